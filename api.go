@@ -14,6 +14,13 @@ const (
 	OPERATION_BNPAIR      = 0x08
 	OPERATION_MNT4PAIR    = 0x09
 	OPERATION_MNT6PAIR    = 0x0a
+	OPERATION_G1_MAP      = 0x0b
+	OPERATION_G2_MAP      = 0x0c
+	// pairings that return the product in the target group
+	OPERATION_BLS12PAIR_GT = 0x0d
+	OPERATION_BNPAIR_GT    = 0x0e
+	OPERATION_MNT4PAIR_GT  = 0x0f
+	OPERATION_MNT6PAIR_GT  = 0x10
 	// flags
 	USE_4LIMBS_FOR_LOWER_LIMBS  = true
 	TWIST_M, TWIST_D            = 0x01, 0x02
@@ -21,14 +28,45 @@ const (
 	BOOLEAN_FALSE, BOOLEAN_TRUE = 0x00, 0x01
 )
 
+// API is the entry point of the EIP-1962 precompile.
+//
+// Compatibility contract: every method takes the raw call data of the
+// corresponding operation, without the leading operation byte, and returns
+// the raw return data. Operation codes, input and output encodings and
+// error messages (see error.go) are stable across releases and only change
+// together with the EIP itself. An API value holds no state and is safe
+// for concurrent use.
 type API struct{}
 
-func newAPI() *API {
+// NewAPI returns a new precompile entry point.
+func NewAPI() *API {
 	return &API{}
 }
 
+// Run executes the operation given by opType over the encoded input.
 func (api *API) Run(opType int, in []byte) ([]byte, error) {
+	runner, err := newDecoder(in).runner(opType)
+	if err != nil {
+		return nil, err
+	}
+	return runner.run()
+}
+
+// Validate decodes the input of opType like Run without executing the
+// operation and additionally requires the modulus and the group order to be
+// probable primes, curves to be non singular, every point to be annihilated
+// by the group order and pairing curves to have the embedding degree of
+// their family. It is meant for vetting curve parameters offline and is
+// much slower than decoding in Run.
+func (api *API) Validate(opType int, in []byte) error {
 	decoder := newDecoder(in)
+	decoder.context.strict = true
+	_, err := decoder.runner(opType)
+	return err
+}
+
+// runner decodes the input of opType
+func (decoder *decoder) runner(opType int) (runner, error) {
 	var runner runner
 	var err error
 	switch opType {
@@ -45,18 +83,151 @@ func (api *API) Run(opType int, in []byte) ([]byte, error) {
 	case OPERATION_G2_MULTIEXP:
 		runner, err = decoder.g2MultiExpRunner()
 	case OPERATION_BLS12PAIR:
-		runner, err = decoder.blsRunner()
+		runner, err = decoder.blsRunner(false)
+	case OPERATION_BLS12PAIR_GT:
+		runner, err = decoder.blsRunner(true)
 	case OPERATION_BNPAIR:
-		runner, err = decoder.bnRunner()
+		runner, err = decoder.bnRunner(false)
+	case OPERATION_BNPAIR_GT:
+		runner, err = decoder.bnRunner(true)
 	case OPERATION_MNT4PAIR:
-		runner, err = decoder.mnt4Runner()
+		runner, err = decoder.mnt4Runner(false)
+	case OPERATION_MNT4PAIR_GT:
+		runner, err = decoder.mnt4Runner(true)
 	case OPERATION_MNT6PAIR:
-		runner, err = decoder.mnt6Runner()
+		runner, err = decoder.mnt6Runner(false)
+	case OPERATION_MNT6PAIR_GT:
+		runner, err = decoder.mnt6Runner(true)
+	case OPERATION_G1_MAP:
+		runner, err = decoder.g1MapRunner()
+	case OPERATION_G2_MAP:
+		runner, err = decoder.g2MapRunner()
 	default:
 		err = errors.New(ERR_UNKNOWN_OPERATION)
 	}
-	if err != nil {
-		return nil, err
+	return runner, err
+}
+
+// RunParallel executes a pairing operation like Run and splits the Miller
+// loop of BLS12 and BN pairings across the given number of goroutines. It is
+// meant for batches of many pairs, MNT4 and MNT6 pairings run sequentially.
+func (api *API) RunParallel(opType int, in []byte, workers int) ([]byte, error) {
+	decoder := newDecoder(in)
+	switch opType {
+	case OPERATION_BLS12PAIR, OPERATION_BLS12PAIR_GT:
+		runner, err := decoder.blsRunner(opType == OPERATION_BLS12PAIR_GT)
+		if err != nil {
+			return nil, err
+		}
+		runner.e.setWorkers(workers)
+		return runner.run()
+	case OPERATION_BNPAIR, OPERATION_BNPAIR_GT:
+		runner, err := decoder.bnRunner(opType == OPERATION_BNPAIR_GT)
+		if err != nil {
+			return nil, err
+		}
+		runner.e.setWorkers(workers)
+		return runner.run()
+	case OPERATION_MNT4PAIR, OPERATION_MNT4PAIR_GT, OPERATION_MNT6PAIR, OPERATION_MNT6PAIR_GT:
+		return api.Run(opType, in)
+	default:
+		return nil, errors.New(ERR_UNKNOWN_OPERATION)
 	}
-	return runner.run()
+}
+
+// G1Add runs OPERATION_G1_ADD and returns the dense encoded sum.
+func (api *API) G1Add(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G1_ADD, in)
+}
+
+// G1Mul runs OPERATION_G1_MUL and returns the dense encoded product.
+func (api *API) G1Mul(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G1_MUL, in)
+}
+
+// G1MultiExp runs OPERATION_G1_MULTIEXP and returns the dense encoded result.
+func (api *API) G1MultiExp(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G1_MULTIEXP, in)
+}
+
+// G2Add runs OPERATION_G2_ADD and returns the dense encoded sum.
+// Extension degree is decoded from the input.
+func (api *API) G2Add(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G2_ADD, in)
+}
+
+// G2Mul runs OPERATION_G2_MUL and returns the dense encoded product.
+// Extension degree is decoded from the input.
+func (api *API) G2Mul(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G2_MUL, in)
+}
+
+// G2MultiExp runs OPERATION_G2_MULTIEXP and returns the dense encoded result.
+// Extension degree is decoded from the input.
+func (api *API) G2MultiExp(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G2_MULTIEXP, in)
+}
+
+// BLS12Pairing runs OPERATION_BLS12PAIR and returns a single byte,
+// 0x01 if the pairing product is one and 0x00 otherwise.
+func (api *API) BLS12Pairing(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_BLS12PAIR, in)
+}
+
+// BNPairing runs OPERATION_BNPAIR and returns a single byte,
+// 0x01 if the pairing product is one and 0x00 otherwise.
+func (api *API) BNPairing(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_BNPAIR, in)
+}
+
+// MNT4Pairing runs OPERATION_MNT4PAIR and returns a single byte,
+// 0x01 if the pairing product is one and 0x00 otherwise.
+func (api *API) MNT4Pairing(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_MNT4PAIR, in)
+}
+
+// MNT6Pairing runs OPERATION_MNT6PAIR and returns a single byte,
+// 0x01 if the pairing product is one and 0x00 otherwise.
+func (api *API) MNT6Pairing(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_MNT6PAIR, in)
+}
+
+// G1Map runs OPERATION_G1_MAP and returns the dense encoded point
+// that the field element maps to. Only BLS12-381 is supported.
+func (api *API) G1Map(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G1_MAP, in)
+}
+
+// G2Map runs OPERATION_G2_MAP and returns the dense encoded point
+// that the Fp2 element maps to. Only BLS12-381 is supported.
+func (api *API) G2Map(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_G2_MAP, in)
+}
+
+// BLS12PairingGT runs OPERATION_BLS12PAIR_GT and returns the pairing
+// product in Fp12 after final exponentiation, encoded with fixed size
+// coefficients.
+func (api *API) BLS12PairingGT(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_BLS12PAIR_GT, in)
+}
+
+// BNPairingGT runs OPERATION_BNPAIR_GT and returns the pairing
+// product in Fp12 after final exponentiation, encoded with fixed size
+// coefficients.
+func (api *API) BNPairingGT(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_BNPAIR_GT, in)
+}
+
+// MNT4PairingGT runs OPERATION_MNT4PAIR_GT and returns the pairing
+// product in Fp4 after final exponentiation, encoded with fixed size
+// coefficients.
+func (api *API) MNT4PairingGT(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_MNT4PAIR_GT, in)
+}
+
+// MNT6PairingGT runs OPERATION_MNT6PAIR_GT and returns the pairing
+// product in Fp6 after final exponentiation, encoded with fixed size
+// coefficients.
+func (api *API) MNT6PairingGT(in []byte) ([]byte, error) {
+	return api.Run(OPERATION_MNT6PAIR_GT, in)
 }
@@ -25,6 +25,9 @@ const (
 	MAX_ATE_PAIRING_ATE_LOOP_COUNT_HAMMING  = 2032
 	MAX_ATE_PAIRING_FINAL_EXP_W0_BIT_LENGTH = 2032
 	MAX_ATE_PAIRING_FINAL_EXP_W1_BIT_LENGTH = 2032
+	// miller rabin rounds of primality tests in strict mode on top of
+	// baillie psw
+	STRICT_PRIMALITY_ROUNDS = 20
 )
 
 type tape struct {
@@ -66,6 +69,10 @@ type cache struct {
 
 type context struct {
 	willDoPairing bool
+	// strict additionally requires prime modulus and order, non singular
+	// curves, points annihilated by the order and the embedding degree of
+	// the pairing family
+	strict bool
 }
 
 type decoder struct {
@@ -75,7 +82,7 @@ type decoder struct {
 }
 
 func newDecoder(in []byte) *decoder {
-	return &decoder{newTape(in), &cache{nil, 0, 0}, &context{false}}
+	return &decoder{newTape(in), &cache{nil, 0, 0}, &context{false, false}}
 }
 
 func (decoder *decoder) read(size int) ([]byte, error) {
@@ -122,6 +129,9 @@ func (decoder *decoder) readFq() (*fq, error) {
 	if fq, err = newField(modulusBuf); err != nil {
 		return nil, errors.New(ERR_BASE_FIELD_CONSTRUCTION)
 	}
+	if decoder.context.strict && !fq.modulus().ProbablyPrime(STRICT_PRIMALITY_ROUNDS) {
+		return nil, errors.New(ERR_STRICT_MODULUS_NOT_PRIME)
+	}
 	cache.fq = fq
 	return fq, nil
 }
@@ -159,11 +169,11 @@ func (decoder *decoder) readG1G22Pairs(g1 *g1, g2 *g22) ([]*pointG1, []*pointG22
 		if !g2.isOnCurve(p2) {
 			return nil, nil, errors.New(ERR_PAIRING_POINTG2_NOT_ON_CURVE)
 		}
-		if checkSubgroup1 {
-			g1.checkCorrectSubgroup(p1)
+		if checkSubgroup1 && !g1.checkCorrectSubgroup(p1) {
+			return nil, nil, errors.New(ERR_PAIRING_POINTG1_NOT_IN_SUBGROUP)
 		}
-		if checkSubgroup2 {
-			g2.checkCorrectSubgroup(p2)
+		if checkSubgroup2 && !g2.checkCorrectSubgroup(p2) {
+			return nil, nil, errors.New(ERR_PAIRING_POINTG2_NOT_IN_SUBGROUP)
 		}
 		if !g1.isZero(p1) && !g2.isZero(p2) {
 			g1Points = append(g1Points, p1)
@@ -206,11 +216,11 @@ func (decoder *decoder) readG1G23Pairs(g1 *g1, g2 *g23) ([]*pointG1, []*pointG23
 		if !g2.isOnCurve(p2) {
 			return nil, nil, errors.New(ERR_PAIRING_POINTG2_NOT_ON_CURVE)
 		}
-		if checkSubgroup1 {
-			g1.checkCorrectSubgroup(p1)
+		if checkSubgroup1 && !g1.checkCorrectSubgroup(p1) {
+			return nil, nil, errors.New(ERR_PAIRING_POINTG1_NOT_IN_SUBGROUP)
 		}
-		if checkSubgroup2 {
-			g2.checkCorrectSubgroup(p2)
+		if checkSubgroup2 && !g2.checkCorrectSubgroup(p2) {
+			return nil, nil, errors.New(ERR_PAIRING_POINTG2_NOT_IN_SUBGROUP)
 		}
 		if !g1.isZero(p1) && !g2.isZero(p2) {
 			g1Points = append(g1Points, p1)
@@ -417,6 +427,9 @@ func (decoder *decoder) readGroupOrder() (*big.Int, error) {
 	if order.Cmp(zero) == 0 {
 		return nil, errors.New(ERR_GROUP_ORDER_ZERO)
 	}
+	if decoder.context.strict && !order.ProbablyPrime(STRICT_PRIMALITY_ROUNDS) {
+		return nil, errors.New(ERR_STRICT_GROUP_ORDER_NOT_PRIME)
+	}
 	decoder.cache.groupOrderLen = orderLen
 	return order, nil
 }
@@ -426,7 +439,15 @@ func (decoder *decoder) readG1Point(g1 *g1) (*pointG1, error) {
 	if err != nil {
 		return nil, err
 	}
-	return g1.fromBytes(buf)
+	p, err := g1.fromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	// points that are not on curve are reported by the caller
+	if decoder.context.strict && g1.isOnCurve(p) && !g1.isZero(g1.wnafMul(g1.newPoint(), p, g1.q)) {
+		return nil, errors.New(ERR_STRICT_POINT_ORDER)
+	}
+	return p, nil
 }
 
 func (decoder *decoder) readG22Point(g22 *g22) (*pointG22, error) {
@@ -434,7 +455,15 @@ func (decoder *decoder) readG22Point(g22 *g22) (*pointG22, error) {
 	if err != nil {
 		return nil, err
 	}
-	return g22.fromBytes(buf)
+	p, err := g22.fromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	// points that are not on curve are reported by the caller
+	if decoder.context.strict && g22.isOnCurve(p) && !g22.isZero(g22.wnafMul(g22.newPoint(), p, g22.q)) {
+		return nil, errors.New(ERR_STRICT_POINT_ORDER)
+	}
+	return p, nil
 }
 
 func (decoder *decoder) readG23Point(g23 *g23) (*pointG23, error) {
@@ -442,7 +471,15 @@ func (decoder *decoder) readG23Point(g23 *g23) (*pointG23, error) {
 	if err != nil {
 		return nil, err
 	}
-	return g23.fromBytes(buf)
+	p, err := g23.fromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	// points that are not on curve are reported by the caller
+	if decoder.context.strict && g23.isOnCurve(p) && !g23.isZero(g23.wnafMul(g23.newPoint(), p, g23.q)) {
+		return nil, errors.New(ERR_STRICT_POINT_ORDER)
+	}
+	return p, nil
 }
 
 func (decoder *decoder) readScalar(size int) (*big.Int, error) {
@@ -470,7 +507,14 @@ func (decoder *decoder) readG1() (*g1, error) {
 		return nil, err
 	}
 	// construct g1
-	return newG1(fq, a, b, order)
+	g1, err := newG1(fq, a, b, order)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.context.strict && g1.isSingular() {
+		return nil, errors.New(ERR_STRICT_CURVE_SINGULAR)
+	}
+	return g1, nil
 }
 
 func (decoder *decoder) readG22() (*g22, error) {
@@ -490,7 +534,14 @@ func (decoder *decoder) readG22() (*g22, error) {
 		return nil, err
 	}
 	// construct g22
-	return newG22(fq2, a, b, order)
+	g22, err := newG22(fq2, a, b, order)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.context.strict && g22.isSingular() {
+		return nil, errors.New(ERR_STRICT_CURVE_SINGULAR)
+	}
+	return g22, nil
 }
 
 func (decoder *decoder) readG23() (*g23, error) {
@@ -509,8 +560,35 @@ func (decoder *decoder) readG23() (*g23, error) {
 	if err != nil {
 		return nil, err
 	}
-	// construct g22
-	return newG23(fq3, a, b, order)
+	// construct g23
+	g23, err := newG23(fq3, a, b, order)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.context.strict && g23.isSingular() {
+		return nil, errors.New(ERR_STRICT_CURVE_SINGULAR)
+	}
+	return g23, nil
+}
+
+// checkEmbeddingDegree requires k to be the smallest integer with
+// q | p^k - 1 in strict mode
+func (decoder *decoder) checkEmbeddingDegree(g1 *g1, k int) error {
+	if !decoder.context.strict {
+		return nil
+	}
+	p, q, one := g1.f.modulus(), g1.q, big.NewInt(1)
+	t := new(big.Int).Mod(p, q)
+	for i := 1; i <= k; i++ {
+		if t.Cmp(one) == 0 {
+			if i == k {
+				return nil
+			}
+			break
+		}
+		t.Mod(t.Mul(t, p), q)
+	}
+	return errors.New(ERR_STRICT_EMBEDDING_DEGREE)
 }
 
 func (decoder *decoder) readAB() (fe, fe, error) {
@@ -607,6 +685,83 @@ func (decoder *decoder) readTwistType() (int, error) {
 	return twistType, nil
 }
 
+// readBLSLoopParam reads x and its sign
+func (decoder *decoder) readBLSLoopParam() (*big.Int, bool, error) {
+	z, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
+	if err != nil {
+		return nil, false, err
+	}
+	if weight := calculateHammingWeight(z); weight > MAX_BLS12_X_HAMMING {
+		return nil, false, errors.New(ERR_BLS_PAIRING_LOW_HAMMING_WEIGHT)
+	}
+	zIsNegative, err := decoder.readSign()
+	if err != nil {
+		return nil, false, err
+	}
+	return z, zIsNegative, nil
+}
+
+// readBNLoopParam reads u and its sign and returns
+// miller loop parameter 6u + 2 alongside
+func (decoder *decoder) readBNLoopParam() (*big.Int, bool, *big.Int, error) {
+	u, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	uIsNegative, err := decoder.readSign()
+	if err != nil {
+		return nil, false, nil, err
+	}
+	sixUPlus2 := new(big.Int)
+	six, two := big.NewInt(6), big.NewInt(2)
+	if uIsNegative {
+		sixUPlus2.Mul(six, u)
+		sixUPlus2.Sub(sixUPlus2, two)
+	} else {
+		sixUPlus2.Mul(six, u)
+		sixUPlus2.Add(sixUPlus2, two)
+	}
+	if weight := calculateHammingWeight(sixUPlus2); weight > MAX_BN_SIX_U_PLUS_TWO_HAMMING {
+		return nil, false, nil, errors.New(ERR_BN_PAIRING_LOW_HAMMING_WEIGHT)
+	}
+	return u, uIsNegative, sixUPlus2, nil
+}
+
+// mntLoopParams keeps miller loop and final exponentiation parameters of mnt pairings
+type mntLoopParams struct {
+	x               *big.Int
+	xIsNegative     bool
+	expW0, expW1    *big.Int
+	expW0IsNegative bool
+}
+
+func (decoder *decoder) readMNTLoopParams() (*mntLoopParams, error) {
+	x, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
+	if err != nil {
+		return nil, err
+	}
+	xIsNegative, err := decoder.readSign()
+	if err != nil {
+		return nil, err
+	}
+	if weight := calculateHammingWeight(x); weight > MAX_ATE_PAIRING_ATE_LOOP_COUNT_HAMMING {
+		return nil, errors.New(ERR_MNT_PAIRING_LOW_HAMMING_WEIGHT)
+	}
+	expW0, err := decoder.readLoopParam(MAX_ATE_PAIRING_FINAL_EXP_W0_BIT_LENGTH)
+	if err != nil {
+		return nil, err
+	}
+	expW1, err := decoder.readLoopParam(MAX_ATE_PAIRING_FINAL_EXP_W1_BIT_LENGTH)
+	if err != nil {
+		return nil, err
+	}
+	expW0IsNegative, err := decoder.readSign()
+	if err != nil {
+		return nil, err
+	}
+	return &mntLoopParams{x, xIsNegative, expW0, expW1, expW0IsNegative}, nil
+}
+
 func (decoder *decoder) g1AddRunner() (*g1AddRunner, error) {
 	g1, err := decoder.readG1()
 	if err != nil {
@@ -918,12 +1073,15 @@ func (decoder *decoder) g23MultiExpRunner() (*g23MultiExpRunner, error) {
 	return newG23MultiExpRunner(g23, points, scalars), nil
 }
 
-func (decoder *decoder) blsRunner() (*blsRunner, error) {
+func (decoder *decoder) blsEngine() (*blsInstance, error) {
 	decoder.context.willDoPairing = true
 	g1, err := decoder.readG1()
 	if err != nil {
 		return nil, err
 	}
+	if err := decoder.checkEmbeddingDegree(g1, 12); err != nil {
+		return nil, err
+	}
 	fq2, err := decoder.readFq2()
 	if err != nil {
 		return nil, err
@@ -971,31 +1129,34 @@ func (decoder *decoder) blsRunner() (*blsRunner, error) {
 	if err != nil {
 		return nil, err
 	}
-	z, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
+	z, zIsNegative, err := decoder.readBLSLoopParam()
 	if err != nil {
 		return nil, err
 	}
-	if weight := calculateHammingWeight(z); weight > MAX_BLS12_X_HAMMING {
-		return nil, errors.New(ERR_BLS_PAIRING_LOW_HAMMING_WEIGHT)
-	}
-	zIsNegative, err := decoder.readSign()
+	return newBLSInstance(z, zIsNegative, twistType, g1, g2, fq12, false), nil
+}
+
+func (decoder *decoder) blsRunner(gtOutput bool) (*blsRunner, error) {
+	e, err := decoder.blsEngine()
 	if err != nil {
 		return nil, err
 	}
-	g1Points, g2Points, err := decoder.readG1G22Pairs(g1, g2)
+	g1Points, g2Points, err := decoder.readG1G22Pairs(e.g1, e.g2)
 	if err != nil {
 		return nil, err
 	}
-	e := newBLSInstance(z, zIsNegative, twistType, g1, g2, fq12, false)
-	return newBLSRunner(e, g1Points, g2Points), nil
+	return newBLSRunner(e, g1Points, g2Points, gtOutput), nil
 }
 
-func (decoder *decoder) bnRunner() (*bnRunner, error) {
+func (decoder *decoder) bnEngine() (*bnInstance, error) {
 	decoder.context.willDoPairing = true
 	g1, err := decoder.readG1()
 	if err != nil {
 		return nil, err
 	}
+	if err := decoder.checkEmbeddingDegree(g1, 12); err != nil {
+		return nil, err
+	}
 	fq2, err := decoder.readFq2()
 	if err != nil {
 		return nil, err
@@ -1043,44 +1204,38 @@ func (decoder *decoder) bnRunner() (*bnRunner, error) {
 	if err != nil {
 		return nil, err
 	}
-	u, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
-	if err != nil {
-		return nil, err
-	}
-	uIsNegative, err := decoder.readSign()
+	u, uIsNegative, _, err := decoder.readBNLoopParam()
 	if err != nil {
 		return nil, err
 	}
-	sixUPlus2 := new(big.Int)
-	six, two := big.NewInt(6), big.NewInt(2)
-	if uIsNegative {
-		sixUPlus2.Mul(six, u)
-		sixUPlus2.Sub(sixUPlus2, two)
-	} else {
-		sixUPlus2.Mul(six, u)
-		sixUPlus2.Add(sixUPlus2, two)
-	}
-	if weight := calculateHammingWeight(sixUPlus2); weight > MAX_BN_SIX_U_PLUS_TWO_HAMMING {
-		return nil, errors.New(ERR_BLS_PAIRING_LOW_HAMMING_WEIGHT)
-	}
 	minus2Inv := new(big.Int).ModInverse(big.NewInt(-2), fq2.modulus())
 	nonResidueInPMinus1Over2 := fq2.new()
 	fq2.exp(nonResidueInPMinus1Over2, fq6.nonResidue, minus2Inv)
 
-	g1Points, g2Points, err := decoder.readG1G22Pairs(g1, g2)
+	return newBNInstance(u, uIsNegative, twistType, g1, g2, fq12, true), nil
+}
+
+func (decoder *decoder) bnRunner(gtOutput bool) (*bnRunner, error) {
+	e, err := decoder.bnEngine()
 	if err != nil {
 		return nil, err
 	}
-	e := newBNInstance(u, uIsNegative, twistType, g1, g2, fq12, true)
-	return newBNRunner(e, g1Points, g2Points), nil
+	g1Points, g2Points, err := decoder.readG1G22Pairs(e.g1, e.g2)
+	if err != nil {
+		return nil, err
+	}
+	return newBNRunner(e, g1Points, g2Points, gtOutput), nil
 }
 
-func (decoder *decoder) mnt4Runner() (*mnt4Runner, error) {
+func (decoder *decoder) mnt4Engine() (*mnt4Instance, error) {
 	decoder.context.willDoPairing = true
 	g1, err := decoder.readG1()
 	if err != nil {
 		return nil, err
 	}
+	if err := decoder.checkEmbeddingDegree(g1, 4); err != nil {
+		return nil, err
+	}
 	fq2, err := decoder.readFq2()
 	if err != nil {
 		return nil, err
@@ -1102,43 +1257,34 @@ func (decoder *decoder) mnt4Runner() (*mnt4Runner, error) {
 	if err != nil {
 		return nil, err
 	}
-	x, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
-	if err != nil {
-		return nil, err
-	}
-	xIsNegative, err := decoder.readSign()
+	p, err := decoder.readMNTLoopParams()
 	if err != nil {
 		return nil, err
 	}
-	if weight := calculateHammingWeight(x); weight > MAX_ATE_PAIRING_ATE_LOOP_COUNT_HAMMING {
-		return nil, errors.New(ERR_MNT_PAIRING_LOW_HAMMING_WEIGHT)
-	}
-	expW0, err := decoder.readLoopParam(MAX_ATE_PAIRING_FINAL_EXP_W0_BIT_LENGTH)
-	if err != nil {
-		return nil, err
-	}
-	expW1, err := decoder.readLoopParam(MAX_ATE_PAIRING_FINAL_EXP_W1_BIT_LENGTH)
-	if err != nil {
-		return nil, err
-	}
-	expW0IsNegative, err := decoder.readSign()
+	return newMNT4Instance(p.x, p.xIsNegative, p.expW0, p.expW1, p.expW0IsNegative, fq4, g1, g2, twist), nil
+}
+
+func (decoder *decoder) mnt4Runner(gtOutput bool) (*mnt4Runner, error) {
+	e, err := decoder.mnt4Engine()
 	if err != nil {
 		return nil, err
 	}
-	g1Points, g2Points, err := decoder.readG1G22Pairs(g1, g2)
+	g1Points, g2Points, err := decoder.readG1G22Pairs(e.g1, e.g2)
 	if err != nil {
 		return nil, err
 	}
-	mnt4 := newMNT4Instance(x, xIsNegative, expW0, expW1, expW0IsNegative, fq4, g1, g2, twist)
-	return newMNT4Runner(mnt4, g1Points, g2Points), nil
+	return newMNT4Runner(e, g1Points, g2Points, gtOutput), nil
 }
 
-func (decoder *decoder) mnt6Runner() (*mnt6Runner, error) {
+func (decoder *decoder) mnt6Engine() (*mnt6Instance, error) {
 	decoder.context.willDoPairing = true
 	g1, err := decoder.readG1()
 	if err != nil {
 		return nil, err
 	}
+	if err := decoder.checkEmbeddingDegree(g1, 6); err != nil {
+		return nil, err
+	}
 	fq3, err := decoder.readFq3()
 	if err != nil {
 		return nil, err
@@ -1163,33 +1309,85 @@ func (decoder *decoder) mnt6Runner() (*mnt6Runner, error) {
 	if err != nil {
 		return nil, err
 	}
-	x, err := decoder.readLoopParam(MAX_ATE_PAIRING_ATE_LOOP_COUNT)
+	p, err := decoder.readMNTLoopParams()
 	if err != nil {
 		return nil, err
 	}
-	xIsNegative, err := decoder.readSign()
+	return newMNT6Instance(p.x, p.xIsNegative, p.expW0, p.expW1, p.expW0IsNegative, fq6, g1, g2, twist), nil
+}
+
+func (decoder *decoder) mnt6Runner(gtOutput bool) (*mnt6Runner, error) {
+	e, err := decoder.mnt6Engine()
 	if err != nil {
 		return nil, err
 	}
-	if weight := calculateHammingWeight(x); weight > MAX_ATE_PAIRING_ATE_LOOP_COUNT_HAMMING {
-		return nil, errors.New(ERR_MNT_PAIRING_LOW_HAMMING_WEIGHT)
+	g1Points, g2Points, err := decoder.readG1G23Pairs(e.g1, e.g2)
+	if err != nil {
+		return nil, err
 	}
-	expW0, err := decoder.readLoopParam(MAX_ATE_PAIRING_FINAL_EXP_W0_BIT_LENGTH)
+	return newMNT6Runner(e, g1Points, g2Points, gtOutput), nil
+}
+
+func (decoder *decoder) g1MapRunner() (*g1MapRunner, error) {
+	g1, err := decoder.readG1()
 	if err != nil {
 		return nil, err
 	}
-	expW1, err := decoder.readLoopParam(MAX_ATE_PAIRING_FINAL_EXP_W1_BIT_LENGTH)
+	if !isBLS12381G1(g1) {
+		return nil, errors.New(ERR_MAP_UNSUPPORTED_CURVE)
+	}
+	buf, err := decoder.readFieldElementAsBytes(1)
 	if err != nil {
 		return nil, err
 	}
-	expW0IsNegative, err := decoder.readSign()
+	u, err := g1.f.fromBytes(buf)
+	if err != nil {
+		return nil, errors.New(ERR_MAP_FIELD_ELEMENT)
+	}
+	if decoder.remainingDataLen() != 0 {
+		return nil, errors.New(ERR_GARBAGE_INPUT)
+	}
+	return newG1MapRunner(g1, u), nil
+}
+
+func (decoder *decoder) g2MapRunner() (runner, error) {
+	_, err := decoder.readFq()
 	if err != nil {
 		return nil, err
 	}
-	g1Points, g2Points, err := decoder.readG1G23Pairs(g1, g2)
+	degreeBuf, err := decoder.read(EXTENSION_DEGREE_LENGTH_ENCODING)
+	if err != nil {
+		return nil, errors.New(ERR_G2_CANT_DECODE_EXT_DEGREE_LENGTH)
+	}
+	degree := int(degreeBuf[0])
+	switch degree {
+	case EXTENSION_TWO_DEGREE:
+		return decoder.g22MapRunner()
+	case EXTENSION_THREE_DEGREE:
+		return nil, errors.New(ERR_MAP_UNSUPPORTED_CURVE)
+	default:
+		return nil, errors.New(ERR_G2_UNEXPECTED_EXT_DEGREE)
+	}
+}
+
+func (decoder *decoder) g22MapRunner() (*g22MapRunner, error) {
+	g22, err := decoder.readG22()
 	if err != nil {
 		return nil, err
 	}
-	mnt6 := newMNT6Instance(x, xIsNegative, expW0, expW1, expW0IsNegative, fq6, g1, g2, twist)
-	return newMNT6Runner(mnt6, g1Points, g2Points), nil
+	if !isBLS12381G2(g22) {
+		return nil, errors.New(ERR_MAP_UNSUPPORTED_CURVE)
+	}
+	buf, err := decoder.readFieldElementAsBytes(2)
+	if err != nil {
+		return nil, err
+	}
+	u, err := g22.f.fromBytes(buf)
+	if err != nil {
+		return nil, errors.New(ERR_MAP_FIELD_ELEMENT)
+	}
+	if decoder.remainingDataLen() != 0 {
+		return nil, errors.New(ERR_GARBAGE_INPUT)
+	}
+	return newG22MapRunner(g22, u), nil
 }
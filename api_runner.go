@@ -185,13 +185,14 @@ var (
 )
 
 type blsRunner struct {
-	e  *blsInstance
-	P1 []*pointG1
-	P2 []*pointG22
+	e        *blsInstance
+	P1       []*pointG1
+	P2       []*pointG22
+	gtOutput bool
 }
 
-func newBLSRunner(e *blsInstance, P1 []*pointG1, P2 []*pointG22) *blsRunner {
-	return &blsRunner{e, P1, P2}
+func newBLSRunner(e *blsInstance, P1 []*pointG1, P2 []*pointG22, gtOutput bool) *blsRunner {
+	return &blsRunner{e, P1, P2, gtOutput}
 }
 
 func (runner *blsRunner) run() ([]byte, error) {
@@ -203,6 +204,9 @@ func (runner *blsRunner) run() ([]byte, error) {
 	if !hasValue {
 		return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
 	}
+	if runner.gtOutput {
+		return gt.toBytes(result), nil
+	}
 	if !gt.equal(result, gt.one()) {
 		return pairingError, nil
 	}
@@ -210,13 +214,14 @@ func (runner *blsRunner) run() ([]byte, error) {
 }
 
 type bnRunner struct {
-	e  *bnInstance
-	P1 []*pointG1
-	P2 []*pointG22
+	e        *bnInstance
+	P1       []*pointG1
+	P2       []*pointG22
+	gtOutput bool
 }
 
-func newBNRunner(e *bnInstance, P1 []*pointG1, P2 []*pointG22) *bnRunner {
-	return &bnRunner{e, P1, P2}
+func newBNRunner(e *bnInstance, P1 []*pointG1, P2 []*pointG22, gtOutput bool) *bnRunner {
+	return &bnRunner{e, P1, P2, gtOutput}
 }
 
 func (runner *bnRunner) run() ([]byte, error) {
@@ -228,6 +233,9 @@ func (runner *bnRunner) run() ([]byte, error) {
 	if !hasValue {
 		return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
 	}
+	if runner.gtOutput {
+		return gt.toBytes(result), nil
+	}
 	if !gt.equal(result, gt.one()) {
 		return pairingError, nil
 	}
@@ -235,13 +243,14 @@ func (runner *bnRunner) run() ([]byte, error) {
 }
 
 type mnt4Runner struct {
-	e  *mnt4Instance
-	P1 []*pointG1
-	P2 []*pointG22
+	e        *mnt4Instance
+	P1       []*pointG1
+	P2       []*pointG22
+	gtOutput bool
 }
 
-func newMNT4Runner(e *mnt4Instance, P1 []*pointG1, P2 []*pointG22) *mnt4Runner {
-	return &mnt4Runner{e, P1, P2}
+func newMNT4Runner(e *mnt4Instance, P1 []*pointG1, P2 []*pointG22, gtOutput bool) *mnt4Runner {
+	return &mnt4Runner{e, P1, P2, gtOutput}
 }
 
 func (runner *mnt4Runner) run() ([]byte, error) {
@@ -253,6 +262,9 @@ func (runner *mnt4Runner) run() ([]byte, error) {
 	if !hasValue {
 		return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
 	}
+	if runner.gtOutput {
+		return gt.toBytes(result), nil
+	}
 	if !gt.equal(result, gt.one()) {
 		return pairingError, nil
 	}
@@ -260,13 +272,14 @@ func (runner *mnt4Runner) run() ([]byte, error) {
 }
 
 type mnt6Runner struct {
-	e  *mnt6Instance
-	P1 []*pointG1
-	P2 []*pointG23
+	e        *mnt6Instance
+	P1       []*pointG1
+	P2       []*pointG23
+	gtOutput bool
 }
 
-func newMNT6Runner(e *mnt6Instance, P1 []*pointG1, P2 []*pointG23) *mnt6Runner {
-	return &mnt6Runner{e, P1, P2}
+func newMNT6Runner(e *mnt6Instance, P1 []*pointG1, P2 []*pointG23, gtOutput bool) *mnt6Runner {
+	return &mnt6Runner{e, P1, P2, gtOutput}
 }
 
 func (runner *mnt6Runner) run() ([]byte, error) {
@@ -278,8 +291,47 @@ func (runner *mnt6Runner) run() ([]byte, error) {
 	if !hasValue {
 		return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
 	}
+	if runner.gtOutput {
+		return gt.toBytes(result), nil
+	}
 	if !gt.equal(result, gt.one()) {
 		return pairingError, nil
 	}
 	return pairingSuccess, nil
 }
+
+type g1MapRunner struct {
+	g1 *g1
+	u  fe
+}
+
+func newG1MapRunner(g1 *g1, u fe) *g1MapRunner {
+	return &g1MapRunner{g1, u}
+}
+
+func (runner *g1MapRunner) run() ([]byte, error) {
+	g1 := runner.g1
+	r, err := mapToG1(g1, runner.u)
+	if err != nil {
+		return nil, errors.New(ERR_MAP_TO_CURVE_FAILED)
+	}
+	return g1.toBytesDense(r), nil
+}
+
+type g22MapRunner struct {
+	g22 *g22
+	u   *fe2
+}
+
+func newG22MapRunner(g22 *g22, u *fe2) *g22MapRunner {
+	return &g22MapRunner{g22, u}
+}
+
+func (runner *g22MapRunner) run() ([]byte, error) {
+	g22 := runner.g22
+	r, err := mapToG2(g22, runner.u)
+	if err != nil {
+		return nil, errors.New(ERR_MAP_TO_CURVE_FAILED)
+	}
+	return g22.toBytesDense(r), nil
+}
@@ -2,6 +2,7 @@ package eip
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -470,6 +471,64 @@ func (b *builder) encodeMNT6Input() *vectorAPI {
 	return newVectorAPI(input.Bytes(), output, nil, OPERATION_MNT6PAIR, tag)
 }
 
+func encodeBLS12381G1(g *g1) *bytes.Buffer {
+	data := bytes.NewBuffer([]byte{})
+	data.WriteByte(byte(g.f.modulusByteLen))
+	data.Write(g.f.modulus().Bytes())
+	data.Write(g.f.toBytesDense(g.a))
+	data.Write(g.f.toBytesDense(g.b))
+	q := g.q.Bytes()
+	data.WriteByte(byte(len(q)))
+	data.Write(q)
+	return data
+}
+
+func encodeBLS12381G22(g *g22) *bytes.Buffer {
+	data := bytes.NewBuffer([]byte{})
+	fq := g.f.fq()
+	data.WriteByte(byte(fq.modulusByteLen))
+	data.Write(fq.modulus().Bytes())
+	data.WriteByte(byte(2))
+	data.Write(fq.toBytesDense(g.f.nonResidue))
+	data.Write(g.f.toBytesDense(g.a))
+	data.Write(g.f.toBytesDense(g.b))
+	q := g.q.Bytes()
+	data.WriteByte(byte(len(q)))
+	data.Write(q)
+	return data
+}
+
+// map vectors are u values and results of encode to curve of rfc 9380 for empty message
+func encodeG1MapInput(t *testing.T) *vectorAPI {
+	g := bls12381G1ForHashing(t)
+	input := encodeBLS12381G1(g)
+	u, err := hashToFieldFq(g.f, []byte{}, []byte("QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_NU_"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input.Write(g.f.toBytesDense(u[0]))
+	output := bytes.NewBuffer([]byte{})
+	output.Write(fromHex(48, "0x184bb665c37ff561a89ec2122dd343f20e0f4cbcaec84e3c3052ea81d1834e192c426074b02ed3dca4e7676ce4ce48ba"))
+	output.Write(fromHex(48, "0x04407b8d35af4dacc809927071fc0405218f1401a6d15af775810e4e460064bcc9468beeba82fdc751be70476c888bf3"))
+	return newVectorAPI(input.Bytes(), output.Bytes(), nil, OPERATION_G1_MAP, "BLS12381_G1_MAP")
+}
+
+func encodeG22MapInput(t *testing.T) *vectorAPI {
+	g := bls12381G2ForHashing(t)
+	input := encodeBLS12381G22(g)
+	u, err := hashToFieldFq2(g.f, []byte{}, []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_NU_"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input.Write(g.f.toBytesDense(u[0]))
+	output := bytes.NewBuffer([]byte{})
+	output.Write(fromHex(48, "0x00e7f4568a82b4b7dc1f14c6aaa055edf51502319c723c4dc2688c7fe5944c213f510328082396515734b6612c4e7bb7"))
+	output.Write(fromHex(48, "0x126b855e9e69b1f691f816e48ac6977664d24d99f8724868a184186469ddfd4617367e94527d4b74fc86413483afb35b"))
+	output.Write(fromHex(48, "0x0caead0fd7b6176c01436833c79d305c78be307da5f6af6c133c47311def6ff1e0babf57a0fb5539fce7ee12407b0a42"))
+	output.Write(fromHex(48, "0x1498aadcf7ae2b345243e281ae076df6de84455d766ab6fcdaad71fab60abb2e8b980a440043cd305db09d283c895e3d"))
+	return newVectorAPI(input.Bytes(), output.Bytes(), nil, OPERATION_G2_MAP, "BLS12381_G2_MAP")
+}
+
 func TestAPIG1Add(t *testing.T) {
 	vectors := []*vectorAPI{
 		testBuilderFromFile(t, "bls12/256.json", newBuilderOpt("BLS")).encodeG1AddInput(),
@@ -486,7 +545,7 @@ func TestAPIG1Add(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", newBuilderOpt("BLS")).encodeG1AddInput(),
 		testBuilderFromFile(t, "bls12/1024.json", newBuilderOpt("BLS")).encodeG1AddInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -515,7 +574,7 @@ func TestAPIG1Mul(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", newBuilderOpt("BLS")).encodeG1MulInput(),
 		testBuilderFromFile(t, "bls12/1024.json", newBuilderOpt("BLS")).encodeG1MulInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -544,7 +603,7 @@ func TestAPIG1MultiExp(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", newBuilderOpt("BLS")).encodeG1MultiExpInput(),
 		testBuilderFromFile(t, "bls12/1024.json", newBuilderOpt("BLS")).encodeG1MultiExpInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -572,7 +631,7 @@ func TestAPIG2Add(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", newBuilderOpt("BLS")).encodeG22AddInput(),
 		testBuilderFromFile(t, "bls12/1024.json", newBuilderOpt("BLS")).encodeG22AddInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -601,7 +660,7 @@ func TestAPIG2Mul(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", newBuilderOpt("BLS")).encodeG22MulInput(),
 		testBuilderFromFile(t, "bls12/1024.json", newBuilderOpt("BLS")).encodeG22MulInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -630,7 +689,7 @@ func TestAPIG2MultiExp(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", newBuilderOpt("BLS")).encodeG22MultiExpInput(),
 		testBuilderFromFile(t, "bls12/1024.json", newBuilderOpt("BLS")).encodeG22MultiExpInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -660,7 +719,7 @@ func TestAPIBLS(t *testing.T) {
 		testBuilderFromFile(t, "bls12/960.json", opts).encodeBLSInput(),
 		testBuilderFromFile(t, "bls12/1024.json", opts).encodeBLSInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -697,7 +756,7 @@ func TestAPIBN(t *testing.T) {
 			opts,
 		).encodeBNInput(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -756,7 +815,7 @@ func TestAPIMNT4(t *testing.T) {
 			opts,
 		).encodeMNT4Input(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -797,7 +856,7 @@ func TestAPIMNT6(t *testing.T) {
 			opts,
 		).encodeMNT6Input(),
 	}
-	api := newAPI()
+	api := NewAPI()
 	for _, v := range vectors {
 		result, err := api.Run(v.operation, v.input)
 		if err != v.expectedErr {
@@ -809,3 +868,393 @@ func TestAPIMNT6(t *testing.T) {
 		}
 	}
 }
+
+func TestAPITypedWrappers(t *testing.T) {
+	api := NewAPI()
+	wrappers := map[int]func([]byte) ([]byte, error){
+		OPERATION_G1_ADD:       api.G1Add,
+		OPERATION_G1_MUL:       api.G1Mul,
+		OPERATION_G1_MULTIEXP:  api.G1MultiExp,
+		OPERATION_G2_ADD:       api.G2Add,
+		OPERATION_G2_MUL:       api.G2Mul,
+		OPERATION_G2_MULTIEXP:  api.G2MultiExp,
+		OPERATION_BLS12PAIR:    api.BLS12Pairing,
+		OPERATION_BNPAIR:       api.BNPairing,
+		OPERATION_MNT4PAIR:     api.MNT4Pairing,
+		OPERATION_MNT6PAIR:     api.MNT6Pairing,
+		OPERATION_G1_MAP:       api.G1Map,
+		OPERATION_G2_MAP:       api.G2Map,
+		OPERATION_BLS12PAIR_GT: api.BLS12PairingGT,
+		OPERATION_BNPAIR_GT:    api.BNPairingGT,
+		OPERATION_MNT4PAIR_GT:  api.MNT4PairingGT,
+		OPERATION_MNT6PAIR_GT:  api.MNT6PairingGT,
+	}
+	g := testBuilderFromFile(t, "bls12/384.json", newBuilderOpt("BLS"))
+	vectors := []*vectorAPI{
+		g.encodeG1AddInput(),
+		g.encodeG1MulInput(),
+		g.encodeG1MultiExpInput(),
+		g.encodeG22AddInput(),
+		g.encodeG22MulInput(),
+		g.encodeG22MultiExpInput(),
+		testBuilderFromFile(t, "bls12/384.json", newBuilderOptPairing("BLS")).encodeBLSInput(),
+		encodeG1MapInput(t),
+		encodeG22MapInput(t),
+	}
+	for _, v := range vectors {
+		result, err := wrappers[v.operation](v.input)
+		if err != v.expectedErr {
+			t.Log(err)
+			t.Fatal("not have expected error", v.tag)
+		}
+		if !bytes.Equal(result, v.expected) {
+			t.Fatal("not have expected result", v.tag)
+		}
+	}
+	for op, wrapper := range wrappers {
+		_, err1 := wrapper([]byte{})
+		_, err2 := api.Run(op, []byte{})
+		if err1 == nil || err1.Error() != err2.Error() {
+			t.Fatal("wrapper must fail exactly as Run", op)
+		}
+	}
+}
+
+func TestAPIGas(t *testing.T) {
+	api := NewAPI()
+	bls := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	bn := testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN"))
+	for _, v := range []struct {
+		*vectorAPI
+		gas uint64
+	}{
+		{bls.encodeG1AddInput(), 1530},
+		{bls.encodeG1MulInput(), 15811},
+		{bls.encodeG1MultiExpInput(), 38705},
+		{bls.encodeG22AddInput(), 3879},
+		{bls.encodeG22MulInput(), 66950},
+		{bls.encodeG22MultiExpInput(), 431158},
+		{bls.encodeBLSInput(), 728294},
+		{encodeG1MapInput(t), G1_MAP_GAS},
+		{encodeG22MapInput(t), G2_MAP_GAS},
+		{bn.encodeG1AddInput(), 1133},
+		{bn.encodeG1MulInput(), 8931},
+		{bn.encodeG1MultiExpInput(), 21863},
+		{bn.encodeBNInput(), 452254},
+	} {
+		gas, err := api.Gas(v.operation, v.input)
+		if err != nil {
+			t.Fatal(err, v.tag)
+		}
+		if gas != v.gas {
+			t.Fatal("bad price", v.tag, gas, v.gas)
+		}
+		// only header is used
+		gas2, err := api.Gas(v.operation, v.input[:len(v.input)-1])
+		if err != nil || gas != gas2 {
+			t.Fatal("gas must not depend on the body", v.tag)
+		}
+	}
+	gasOf := func(file string) []uint64 {
+		g := testBuilderFromFile(t, file, newBuilderOpt("BLS"))
+		vectors := []*vectorAPI{
+			g.encodeG1AddInput(),
+			g.encodeG1MulInput(),
+			g.encodeG22AddInput(),
+			g.encodeG22MulInput(),
+			testBuilderFromFile(t, file, newBuilderOptPairing("BLS")).encodeBLSInput(),
+		}
+		prices := make([]uint64, len(vectors))
+		for i, v := range vectors {
+			gas, err := api.Gas(v.operation, v.input)
+			if err != nil {
+				t.Fatal(err, v.tag)
+			}
+			prices[i] = gas
+		}
+		return prices
+	}
+	small, large := gasOf("bls12/256.json"), gasOf("bls12/1024.json")
+	for i := range small {
+		if small[i] >= large[i] {
+			t.Fatal("larger modulus must be more expensive", i)
+		}
+	}
+	for op := OPERATION_G1_ADD; op <= OPERATION_MNT6PAIR_GT; op++ {
+		_, err1 := api.Gas(op, []byte{})
+		_, err2 := api.Run(op, []byte{})
+		if err1 == nil || err1.Error() != err2.Error() {
+			t.Fatal("gas must fail as Run on malformed header", op)
+		}
+	}
+	if _, err := api.Gas(0x11, []byte{}); err == nil {
+		t.Fatal("unknown operation must fail")
+	}
+	// loop parameters with too high hamming weight, weight of mnt loop
+	// parameter is bounded by its length so it is given oversized
+	allOnes := func(n uint) []byte {
+		return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), n), big.NewInt(1)).Bytes()
+	}
+	for _, v := range pairingGTInputs(t) {
+		vector := v.b.input()
+		loopLen := 2 + len(vector.z)
+		var heavy []byte
+		var expected string
+		switch v.family {
+		case "BLS":
+			heavy, expected = allOnes(MAX_BLS12_X_HAMMING+1), ERR_BLS_PAIRING_LOW_HAMMING_WEIGHT
+		case "BN":
+			heavy, expected = allOnes(200), ERR_BN_PAIRING_LOW_HAMMING_WEIGHT
+		default:
+			loopLen += 3 + len(vector.expW0) + len(vector.expW1)
+			heavy, expected = allOnes(MAX_ATE_PAIRING_ATE_LOOP_COUNT+8), ERR_PAIRING_LOOP_PARAM_LENGTH_LARGE
+		}
+		prefix := v.header[:len(v.header)-loopLen]
+		in := bytes.Join([][]byte{prefix, {byte(len(heavy))}, heavy, v.header[len(v.header)-loopLen+1+len(vector.z):], {5}, v.pairs}, nil)
+		for _, op := range []int{v.operation - OPERATION_BLS12PAIR_GT + OPERATION_BLS12PAIR, v.operation} {
+			_, err1 := api.Gas(op, in)
+			_, err2 := api.Run(op, in)
+			if err1 == nil || err2 == nil || err1.Error() != expected || err2.Error() != expected {
+				t.Fatal("gas must fail as Run on heavy loop parameter", v.family, err1, err2)
+			}
+		}
+	}
+}
+
+// pairingGTInput is an input of a pairing whose product is one, split into
+// the first n - 1 pairs and the last pair
+type pairingGTInput struct {
+	family      string
+	b           *builder
+	operation   int
+	input       []byte
+	first, last []byte
+	// header is the input up to the number of pairs
+	header, pairs []byte
+	g1Len, g2Len  int
+}
+
+func pairingGTInputs(t *testing.T) []*pairingGTInput {
+	inputs := []*pairingGTInput{}
+	for _, family := range []string{"BLS", "BN", "MNT4", "MNT6"} {
+		var b *builder
+		var v *vectorAPI
+		var op, k int
+		switch family {
+		case "BLS":
+			b = testBuilderFromFile(t, "bls12/384.json", newBuilderOptPairing(family))
+			v, op, k = b.encodeBLSInput(), OPERATION_BLS12PAIR_GT, 2
+		case "BN":
+			b = testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing(family))
+			v, op, k = b.encodeBNInput(), OPERATION_BNPAIR_GT, 2
+		case "MNT4":
+			b = testBuilderFromVector(t, "mnt4_320", mnt4320Vector, newBuilderOptPairing(family))
+			v, op, k = b.encodeMNT4Input(), OPERATION_MNT4PAIR_GT, 2
+		case "MNT6":
+			b = testBuilderFromVector(t, "mnt6_320", mnt6320Vector, newBuilderOptPairing(family))
+			v, op, k = b.encodeMNT6Input(), OPERATION_MNT6PAIR_GT, 3
+		}
+		n := 5
+		l := b.fq().modulusByteLen
+		pairLen := 2 + 2*l + 2*k*l
+		headerLen := len(v.input) - n*pairLen - 1
+		header, pairs := v.input[:headerLen], v.input[headerLen+1:]
+		inputs = append(inputs, &pairingGTInput{
+			family:    family,
+			b:         b,
+			operation: op,
+			input:     v.input,
+			first:     bytes.Join([][]byte{header, {byte(n - 1)}, pairs[:(n-1)*pairLen]}, nil),
+			last:      bytes.Join([][]byte{header, {1}, pairs[(n-1)*pairLen:]}, nil),
+			header:    header,
+			pairs:     pairs,
+			g1Len:     2 * l,
+			g2Len:     2 * k * l,
+		})
+	}
+	return inputs
+}
+
+func TestAPIPairingGT(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		b, family := v.b, v.family
+		results := make([][]byte, 3)
+		for i, in := range [][]byte{v.input, v.first, v.last} {
+			var err error
+			if results[i], err = api.Run(v.operation, in); err != nil {
+				t.Fatal(err, family)
+			}
+		}
+		var one, product, half bool
+		switch family {
+		case "BLS", "BN":
+			gt := b.fq12()
+			f, _ := gt.fromBytes(results[0])
+			f1, _ := gt.fromBytes(results[1])
+			f2, _ := gt.fromBytes(results[2])
+			one, half = gt.isOne(f), gt.isOne(f1)
+			gt.mul(f1, f1, f2)
+			product = gt.isOne(f1)
+		case "MNT4":
+			gt := b.fq4()
+			f, _ := gt.fromBytes(results[0])
+			f1, _ := gt.fromBytes(results[1])
+			f2, _ := gt.fromBytes(results[2])
+			one, half = gt.isOne(f), gt.isOne(f1)
+			gt.mul(f1, f1, f2)
+			product = gt.isOne(f1)
+		case "MNT6":
+			gt := b.fq6Q()
+			f, _ := gt.fromBytes(results[0])
+			f1, _ := gt.fromBytes(results[1])
+			f2, _ := gt.fromBytes(results[2])
+			one, half = gt.isOne(f), gt.isOne(f1)
+			gt.mul(f1, f1, f2)
+			product = gt.isOne(f1)
+		}
+		if !one || half || !product {
+			t.Fatal("bad pairing result in target group", family)
+		}
+	}
+}
+
+func TestAPIMap(t *testing.T) {
+	api := NewAPI()
+	for _, v := range []*vectorAPI{encodeG1MapInput(t), encodeG22MapInput(t)} {
+		result, err := api.Run(v.operation, v.input)
+		if err != nil {
+			t.Fatal(err, v.tag)
+		}
+		if !bytes.Equal(result, v.expected) {
+			t.Fatal("not have expected result", v.tag)
+		}
+	}
+	g1, g2 := bls12381G1ForHashing(t), bls12381G2ForHashing(t)
+	g1Header, g2Header := encodeBLS12381G1(g1).Bytes(), encodeBLS12381G22(g2).Bytes()
+	modulus := g1.f.modulus().Bytes()
+	concat := func(in ...[]byte) []byte {
+		return bytes.Join(in, []byte{})
+	}
+	notBLS12381 := testBuilderFromFile(t, "bls12/384.json", newBuilderOpt("BLS"))
+	for _, v := range []*vectorAPI{
+		newVectorAPI(concat(g1Header, modulus), nil, errors.New(ERR_MAP_FIELD_ELEMENT), OPERATION_G1_MAP, "g1_non_canonical"),
+		newVectorAPI(concat(g1Header, make([]byte, 47)), nil, errors.New(ERR_INPUT_NOT_ENOUGH_FOR_FIELD_ELEMS), OPERATION_G1_MAP, "g1_short"),
+		newVectorAPI(concat(g1Header, make([]byte, 49)), nil, errors.New(ERR_GARBAGE_INPUT), OPERATION_G1_MAP, "g1_garbage"),
+		newVectorAPI(concat(notBLS12381.encodeG1().Bytes(), make([]byte, 48)), nil, errors.New(ERR_MAP_UNSUPPORTED_CURVE), OPERATION_G1_MAP, "g1_unsupported"),
+		newVectorAPI(concat(g2Header, modulus, make([]byte, 48)), nil, errors.New(ERR_MAP_FIELD_ELEMENT), OPERATION_G2_MAP, "g2_non_canonical"),
+		newVectorAPI(concat(g2Header, make([]byte, 95)), nil, errors.New(ERR_INPUT_NOT_ENOUGH_FOR_FIELD_ELEMS), OPERATION_G2_MAP, "g2_short"),
+		newVectorAPI(concat(g2Header, make([]byte, 97)), nil, errors.New(ERR_GARBAGE_INPUT), OPERATION_G2_MAP, "g2_garbage"),
+		newVectorAPI(concat(notBLS12381.encodeG22().Bytes(), make([]byte, 96)), nil, errors.New(ERR_MAP_UNSUPPORTED_CURVE), OPERATION_G2_MAP, "g2_unsupported"),
+	} {
+		_, err := api.Run(v.operation, v.input)
+		if err == nil || err.Error() != v.expectedErr.Error() {
+			t.Fatal("not have expected error", v.tag, err)
+		}
+	}
+	// zero maps to a point in the subgroup
+	result, err := api.G1Map(concat(g1Header, make([]byte, 48)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := g1.fromBytes(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g1.isOnCurve(p) || !g1.checkCorrectSubgroup(p) {
+		t.Fatal("point is not in correct subgroup")
+	}
+}
+
+func TestAPIRunParallel(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		boolOp := v.operation - OPERATION_BLS12PAIR_GT + OPERATION_BLS12PAIR
+		for _, op := range []int{v.operation, boolOp} {
+			for _, in := range [][]byte{v.input, v.first} {
+				expected, err := api.Run(op, in)
+				if err != nil {
+					t.Fatal(err, v.family)
+				}
+				for _, workers := range []int{0, 1, 3} {
+					actual, err := api.RunParallel(op, in, workers)
+					if err != nil {
+						t.Fatal(err, v.family)
+					}
+					if !bytes.Equal(expected, actual) {
+						t.Fatalf("parallel result does not match with %d workers %s", workers, v.family)
+					}
+				}
+			}
+		}
+	}
+	if _, err := api.RunParallel(OPERATION_G1_ADD, nil, 2); err == nil || err.Error() != ERR_UNKNOWN_OPERATION {
+		t.Fatal("unknown operation is expected")
+	}
+}
+
+func TestAPIValidate(t *testing.T) {
+	api := NewAPI()
+	bls := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	bn := testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN"))
+	mnt4 := testBuilderFromVector(t, "mnt4_320", mnt4320Vector, newBuilderOptPairing("MNT4"))
+	mnt6 := testBuilderFromVector(t, "mnt6_320", mnt6320Vector, newBuilderOptPairing("MNT6"))
+	for _, v := range []*vectorAPI{
+		bls.encodeG1AddInput(),
+		bls.encodeG1MulInput(),
+		bls.encodeG1MultiExpInput(),
+		bls.encodeG22MulInput(),
+		bls.encodeBLSInput(),
+		bn.encodeBNInput(),
+		mnt4.encodeMNT4Input(),
+		mnt6.encodeMNT6Input(),
+	} {
+		if err := api.Validate(v.operation, v.input); err != nil {
+			t.Fatal(err, v.tag)
+		}
+	}
+	// curve header of g1 operations
+	encode := func(p, a, b, q *big.Int) *bytes.Buffer {
+		size := len(p.Bytes())
+		data := bytes.NewBuffer([]byte{byte(size)})
+		data.Write(p.Bytes())
+		data.Write(padBytes(a.Bytes(), size))
+		data.Write(padBytes(b.Bytes(), size))
+		data.WriteByte(byte(len(q.Bytes())))
+		data.Write(q.Bytes())
+		return data
+	}
+	p, q := bls.fq().modulus(), bls.g1().q
+	// a = -3 and b = 2 has zero discriminant
+	singular := encode(p, new(big.Int).Sub(p, big.NewInt(3)), big.NewInt(2), q)
+	// points out of the subgroup pass the on curve check
+	g1Point, g2Point := bls.encodeG1(), bls.encodeG22()
+	orderLen := len(q.Bytes())
+	g1Point.Write(bls.g1().toBytesDense(randCurvePointG1(bls.g1())))
+	g1Point.Write(padBytes(big.NewInt(11).Bytes(), orderLen))
+	g2Point.Write(bls.g22().toBytesDense(randCurvePointG22(bls.g22())))
+	g2Point.Write(padBytes(big.NewInt(11).Bytes(), orderLen))
+	for _, v := range []struct {
+		operation int
+		input     []byte
+		err       string
+	}{
+		// orders of test curves of the family are not prime
+		{OPERATION_BLS12PAIR, testBuilderFromFile(t, "bls12/256.json", newBuilderOptPairing("BLS")).encodeBLSInput().input, ERR_STRICT_GROUP_ORDER_NOT_PRIME},
+		{OPERATION_G1_MUL, encode(new(big.Int).Mul(p, big.NewInt(3)), big.NewInt(0), big.NewInt(4), q).Bytes(), ERR_STRICT_MODULUS_NOT_PRIME},
+		{OPERATION_G1_MUL, singular.Bytes(), ERR_STRICT_CURVE_SINGULAR},
+		{OPERATION_G1_MUL, g1Point.Bytes(), ERR_STRICT_POINT_ORDER},
+		{OPERATION_G2_MUL, g2Point.Bytes(), ERR_STRICT_POINT_ORDER},
+		// embedding degree of mnt4 curves is 4
+		{OPERATION_BLS12PAIR, mnt4.encodeMNT4Input().input, ERR_STRICT_EMBEDDING_DEGREE},
+	} {
+		err := api.Validate(v.operation, v.input)
+		if err == nil || err.Error() != v.err {
+			t.Fatalf("expected %q, got %v", v.err, err)
+		}
+	}
+	// run does not apply strict checks
+	if _, err := api.Run(OPERATION_G1_MUL, g1Point.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,5 +1,10 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
 package eip
 
+//go:generate go run ./asm -output generic -opt D -out x86_arithmetic.s
+
 //go:noescape
 func is_even(a fe) bool
 
@@ -42,6 +47,12 @@ func mul1(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_1(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square1(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_1(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq2(a, b fe) bool
 
@@ -81,6 +92,12 @@ func mul2(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_2(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square2(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_2(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq3(a, b fe) bool
 
@@ -120,6 +137,12 @@ func mul3(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_3(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square3(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_3(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq4(a, b fe) bool
 
@@ -159,6 +182,12 @@ func mul4(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_4(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square4(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_4(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq5(a, b fe) bool
 
@@ -198,6 +227,12 @@ func mul5(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_5(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square5(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_5(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq6(a, b fe) bool
 
@@ -237,6 +272,12 @@ func mul6(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_6(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square6(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_6(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq7(a, b fe) bool
 
@@ -276,6 +317,12 @@ func mul7(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_7(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square7(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_7(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq8(a, b fe) bool
 
@@ -315,6 +362,12 @@ func mul8(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_8(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square8(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_8(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq9(a, b fe) bool
 
@@ -354,6 +407,12 @@ func mul9(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_9(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square9(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_9(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq10(a, b fe) bool
 
@@ -393,6 +452,12 @@ func mul10(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_10(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square10(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_10(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq11(a, b fe) bool
 
@@ -432,6 +497,12 @@ func mul11(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_11(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square11(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_11(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq12(a, b fe) bool
 
@@ -471,6 +542,12 @@ func mul12(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_12(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square12(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_12(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq13(a, b fe) bool
 
@@ -510,6 +587,12 @@ func mul13(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_13(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square13(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_13(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq14(a, b fe) bool
 
@@ -549,6 +632,12 @@ func mul14(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_14(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square14(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_14(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq15(a, b fe) bool
 
@@ -588,6 +677,12 @@ func mul15(c, a, b, p fe, inp uint64)
 //go:noescape
 func mul_no_adx_bmi2_15(c, a, b, p fe, inp uint64)
 
+//go:noescape
+func square15(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_15(c, a, p fe, inp uint64)
+
 //go:noescape
 func eq16(a, b fe) bool
 
@@ -626,3 +721,9 @@ func mul16(c, a, b, p fe, inp uint64)
 
 //go:noescape
 func mul_no_adx_bmi2_16(c, a, b, p fe, inp uint64)
+
+//go:noescape
+func square16(c, a, p fe, inp uint64)
+
+//go:noescape
+func square_no_adx_bmi2_16(c, a, p fe, inp uint64)
@@ -0,0 +1,181 @@
+package eip
+
+import (
+	"math/bits"
+	"reflect"
+	"unsafe"
+)
+
+// generic limb arithmetic, semantics follows x86_arithmetic.s
+
+// limbs returns a slice view of the field element with given limb size
+func limbs(a fe, limbSize int) []uint64 {
+	var data []uint64
+	sh := (*reflect.SliceHeader)(unsafe.Pointer(&data))
+	sh.Data = uintptr(a)
+	sh.Len, sh.Cap = limbSize, limbSize
+	return data
+}
+
+func isEvenGeneric(a fe) bool {
+	return (*[1]uint64)(a)[0]&1 == 0
+}
+
+func eqGeneric(a, b []uint64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func cpyGeneric(dst, src []uint64) {
+	copy(dst, src)
+}
+
+func cmpGeneric(a, b []uint64) int8 {
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] > b[i] {
+			return 1
+		} else if a[i] < b[i] {
+			return -1
+		}
+	}
+	return 0
+}
+
+func addGeneric(c, a, b, p []uint64) {
+	var s, d [16]uint64
+	var carry, borrow uint64
+	n := len(p)
+	for i := 0; i < n; i++ {
+		s[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	for i := 0; i < n; i++ {
+		d[i], borrow = bits.Sub64(s[i], p[i], borrow)
+	}
+	_, borrow = bits.Sub64(carry, 0, borrow)
+	copy(c, s[:n])
+	cmovGeneric(c, d[:n], borrow-1)
+}
+
+func doubleGeneric(c, a, p []uint64) {
+	addGeneric(c, a, a, p)
+}
+
+func subGeneric(c, a, b, p []uint64) {
+	var d [16]uint64
+	var carry, borrow uint64
+	n := len(p)
+	for i := 0; i < n; i++ {
+		d[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	mask := -borrow
+	for i := 0; i < n; i++ {
+		c[i], carry = bits.Add64(d[i], p[i]&mask, carry)
+	}
+}
+
+func negGeneric(c, a, p []uint64) {
+	var borrow uint64
+	for i := range p {
+		c[i], borrow = bits.Sub64(p[i], a[i], borrow)
+	}
+}
+
+func addnGeneric(a, b []uint64) uint64 {
+	var carry uint64
+	for i := range a {
+		a[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	return carry
+}
+
+func subnGeneric(a, b []uint64) uint64 {
+	var borrow uint64
+	for i := range a {
+		a[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	return borrow
+}
+
+func mulTwoGeneric(a []uint64) {
+	for i := len(a) - 1; i > 0; i-- {
+		a[i] = a[i]<<1 | a[i-1]>>63
+	}
+	a[0] <<= 1
+}
+
+func divTwoGeneric(a []uint64) {
+	n := len(a)
+	for i := 0; i < n-1; i++ {
+		a[i] = a[i]>>1 | a[i+1]<<63
+	}
+	a[n-1] >>= 1
+}
+
+// mulGeneric is montgomery multiplication with coarsely integrated operand scanning
+func mulGeneric(c, a, b, p []uint64, inp uint64) {
+	var t [18]uint64
+	var d [16]uint64
+	var carry, hi, lo, cc uint64
+	n := len(p)
+	for i := 0; i < n; i++ {
+		// t = t + a * b_i
+		carry = 0
+		for j := 0; j < n; j++ {
+			hi, lo = bits.Mul64(a[j], b[i])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, carry, 0)
+			hi += cc
+			t[j], carry = lo, hi
+		}
+		t[n], t[n+1] = bits.Add64(t[n], carry, 0)
+		// t = (t + m * p) / w
+		m := t[0] * inp
+		hi, lo = bits.Mul64(m, p[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		carry = hi + cc
+		for j := 1; j < n; j++ {
+			hi, lo = bits.Mul64(m, p[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, carry, 0)
+			hi += cc
+			t[j-1], carry = lo, hi
+		}
+		t[n-1], cc = bits.Add64(t[n], carry, 0)
+		t[n] = t[n+1] + cc
+	}
+	var borrow uint64
+	for i := 0; i < n; i++ {
+		d[i], borrow = bits.Sub64(t[i], p[i], borrow)
+	}
+	_, borrow = bits.Sub64(t[n], 0, borrow)
+	copy(c, t[:n])
+	cmovGeneric(c, d[:n], borrow-1)
+}
+
+// ctMask is all ones if cond is true and zero otherwise, bool is read as
+// a byte so that there is no branch
+func ctMask(cond bool) uint64 {
+	return -uint64(*(*uint8)(unsafe.Pointer(&cond)))
+}
+
+// cmovGeneric sets c to a where mask is set
+func cmovGeneric(c, a []uint64, mask uint64) {
+	for i := range c {
+		c[i] ^= (c[i] ^ a[i]) & mask
+	}
+}
+
+// cswapGeneric swaps a and b where mask is set
+func cswapGeneric(a, b []uint64, mask uint64) {
+	for i := range a {
+		t := (a[i] ^ b[i]) & mask
+		a[i] ^= t
+		b[i] ^= t
+	}
+}
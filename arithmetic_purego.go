@@ -0,0 +1,970 @@
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package eip
+
+// portable implementations of the routines in x86_arithmetic.s
+
+func is_even(a fe) bool {
+	return isEvenGeneric(a)
+}
+
+func eq1(a, b fe) bool {
+	return eqGeneric(limbs(a, 1), limbs(b, 1))
+}
+
+func mul_two_1(a fe) {
+	mulTwoGeneric(limbs(a, 1))
+}
+
+func div_two_1(a fe) {
+	divTwoGeneric(limbs(a, 1))
+}
+
+func cpy1(dst, src fe) {
+	cpyGeneric(limbs(dst, 1), limbs(src, 1))
+}
+
+func cmp1(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 1), limbs(b, 1))
+}
+
+func add1(c, a, b, p fe) {
+	addGeneric(limbs(c, 1), limbs(a, 1), limbs(b, 1), limbs(p, 1))
+}
+
+func addn1(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 1), limbs(b, 1))
+}
+
+func sub1(c, a, b, p fe) {
+	subGeneric(limbs(c, 1), limbs(a, 1), limbs(b, 1), limbs(p, 1))
+}
+
+func subn1(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 1), limbs(b, 1))
+}
+
+func _neg1(c, a, p fe) {
+	negGeneric(limbs(c, 1), limbs(a, 1), limbs(p, 1))
+}
+
+func double1(c, a, p fe) {
+	doubleGeneric(limbs(c, 1), limbs(a, 1), limbs(p, 1))
+}
+
+func mul1(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 1), limbs(a, 1), limbs(b, 1), limbs(p, 1), inp)
+}
+
+func mul_no_adx_bmi2_1(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 1), limbs(a, 1), limbs(b, 1), limbs(p, 1), inp)
+}
+
+func square1(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 1), limbs(a, 1), limbs(a, 1), limbs(p, 1), inp)
+}
+
+func square_no_adx_bmi2_1(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 1), limbs(a, 1), limbs(a, 1), limbs(p, 1), inp)
+}
+
+func eq2(a, b fe) bool {
+	return eqGeneric(limbs(a, 2), limbs(b, 2))
+}
+
+func mul_two_2(a fe) {
+	mulTwoGeneric(limbs(a, 2))
+}
+
+func div_two_2(a fe) {
+	divTwoGeneric(limbs(a, 2))
+}
+
+func cpy2(dst, src fe) {
+	cpyGeneric(limbs(dst, 2), limbs(src, 2))
+}
+
+func cmp2(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 2), limbs(b, 2))
+}
+
+func add2(c, a, b, p fe) {
+	addGeneric(limbs(c, 2), limbs(a, 2), limbs(b, 2), limbs(p, 2))
+}
+
+func addn2(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 2), limbs(b, 2))
+}
+
+func sub2(c, a, b, p fe) {
+	subGeneric(limbs(c, 2), limbs(a, 2), limbs(b, 2), limbs(p, 2))
+}
+
+func subn2(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 2), limbs(b, 2))
+}
+
+func _neg2(c, a, p fe) {
+	negGeneric(limbs(c, 2), limbs(a, 2), limbs(p, 2))
+}
+
+func double2(c, a, p fe) {
+	doubleGeneric(limbs(c, 2), limbs(a, 2), limbs(p, 2))
+}
+
+func mul2(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 2), limbs(a, 2), limbs(b, 2), limbs(p, 2), inp)
+}
+
+func mul_no_adx_bmi2_2(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 2), limbs(a, 2), limbs(b, 2), limbs(p, 2), inp)
+}
+
+func square2(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 2), limbs(a, 2), limbs(a, 2), limbs(p, 2), inp)
+}
+
+func square_no_adx_bmi2_2(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 2), limbs(a, 2), limbs(a, 2), limbs(p, 2), inp)
+}
+
+func eq3(a, b fe) bool {
+	return eqGeneric(limbs(a, 3), limbs(b, 3))
+}
+
+func mul_two_3(a fe) {
+	mulTwoGeneric(limbs(a, 3))
+}
+
+func div_two_3(a fe) {
+	divTwoGeneric(limbs(a, 3))
+}
+
+func cpy3(dst, src fe) {
+	cpyGeneric(limbs(dst, 3), limbs(src, 3))
+}
+
+func cmp3(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 3), limbs(b, 3))
+}
+
+func add3(c, a, b, p fe) {
+	addGeneric(limbs(c, 3), limbs(a, 3), limbs(b, 3), limbs(p, 3))
+}
+
+func addn3(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 3), limbs(b, 3))
+}
+
+func sub3(c, a, b, p fe) {
+	subGeneric(limbs(c, 3), limbs(a, 3), limbs(b, 3), limbs(p, 3))
+}
+
+func subn3(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 3), limbs(b, 3))
+}
+
+func _neg3(c, a, p fe) {
+	negGeneric(limbs(c, 3), limbs(a, 3), limbs(p, 3))
+}
+
+func double3(c, a, p fe) {
+	doubleGeneric(limbs(c, 3), limbs(a, 3), limbs(p, 3))
+}
+
+func mul3(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 3), limbs(a, 3), limbs(b, 3), limbs(p, 3), inp)
+}
+
+func mul_no_adx_bmi2_3(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 3), limbs(a, 3), limbs(b, 3), limbs(p, 3), inp)
+}
+
+func square3(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 3), limbs(a, 3), limbs(a, 3), limbs(p, 3), inp)
+}
+
+func square_no_adx_bmi2_3(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 3), limbs(a, 3), limbs(a, 3), limbs(p, 3), inp)
+}
+
+func eq4(a, b fe) bool {
+	return eqGeneric(limbs(a, 4), limbs(b, 4))
+}
+
+func mul_two_4(a fe) {
+	mulTwoGeneric(limbs(a, 4))
+}
+
+func div_two_4(a fe) {
+	divTwoGeneric(limbs(a, 4))
+}
+
+func cpy4(dst, src fe) {
+	cpyGeneric(limbs(dst, 4), limbs(src, 4))
+}
+
+func cmp4(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 4), limbs(b, 4))
+}
+
+func add4(c, a, b, p fe) {
+	addGeneric(limbs(c, 4), limbs(a, 4), limbs(b, 4), limbs(p, 4))
+}
+
+func addn4(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 4), limbs(b, 4))
+}
+
+func sub4(c, a, b, p fe) {
+	subGeneric(limbs(c, 4), limbs(a, 4), limbs(b, 4), limbs(p, 4))
+}
+
+func subn4(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 4), limbs(b, 4))
+}
+
+func _neg4(c, a, p fe) {
+	negGeneric(limbs(c, 4), limbs(a, 4), limbs(p, 4))
+}
+
+func double4(c, a, p fe) {
+	doubleGeneric(limbs(c, 4), limbs(a, 4), limbs(p, 4))
+}
+
+func mul4(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 4), limbs(a, 4), limbs(b, 4), limbs(p, 4), inp)
+}
+
+func mul_no_adx_bmi2_4(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 4), limbs(a, 4), limbs(b, 4), limbs(p, 4), inp)
+}
+
+func square4(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 4), limbs(a, 4), limbs(a, 4), limbs(p, 4), inp)
+}
+
+func square_no_adx_bmi2_4(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 4), limbs(a, 4), limbs(a, 4), limbs(p, 4), inp)
+}
+
+func eq5(a, b fe) bool {
+	return eqGeneric(limbs(a, 5), limbs(b, 5))
+}
+
+func mul_two_5(a fe) {
+	mulTwoGeneric(limbs(a, 5))
+}
+
+func div_two_5(a fe) {
+	divTwoGeneric(limbs(a, 5))
+}
+
+func cpy5(dst, src fe) {
+	cpyGeneric(limbs(dst, 5), limbs(src, 5))
+}
+
+func cmp5(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 5), limbs(b, 5))
+}
+
+func add5(c, a, b, p fe) {
+	addGeneric(limbs(c, 5), limbs(a, 5), limbs(b, 5), limbs(p, 5))
+}
+
+func addn5(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 5), limbs(b, 5))
+}
+
+func sub5(c, a, b, p fe) {
+	subGeneric(limbs(c, 5), limbs(a, 5), limbs(b, 5), limbs(p, 5))
+}
+
+func subn5(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 5), limbs(b, 5))
+}
+
+func _neg5(c, a, p fe) {
+	negGeneric(limbs(c, 5), limbs(a, 5), limbs(p, 5))
+}
+
+func double5(c, a, p fe) {
+	doubleGeneric(limbs(c, 5), limbs(a, 5), limbs(p, 5))
+}
+
+func mul5(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 5), limbs(a, 5), limbs(b, 5), limbs(p, 5), inp)
+}
+
+func mul_no_adx_bmi2_5(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 5), limbs(a, 5), limbs(b, 5), limbs(p, 5), inp)
+}
+
+func square5(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 5), limbs(a, 5), limbs(a, 5), limbs(p, 5), inp)
+}
+
+func square_no_adx_bmi2_5(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 5), limbs(a, 5), limbs(a, 5), limbs(p, 5), inp)
+}
+
+func eq6(a, b fe) bool {
+	return eqGeneric(limbs(a, 6), limbs(b, 6))
+}
+
+func mul_two_6(a fe) {
+	mulTwoGeneric(limbs(a, 6))
+}
+
+func div_two_6(a fe) {
+	divTwoGeneric(limbs(a, 6))
+}
+
+func cpy6(dst, src fe) {
+	cpyGeneric(limbs(dst, 6), limbs(src, 6))
+}
+
+func cmp6(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 6), limbs(b, 6))
+}
+
+func add6(c, a, b, p fe) {
+	addGeneric(limbs(c, 6), limbs(a, 6), limbs(b, 6), limbs(p, 6))
+}
+
+func addn6(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 6), limbs(b, 6))
+}
+
+func sub6(c, a, b, p fe) {
+	subGeneric(limbs(c, 6), limbs(a, 6), limbs(b, 6), limbs(p, 6))
+}
+
+func subn6(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 6), limbs(b, 6))
+}
+
+func _neg6(c, a, p fe) {
+	negGeneric(limbs(c, 6), limbs(a, 6), limbs(p, 6))
+}
+
+func double6(c, a, p fe) {
+	doubleGeneric(limbs(c, 6), limbs(a, 6), limbs(p, 6))
+}
+
+func mul6(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 6), limbs(a, 6), limbs(b, 6), limbs(p, 6), inp)
+}
+
+func mul_no_adx_bmi2_6(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 6), limbs(a, 6), limbs(b, 6), limbs(p, 6), inp)
+}
+
+func square6(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 6), limbs(a, 6), limbs(a, 6), limbs(p, 6), inp)
+}
+
+func square_no_adx_bmi2_6(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 6), limbs(a, 6), limbs(a, 6), limbs(p, 6), inp)
+}
+
+func eq7(a, b fe) bool {
+	return eqGeneric(limbs(a, 7), limbs(b, 7))
+}
+
+func mul_two_7(a fe) {
+	mulTwoGeneric(limbs(a, 7))
+}
+
+func div_two_7(a fe) {
+	divTwoGeneric(limbs(a, 7))
+}
+
+func cpy7(dst, src fe) {
+	cpyGeneric(limbs(dst, 7), limbs(src, 7))
+}
+
+func cmp7(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 7), limbs(b, 7))
+}
+
+func add7(c, a, b, p fe) {
+	addGeneric(limbs(c, 7), limbs(a, 7), limbs(b, 7), limbs(p, 7))
+}
+
+func addn7(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 7), limbs(b, 7))
+}
+
+func sub7(c, a, b, p fe) {
+	subGeneric(limbs(c, 7), limbs(a, 7), limbs(b, 7), limbs(p, 7))
+}
+
+func subn7(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 7), limbs(b, 7))
+}
+
+func _neg7(c, a, p fe) {
+	negGeneric(limbs(c, 7), limbs(a, 7), limbs(p, 7))
+}
+
+func double7(c, a, p fe) {
+	doubleGeneric(limbs(c, 7), limbs(a, 7), limbs(p, 7))
+}
+
+func mul7(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 7), limbs(a, 7), limbs(b, 7), limbs(p, 7), inp)
+}
+
+func mul_no_adx_bmi2_7(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 7), limbs(a, 7), limbs(b, 7), limbs(p, 7), inp)
+}
+
+func square7(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 7), limbs(a, 7), limbs(a, 7), limbs(p, 7), inp)
+}
+
+func square_no_adx_bmi2_7(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 7), limbs(a, 7), limbs(a, 7), limbs(p, 7), inp)
+}
+
+func eq8(a, b fe) bool {
+	return eqGeneric(limbs(a, 8), limbs(b, 8))
+}
+
+func mul_two_8(a fe) {
+	mulTwoGeneric(limbs(a, 8))
+}
+
+func div_two_8(a fe) {
+	divTwoGeneric(limbs(a, 8))
+}
+
+func cpy8(dst, src fe) {
+	cpyGeneric(limbs(dst, 8), limbs(src, 8))
+}
+
+func cmp8(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 8), limbs(b, 8))
+}
+
+func add8(c, a, b, p fe) {
+	addGeneric(limbs(c, 8), limbs(a, 8), limbs(b, 8), limbs(p, 8))
+}
+
+func addn8(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 8), limbs(b, 8))
+}
+
+func sub8(c, a, b, p fe) {
+	subGeneric(limbs(c, 8), limbs(a, 8), limbs(b, 8), limbs(p, 8))
+}
+
+func subn8(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 8), limbs(b, 8))
+}
+
+func _neg8(c, a, p fe) {
+	negGeneric(limbs(c, 8), limbs(a, 8), limbs(p, 8))
+}
+
+func double8(c, a, p fe) {
+	doubleGeneric(limbs(c, 8), limbs(a, 8), limbs(p, 8))
+}
+
+func mul8(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 8), limbs(a, 8), limbs(b, 8), limbs(p, 8), inp)
+}
+
+func mul_no_adx_bmi2_8(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 8), limbs(a, 8), limbs(b, 8), limbs(p, 8), inp)
+}
+
+func square8(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 8), limbs(a, 8), limbs(a, 8), limbs(p, 8), inp)
+}
+
+func square_no_adx_bmi2_8(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 8), limbs(a, 8), limbs(a, 8), limbs(p, 8), inp)
+}
+
+func eq9(a, b fe) bool {
+	return eqGeneric(limbs(a, 9), limbs(b, 9))
+}
+
+func mul_two_9(a fe) {
+	mulTwoGeneric(limbs(a, 9))
+}
+
+func div_two_9(a fe) {
+	divTwoGeneric(limbs(a, 9))
+}
+
+func cpy9(dst, src fe) {
+	cpyGeneric(limbs(dst, 9), limbs(src, 9))
+}
+
+func cmp9(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 9), limbs(b, 9))
+}
+
+func add9(c, a, b, p fe) {
+	addGeneric(limbs(c, 9), limbs(a, 9), limbs(b, 9), limbs(p, 9))
+}
+
+func addn9(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 9), limbs(b, 9))
+}
+
+func sub9(c, a, b, p fe) {
+	subGeneric(limbs(c, 9), limbs(a, 9), limbs(b, 9), limbs(p, 9))
+}
+
+func subn9(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 9), limbs(b, 9))
+}
+
+func _neg9(c, a, p fe) {
+	negGeneric(limbs(c, 9), limbs(a, 9), limbs(p, 9))
+}
+
+func double9(c, a, p fe) {
+	doubleGeneric(limbs(c, 9), limbs(a, 9), limbs(p, 9))
+}
+
+func mul9(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 9), limbs(a, 9), limbs(b, 9), limbs(p, 9), inp)
+}
+
+func mul_no_adx_bmi2_9(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 9), limbs(a, 9), limbs(b, 9), limbs(p, 9), inp)
+}
+
+func square9(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 9), limbs(a, 9), limbs(a, 9), limbs(p, 9), inp)
+}
+
+func square_no_adx_bmi2_9(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 9), limbs(a, 9), limbs(a, 9), limbs(p, 9), inp)
+}
+
+func eq10(a, b fe) bool {
+	return eqGeneric(limbs(a, 10), limbs(b, 10))
+}
+
+func mul_two_10(a fe) {
+	mulTwoGeneric(limbs(a, 10))
+}
+
+func div_two_10(a fe) {
+	divTwoGeneric(limbs(a, 10))
+}
+
+func cpy10(dst, src fe) {
+	cpyGeneric(limbs(dst, 10), limbs(src, 10))
+}
+
+func cmp10(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 10), limbs(b, 10))
+}
+
+func add10(c, a, b, p fe) {
+	addGeneric(limbs(c, 10), limbs(a, 10), limbs(b, 10), limbs(p, 10))
+}
+
+func addn10(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 10), limbs(b, 10))
+}
+
+func sub10(c, a, b, p fe) {
+	subGeneric(limbs(c, 10), limbs(a, 10), limbs(b, 10), limbs(p, 10))
+}
+
+func subn10(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 10), limbs(b, 10))
+}
+
+func _neg10(c, a, p fe) {
+	negGeneric(limbs(c, 10), limbs(a, 10), limbs(p, 10))
+}
+
+func double10(c, a, p fe) {
+	doubleGeneric(limbs(c, 10), limbs(a, 10), limbs(p, 10))
+}
+
+func mul10(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 10), limbs(a, 10), limbs(b, 10), limbs(p, 10), inp)
+}
+
+func mul_no_adx_bmi2_10(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 10), limbs(a, 10), limbs(b, 10), limbs(p, 10), inp)
+}
+
+func square10(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 10), limbs(a, 10), limbs(a, 10), limbs(p, 10), inp)
+}
+
+func square_no_adx_bmi2_10(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 10), limbs(a, 10), limbs(a, 10), limbs(p, 10), inp)
+}
+
+func eq11(a, b fe) bool {
+	return eqGeneric(limbs(a, 11), limbs(b, 11))
+}
+
+func mul_two_11(a fe) {
+	mulTwoGeneric(limbs(a, 11))
+}
+
+func div_two_11(a fe) {
+	divTwoGeneric(limbs(a, 11))
+}
+
+func cpy11(dst, src fe) {
+	cpyGeneric(limbs(dst, 11), limbs(src, 11))
+}
+
+func cmp11(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 11), limbs(b, 11))
+}
+
+func add11(c, a, b, p fe) {
+	addGeneric(limbs(c, 11), limbs(a, 11), limbs(b, 11), limbs(p, 11))
+}
+
+func addn11(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 11), limbs(b, 11))
+}
+
+func sub11(c, a, b, p fe) {
+	subGeneric(limbs(c, 11), limbs(a, 11), limbs(b, 11), limbs(p, 11))
+}
+
+func subn11(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 11), limbs(b, 11))
+}
+
+func _neg11(c, a, p fe) {
+	negGeneric(limbs(c, 11), limbs(a, 11), limbs(p, 11))
+}
+
+func double11(c, a, p fe) {
+	doubleGeneric(limbs(c, 11), limbs(a, 11), limbs(p, 11))
+}
+
+func mul11(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 11), limbs(a, 11), limbs(b, 11), limbs(p, 11), inp)
+}
+
+func mul_no_adx_bmi2_11(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 11), limbs(a, 11), limbs(b, 11), limbs(p, 11), inp)
+}
+
+func square11(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 11), limbs(a, 11), limbs(a, 11), limbs(p, 11), inp)
+}
+
+func square_no_adx_bmi2_11(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 11), limbs(a, 11), limbs(a, 11), limbs(p, 11), inp)
+}
+
+func eq12(a, b fe) bool {
+	return eqGeneric(limbs(a, 12), limbs(b, 12))
+}
+
+func mul_two_12(a fe) {
+	mulTwoGeneric(limbs(a, 12))
+}
+
+func div_two_12(a fe) {
+	divTwoGeneric(limbs(a, 12))
+}
+
+func cpy12(dst, src fe) {
+	cpyGeneric(limbs(dst, 12), limbs(src, 12))
+}
+
+func cmp12(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 12), limbs(b, 12))
+}
+
+func add12(c, a, b, p fe) {
+	addGeneric(limbs(c, 12), limbs(a, 12), limbs(b, 12), limbs(p, 12))
+}
+
+func addn12(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 12), limbs(b, 12))
+}
+
+func sub12(c, a, b, p fe) {
+	subGeneric(limbs(c, 12), limbs(a, 12), limbs(b, 12), limbs(p, 12))
+}
+
+func subn12(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 12), limbs(b, 12))
+}
+
+func _neg12(c, a, p fe) {
+	negGeneric(limbs(c, 12), limbs(a, 12), limbs(p, 12))
+}
+
+func double12(c, a, p fe) {
+	doubleGeneric(limbs(c, 12), limbs(a, 12), limbs(p, 12))
+}
+
+func mul12(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 12), limbs(a, 12), limbs(b, 12), limbs(p, 12), inp)
+}
+
+func mul_no_adx_bmi2_12(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 12), limbs(a, 12), limbs(b, 12), limbs(p, 12), inp)
+}
+
+func square12(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 12), limbs(a, 12), limbs(a, 12), limbs(p, 12), inp)
+}
+
+func square_no_adx_bmi2_12(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 12), limbs(a, 12), limbs(a, 12), limbs(p, 12), inp)
+}
+
+func eq13(a, b fe) bool {
+	return eqGeneric(limbs(a, 13), limbs(b, 13))
+}
+
+func mul_two_13(a fe) {
+	mulTwoGeneric(limbs(a, 13))
+}
+
+func div_two_13(a fe) {
+	divTwoGeneric(limbs(a, 13))
+}
+
+func cpy13(dst, src fe) {
+	cpyGeneric(limbs(dst, 13), limbs(src, 13))
+}
+
+func cmp13(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 13), limbs(b, 13))
+}
+
+func add13(c, a, b, p fe) {
+	addGeneric(limbs(c, 13), limbs(a, 13), limbs(b, 13), limbs(p, 13))
+}
+
+func addn13(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 13), limbs(b, 13))
+}
+
+func sub13(c, a, b, p fe) {
+	subGeneric(limbs(c, 13), limbs(a, 13), limbs(b, 13), limbs(p, 13))
+}
+
+func subn13(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 13), limbs(b, 13))
+}
+
+func _neg13(c, a, p fe) {
+	negGeneric(limbs(c, 13), limbs(a, 13), limbs(p, 13))
+}
+
+func double13(c, a, p fe) {
+	doubleGeneric(limbs(c, 13), limbs(a, 13), limbs(p, 13))
+}
+
+func mul13(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 13), limbs(a, 13), limbs(b, 13), limbs(p, 13), inp)
+}
+
+func mul_no_adx_bmi2_13(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 13), limbs(a, 13), limbs(b, 13), limbs(p, 13), inp)
+}
+
+func square13(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 13), limbs(a, 13), limbs(a, 13), limbs(p, 13), inp)
+}
+
+func square_no_adx_bmi2_13(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 13), limbs(a, 13), limbs(a, 13), limbs(p, 13), inp)
+}
+
+func eq14(a, b fe) bool {
+	return eqGeneric(limbs(a, 14), limbs(b, 14))
+}
+
+func mul_two_14(a fe) {
+	mulTwoGeneric(limbs(a, 14))
+}
+
+func div_two_14(a fe) {
+	divTwoGeneric(limbs(a, 14))
+}
+
+func cpy14(dst, src fe) {
+	cpyGeneric(limbs(dst, 14), limbs(src, 14))
+}
+
+func cmp14(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 14), limbs(b, 14))
+}
+
+func add14(c, a, b, p fe) {
+	addGeneric(limbs(c, 14), limbs(a, 14), limbs(b, 14), limbs(p, 14))
+}
+
+func addn14(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 14), limbs(b, 14))
+}
+
+func sub14(c, a, b, p fe) {
+	subGeneric(limbs(c, 14), limbs(a, 14), limbs(b, 14), limbs(p, 14))
+}
+
+func subn14(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 14), limbs(b, 14))
+}
+
+func _neg14(c, a, p fe) {
+	negGeneric(limbs(c, 14), limbs(a, 14), limbs(p, 14))
+}
+
+func double14(c, a, p fe) {
+	doubleGeneric(limbs(c, 14), limbs(a, 14), limbs(p, 14))
+}
+
+func mul14(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 14), limbs(a, 14), limbs(b, 14), limbs(p, 14), inp)
+}
+
+func mul_no_adx_bmi2_14(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 14), limbs(a, 14), limbs(b, 14), limbs(p, 14), inp)
+}
+
+func square14(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 14), limbs(a, 14), limbs(a, 14), limbs(p, 14), inp)
+}
+
+func square_no_adx_bmi2_14(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 14), limbs(a, 14), limbs(a, 14), limbs(p, 14), inp)
+}
+
+func eq15(a, b fe) bool {
+	return eqGeneric(limbs(a, 15), limbs(b, 15))
+}
+
+func mul_two_15(a fe) {
+	mulTwoGeneric(limbs(a, 15))
+}
+
+func div_two_15(a fe) {
+	divTwoGeneric(limbs(a, 15))
+}
+
+func cpy15(dst, src fe) {
+	cpyGeneric(limbs(dst, 15), limbs(src, 15))
+}
+
+func cmp15(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 15), limbs(b, 15))
+}
+
+func add15(c, a, b, p fe) {
+	addGeneric(limbs(c, 15), limbs(a, 15), limbs(b, 15), limbs(p, 15))
+}
+
+func addn15(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 15), limbs(b, 15))
+}
+
+func sub15(c, a, b, p fe) {
+	subGeneric(limbs(c, 15), limbs(a, 15), limbs(b, 15), limbs(p, 15))
+}
+
+func subn15(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 15), limbs(b, 15))
+}
+
+func _neg15(c, a, p fe) {
+	negGeneric(limbs(c, 15), limbs(a, 15), limbs(p, 15))
+}
+
+func double15(c, a, p fe) {
+	doubleGeneric(limbs(c, 15), limbs(a, 15), limbs(p, 15))
+}
+
+func mul15(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 15), limbs(a, 15), limbs(b, 15), limbs(p, 15), inp)
+}
+
+func mul_no_adx_bmi2_15(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 15), limbs(a, 15), limbs(b, 15), limbs(p, 15), inp)
+}
+
+func square15(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 15), limbs(a, 15), limbs(a, 15), limbs(p, 15), inp)
+}
+
+func square_no_adx_bmi2_15(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 15), limbs(a, 15), limbs(a, 15), limbs(p, 15), inp)
+}
+
+func eq16(a, b fe) bool {
+	return eqGeneric(limbs(a, 16), limbs(b, 16))
+}
+
+func mul_two_16(a fe) {
+	mulTwoGeneric(limbs(a, 16))
+}
+
+func div_two_16(a fe) {
+	divTwoGeneric(limbs(a, 16))
+}
+
+func cpy16(dst, src fe) {
+	cpyGeneric(limbs(dst, 16), limbs(src, 16))
+}
+
+func cmp16(a, b fe) int8 {
+	return cmpGeneric(limbs(a, 16), limbs(b, 16))
+}
+
+func add16(c, a, b, p fe) {
+	addGeneric(limbs(c, 16), limbs(a, 16), limbs(b, 16), limbs(p, 16))
+}
+
+func addn16(a, b fe) uint64 {
+	return addnGeneric(limbs(a, 16), limbs(b, 16))
+}
+
+func sub16(c, a, b, p fe) {
+	subGeneric(limbs(c, 16), limbs(a, 16), limbs(b, 16), limbs(p, 16))
+}
+
+func subn16(a, b fe) uint64 {
+	return subnGeneric(limbs(a, 16), limbs(b, 16))
+}
+
+func _neg16(c, a, p fe) {
+	negGeneric(limbs(c, 16), limbs(a, 16), limbs(p, 16))
+}
+
+func double16(c, a, p fe) {
+	doubleGeneric(limbs(c, 16), limbs(a, 16), limbs(p, 16))
+}
+
+func mul16(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 16), limbs(a, 16), limbs(b, 16), limbs(p, 16), inp)
+}
+
+func mul_no_adx_bmi2_16(c, a, b, p fe, inp uint64) {
+	mulGeneric(limbs(c, 16), limbs(a, 16), limbs(b, 16), limbs(p, 16), inp)
+}
+
+func square16(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 16), limbs(a, 16), limbs(a, 16), limbs(p, 16), inp)
+}
+
+func square_no_adx_bmi2_16(c, a, p fe, inp uint64) {
+	mulGeneric(limbs(c, 16), limbs(a, 16), limbs(a, 16), limbs(p, 16), inp)
+}
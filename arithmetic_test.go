@@ -0,0 +1,84 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+package eip
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+// cross check assembly against the portable backend
+func TestArithmeticGenericAgainstAsm(t *testing.T) {
+	for limbSize := from; limbSize < to+1; limbSize++ {
+		t.Run(fmt.Sprintf("%d", limbSize*64), func(t *testing.T) {
+			for i := 0; i < fuz; i++ {
+				field := randFq(limbSize)
+				n := field.limbSize
+				pMinusOne := field.new()
+				field.sub(pMinusOne, field.zero, field.one)
+				elems := []fe{field.zero, field.one, field.r, pMinusOne}
+				for j := 0; j < 50; j++ {
+					elems = append(elems, field.rand(rand.Reader))
+				}
+				for _, a := range elems {
+					b := field.rand(rand.Reader)
+					c0, c1 := field.new(), field.new()
+					check := func(op string) {
+						if !eqGeneric(limbs(c0, n), limbs(c1, n)) {
+							t.Fatalf("%s mismatch\n%s\n%s", op, field.toStringNoTransform(c0), field.toStringNoTransform(c1))
+						}
+					}
+					field._add(c0, a, b, field.p)
+					addGeneric(limbs(c1, n), limbs(a, n), limbs(b, n), limbs(field.p, n))
+					check("add")
+					field._double(c0, a, field.p)
+					doubleGeneric(limbs(c1, n), limbs(a, n), limbs(field.p, n))
+					check("double")
+					field._sub(c0, a, b, field.p)
+					subGeneric(limbs(c1, n), limbs(a, n), limbs(b, n), limbs(field.p, n))
+					check("sub")
+					field._neg(c0, a, field.p)
+					negGeneric(limbs(c1, n), limbs(a, n), limbs(field.p, n))
+					check("neg")
+					field._mul(c0, a, b, field.p, field.inp)
+					mulGeneric(limbs(c1, n), limbs(a, n), limbs(b, n), limbs(field.p, n), field.inp)
+					check("mul")
+					field._square(c0, a, field.p, field.inp)
+					mulGeneric(limbs(c1, n), limbs(a, n), limbs(a, n), limbs(field.p, n), field.inp)
+					check("square")
+					// in place operations
+					field.copy(c0, a)
+					cpyGeneric(limbs(c1, n), limbs(a, n))
+					check("copy")
+					// assembly does not clear the carry register so only borrow is compared
+					field.addn(c0, b)
+					addnGeneric(limbs(c1, n), limbs(b, n))
+					check("addn")
+					borrow0 := field.subn(c0, pMinusOne)
+					borrow1 := subnGeneric(limbs(c1, n), limbs(pMinusOne, n))
+					check("subn")
+					if borrow0 != borrow1 {
+						t.Fatal("borrow mismatch")
+					}
+					field.mul_two(c0)
+					mulTwoGeneric(limbs(c1, n))
+					check("mul_two")
+					field.div_two(c0)
+					divTwoGeneric(limbs(c1, n))
+					check("div_two")
+					if field.cmp(a, b) != cmpGeneric(limbs(a, n), limbs(b, n)) {
+						t.Fatal("cmp mismatch")
+					}
+					if field.equal(a, b) != eqGeneric(limbs(a, n), limbs(b, n)) {
+						t.Fatal("eq mismatch")
+					}
+					if is_even(a) != isEvenGeneric(a) {
+						t.Fatal("is_even mismatch")
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	. "github.com/mmcloughlin/avo/operand"
+	. "github.com/mmcloughlin/avo/reg"
+)
+
+// registers that hold limbs, values that does not fit are kept in stack
+var limbRegisters = []Register{RCX, RDX, R8, R9, R10, R11, R12, R13, R14, R15}
+
+// slots allocates registers and then stack for given number of values,
+// indexing is continued from offset
+type slots struct {
+	g      *gen
+	offset int
+}
+
+func (s *slots) next(n int) []Op {
+	values := make([]Op, n)
+	for i := 0; i < n; i++ {
+		if s.offset < len(limbRegisters) {
+			values[i] = limbRegisters[s.offset]
+		} else {
+			values[i] = s.g.AllocLocal(8)
+		}
+		s.offset++
+	}
+	return values
+}
+
+func isStack(op Op) bool {
+	return IsMem(op)
+}
+
+func at(base Register, i int) Mem {
+	return Mem{Base: base, Disp: 8 * i}
+}
+
+func (g *gen) cpy() {
+	g.function("cpy", "func(dst *[%[1]d]uint64, src *[%[1]d]uint64)")
+	g.Load(g.Param("dst"), RDI)
+	g.Load(g.Param("src"), RSI)
+	for i := 0; i < g.size; i++ {
+		g.MOVQ(at(RSI, i), R8)
+		g.MOVQ(R8, at(RDI, i))
+	}
+	g.RET()
+}
+
+func (g *gen) eq() {
+	g.function("eq", "func(a *[%[1]d]uint64, b *[%[1]d]uint64) bool")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	ret, _ := g.ReturnIndex(0).Resolve()
+	g.MOVB(U8(0), ret.Addr)
+	for i := 0; i < g.size; i++ {
+		g.MOVQ(at(RDI, i), R8)
+		g.CMPQ(at(RSI, i), R8)
+		g.JNE(LabelRef("ret"))
+	}
+	g.MOVB(U8(1), ret.Addr)
+	g.Label("ret")
+	g.RET()
+}
+
+func (g *gen) cmp() {
+	g.function("cmp", "func(a *[%[1]d]uint64, b *[%[1]d]uint64) int8")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	ret, _ := g.ReturnIndex(0).Resolve()
+	for i := g.size - 1; i >= 0; i-- {
+		g.MOVQ(at(RDI, i), R8)
+		g.CMPQ(at(RSI, i), R8)
+		g.JB(LabelRef("gt"))
+		g.JA(LabelRef("lt"))
+	}
+	g.MOVB(U8(0), ret.Addr)
+	g.JMP(LabelRef("ret"))
+	g.Label("gt")
+	g.MOVB(U8(1), ret.Addr)
+	g.JMP(LabelRef("ret"))
+	g.Label("lt")
+	g.MOVB(U8(0xff), ret.Addr)
+	g.Label("ret")
+	g.RET()
+}
+
+// loadAdd computes dst = a_i + b_i with carry, stack values are processed in BX
+func (g *gen) addCarry(i int, src, b Op, dst Op, first bool) {
+	r := dst
+	if isStack(dst) {
+		r = RBX
+	}
+	g.MOVQ(src, r)
+	if first {
+		g.ADDQ(b, r)
+	} else {
+		g.ADCQ(b, r)
+	}
+	if isStack(dst) {
+		g.MOVQ(r, dst)
+	}
+}
+
+func (g *gen) subBorrow(i int, src, b Op, dst Op, first bool) {
+	r := dst
+	if isStack(dst) {
+		r = RBX
+	}
+	g.MOVQ(src, r)
+	if first {
+		g.SUBQ(b, r)
+	} else {
+		g.SBBQ(b, r)
+	}
+	if isStack(dst) {
+		g.MOVQ(r, dst)
+	}
+}
+
+// reduce subtracts modulus from sum and writes the one that is in range to output
+func (g *gen) reduce(sum []Op, s *slots) {
+	diff := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		g.subBorrow(i, sum[i], at(RSI, i), diff[i], i == 0)
+	}
+	g.SBBQ(U8(0), RAX)
+
+	g.Comment("|")
+	g.Load(g.Param("c"), RDI)
+	for i := 0; i < g.size; i++ {
+		if isStack(sum[i]) {
+			g.MOVQ(sum[i], RBX)
+			g.CMOVQCC(diff[i], RBX)
+			g.MOVQ(RBX, at(RDI, i))
+			continue
+		}
+		g.CMOVQCC(diff[i], sum[i])
+		g.MOVQ(sum[i], at(RDI, i))
+	}
+	g.RET()
+	g.end()
+	g.RET()
+}
+
+func (g *gen) add() {
+	g.function("add", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, b *[%[1]d]uint64, p *[%[1]d]uint64)")
+	s := &slots{g: g}
+	g.Comment("|")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	g.XORQ(RAX, RAX)
+
+	g.Comment("|")
+	sum := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		g.addCarry(i, at(RDI, i), at(RSI, i), sum[i], i == 0)
+	}
+	g.ADCQ(U8(0), RAX)
+
+	g.Comment("|")
+	g.Load(g.Param("p"), RSI)
+	g.reduce(sum, s)
+}
+
+func (g *gen) double() {
+	g.function("double", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, p *[%[1]d]uint64)")
+	s := &slots{g: g}
+	g.Comment("|")
+	g.Load(g.Param("a"), RDI)
+	g.XORQ(RAX, RAX)
+	sum := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		r := sum[i]
+		if isStack(r) {
+			r = RBX
+		}
+		g.MOVQ(at(RDI, i), r)
+		if i == 0 {
+			g.ADDQ(r, r)
+		} else {
+			g.ADCQ(r, r)
+		}
+		if isStack(sum[i]) {
+			g.MOVQ(r, sum[i])
+		}
+	}
+	g.ADCQ(U8(0), RAX)
+
+	g.Comment("|")
+	g.Load(g.Param("p"), RSI)
+	g.reduce(sum, s)
+}
+
+func (g *gen) sub() {
+	g.function("sub", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, b *[%[1]d]uint64, p *[%[1]d]uint64)")
+	s := &slots{g: g}
+	g.Comment("|")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	g.XORQ(RAX, RAX)
+	diff := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		g.subBorrow(i, at(RDI, i), at(RSI, i), diff[i], i == 0)
+	}
+
+	g.Comment("|")
+	g.Load(g.Param("p"), RSI)
+	// modulus if there is a borrow, zero otherwise
+	mask := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		if isStack(mask[i]) {
+			g.CMOVQCS(at(RSI, i), RAX)
+			g.MOVQ(RAX, mask[i])
+			continue
+		}
+		g.MOVQ(at(RSI, i), mask[i])
+		g.CMOVQCC(RAX, mask[i])
+	}
+
+	g.Comment("|")
+	g.Load(g.Param("c"), RDI)
+	for i := 0; i < g.size; i++ {
+		r := diff[i]
+		if isStack(r) {
+			r = RBX
+			g.MOVQ(diff[i], r)
+		}
+		if i == 0 {
+			g.ADDQ(mask[i], r)
+		} else {
+			g.ADCQ(mask[i], r)
+		}
+		g.MOVQ(r, at(RDI, i))
+	}
+	g.RET()
+	g.end()
+	g.RET()
+}
+
+func (g *gen) addn() {
+	g.function("addn", "func(a *[%[1]d]uint64, b *[%[1]d]uint64) uint64")
+	s := &slots{g: g}
+	g.Comment("|")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+
+	g.Comment("|")
+	sum := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		g.addCarry(i, at(RDI, i), at(RSI, i), sum[i], i == 0)
+	}
+	g.ADCQ(U8(0), RAX)
+
+	g.Comment("|")
+	g.writeBack(sum, RDI)
+	g.Store(RAX, g.ReturnIndex(0))
+	g.RET()
+	g.end()
+	g.RET()
+}
+
+func (g *gen) subn() {
+	g.function("subn", "func(a *[%[1]d]uint64, b *[%[1]d]uint64) uint64")
+	s := &slots{g: g}
+	g.Comment("|")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	g.XORQ(RAX, RAX)
+
+	g.Comment("|")
+	diff := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		g.subBorrow(i, at(RDI, i), at(RSI, i), diff[i], i == 0)
+	}
+	g.ADCQ(U8(0), RAX)
+
+	g.Comment("|")
+	g.writeBack(diff, RDI)
+	g.Store(RAX, g.ReturnIndex(0))
+	g.RET()
+	g.end()
+	g.RET()
+}
+
+func (g *gen) neg() {
+	g.function("_neg", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, p *[%[1]d]uint64)")
+	s := &slots{g: g}
+	g.Comment("|")
+	g.Load(g.Param("a"), RDI)
+
+	g.Comment("|")
+	g.Load(g.Param("p"), RSI)
+	diff := s.next(g.size)
+	for i := 0; i < g.size; i++ {
+		g.subBorrow(i, at(RSI, i), at(RDI, i), diff[i], i == 0)
+	}
+
+	g.Comment("|")
+	g.Load(g.Param("c"), RDI)
+	g.writeBack(diff, RDI)
+	g.RET()
+	g.end()
+	g.RET()
+}
+
+func (g *gen) writeBack(values []Op, base Register) {
+	for i, v := range values {
+		if isStack(v) {
+			g.MOVQ(v, RBX)
+			v = RBX
+		}
+		g.MOVQ(v, at(base, i))
+	}
+}
+
+func (g *gen) mulTwo() {
+	g.function("mul_two_", "func(a *[%[1]d]uint64)")
+	g.Load(g.Param("a"), RDI)
+	g.XORQ(RAX, RAX)
+	for i := 0; i < g.size; i++ {
+		g.RCLQ(U8(1), at(RDI, i))
+	}
+	g.RET()
+}
+
+func (g *gen) divTwo() {
+	g.function("div_two_", "func(a *[%[1]d]uint64)")
+	g.Load(g.Param("a"), RDI)
+	g.XORQ(RAX, RAX)
+	for i := g.size - 1; i >= 0; i-- {
+		g.RCRQ(U8(1), at(RDI, i))
+	}
+	g.RET()
+}
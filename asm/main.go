@@ -0,0 +1,129 @@
+// Command asm generates x86_arithmetic.s, the limb arithmetic
+// of base field for amd64.
+//
+//	go generate github.com/saitima/eip1962
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/mmcloughlin/avo/attr"
+	"github.com/mmcloughlin/avo/build"
+	"github.com/mmcloughlin/avo/buildtags"
+	"github.com/mmcloughlin/avo/pass"
+	"github.com/mmcloughlin/avo/printer"
+)
+
+const maxLimbs = 16
+
+// constraints of the generated file
+const (
+	goBuild   = "amd64 && !purego"
+	plusBuild = "amd64,!purego"
+)
+
+func main() {
+	// avo registers its own flags to the default set
+	flags := flag.NewFlagSet("asm", flag.ExitOnError)
+	output := flags.String("output", "generic", "generic: modulus is an argument of routines")
+	opt := flags.String("opt", "D", "D: ADX/BMI2 multiplication with a fallback for older processors")
+	out := flags.String("out", "", "output file, stdout if empty")
+	flags.Parse(os.Args[1:])
+	var buf bytes.Buffer
+	if err := generate(&buf, *output, *opt); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := ioutil.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(w io.Writer, output, opt string) error {
+	if output != "generic" {
+		return errors.New("only generic output is supported")
+	}
+	if opt != "D" {
+		return errors.New("only D optimization is supported")
+	}
+	ctx := build.NewContext()
+	constraints, err := buildtags.ParseConstraint(plusBuild)
+	if err != nil {
+		return err
+	}
+	ctx.Constraint(constraints)
+	for i := 1; i <= maxLimbs; i++ {
+		g := &gen{Context: ctx, size: i}
+		g.cpy()
+		g.eq()
+		g.cmp()
+		g.add()
+		g.addn()
+		g.double()
+		g.sub()
+		g.subn()
+		g.neg()
+		g.mulTwo()
+		g.divTwo()
+		if i > 1 {
+			g.mul()
+			g.mulNoADXBMI2()
+		}
+		g.square()
+		g.squareNoADXBMI2()
+	}
+	f, err := ctx.Result()
+	if err != nil {
+		return err
+	}
+	if err := pass.Compile.Execute(f); err != nil {
+		return err
+	}
+	cfg := printer.Config{
+		Argv: []string{"go", "run", "main.go", "-output", output, "-opt", opt},
+		Pkg:  "eip",
+	}
+	b, err := printer.NewGoAsm(cfg).Print(f)
+	if err != nil {
+		return err
+	}
+	// printer of this avo version knows only +build lines
+	b = bytes.Replace(b, []byte("// +build"), []byte("//go:build "+goBuild+"\n// +build"), 1)
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, tail)
+	return err
+}
+
+// gen emits routines for a fixed number of limbs
+type gen struct {
+	*build.Context
+	size int
+}
+
+func (g *gen) function(name, signature string) {
+	g.Function(fmt.Sprintf("%s%d", name, g.size))
+	g.Attributes(attr.NOSPLIT)
+	g.SignatureExpr(fmt.Sprintf(signature, g.size))
+}
+
+// title emits a section comment
+func (g *gen) title(s string) {
+	g.Comment(fmt.Sprintf("| \n\n/* %-40s*/\n", s))
+}
+
+func (g *gen) end() {
+	g.title("end")
+}
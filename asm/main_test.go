@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+// committed assembly should be regenerated after changes to the generator
+func TestGeneratedMatchesCommitted(t *testing.T) {
+	committed, err := ioutil.ReadFile("../x86_arithmetic.s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := generate(&buf, "generic", "D"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), committed) {
+		a, b := bytes.Split(buf.Bytes(), []byte("\n")), bytes.Split(committed, []byte("\n"))
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if !bytes.Equal(a[i], b[i]) {
+				t.Fatalf("x86_arithmetic.s is stale at line %d, run go generate\nhave %q\nwant %q", i+1, b[i], a[i])
+			}
+		}
+		t.Fatalf("x86_arithmetic.s is stale, run go generate")
+	}
+}
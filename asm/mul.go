@@ -0,0 +1,605 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmcloughlin/avo/ir"
+
+	. "github.com/mmcloughlin/avo/operand"
+	. "github.com/mmcloughlin/avo/reg"
+)
+
+// registers that are used for partial products, assigned in round
+var productRegisters = []Register{RCX, R8, R9, R10, R11, R12, R13, R14, R15}
+
+// preference order of idle registers
+var idleRegisters = []Register{RCX, RAX, RBX, RDI, RSI, R8, R9, R10, R11, R12, R13, R14, R15}
+
+// inp is the address of montgomery constant argument
+func (g *gen) inp() Mem {
+	b, err := g.Param("inp").Resolve()
+	if err != nil {
+		panic(err)
+	}
+	return b.Addr
+}
+
+// table prints locations of words of double sized product
+func (g *gen) table(title string, w []Op) {
+	lines := []string{"| ", "| " + title}
+	for row := 0; row < 2; row++ {
+		var entries []string
+		for i := row * g.size; i < (row+1)*g.size; i++ {
+			loc := "-"
+			if w[i] != nil {
+				loc = w[i].Asm()
+			}
+			entries = append(entries, fmt.Sprintf("%-4d%-10s", i, loc))
+		}
+		lines = append(lines, "| "+strings.Join(entries, "| "))
+	}
+	lines[len(lines)-1] += "\n\n"
+	g.Comment(lines...)
+}
+
+// zero clears the register without touching flags, avo accepts only
+// 32 bit immediates for MOVQ while the byte form is printed shorter
+func (g *gen) zero(r Op) {
+	g.Instruction(&ir.Instruction{
+		Opcode:   "MOVQ",
+		Operands: []Op{U8(0), r},
+		Outputs:  []Op{r},
+	})
+}
+
+// frame hands out 8 byte stack slots, lowest free slot first
+type frame struct {
+	g     *gen
+	slots []Mem
+	used  []bool
+}
+
+func (f *frame) alloc() Mem {
+	for i, used := range f.used {
+		if !used {
+			f.used[i] = true
+			return f.slots[i]
+		}
+	}
+	f.slots = append(f.slots, f.g.AllocLocal(8))
+	f.used = append(f.used, true)
+	return f.slots[len(f.slots)-1]
+}
+
+func (f *frame) free(op Op) {
+	for i, slot := range f.slots {
+		if slot == op {
+			f.used[i] = false
+		}
+	}
+}
+
+// idle returns the first register in preference order that is not in use
+func idle(inUse ...Op) (Register, bool) {
+	for _, r := range idleRegisters {
+		used := false
+		for _, op := range inUse {
+			if op == r {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// number of limbs that can be reduced at once with mulx,
+// longer inputs are multiplied and reduced in quarters
+const quarter = 9
+
+func (g *gen) mul() {
+	g.function("mul", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, b *[%[1]d]uint64, p *[%[1]d]uint64, inp uint64)")
+	n := g.size
+	f := &frame{g: g}
+	w := make([]Op, 2*n)
+
+	g.title("inputs")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	g.XORQ(RAX, RAX)
+	if n > quarter {
+		g.splitProduct(f, w)
+	} else {
+		g.product(f, w, seq(0, n), seq(0, n), true)
+		g.title("\t\t\t")
+		g.table("W", w)
+	}
+	g.montgomery(f, w)
+}
+
+// montgomery applies reduction to the double sized product w
+// and writes the result to c
+func (g *gen) montgomery(f *frame, w []Op) {
+	n := g.size
+
+	// lower words are needed first in montgomery reduction,
+	// swap them with higher words
+	m := &mont{gen: g, f: f, w: w, modulus: RSI, hi: RBX}
+	if 2*n > quarter+1 {
+		g.swap(w, f, []Register{RBX, RSI}, 0, quarter+1, 2)
+		m.modulus, _ = idle(append(w, RAX, RDX)...)
+		m.hi, _ = idle(append(w, RAX, RDX, m.modulus)...)
+	}
+	g.Comment("| fetch modulus")
+	g.Load(g.Param("p"), m.modulus)
+	g.table("W ready to mont", w)
+
+	m.carry = w[0]
+	if n <= quarter {
+		g.title("montgomery reduction")
+		g.Comment("| clear flags")
+		g.XORQ(RAX, RAX)
+		for i := 0; i < n; i++ {
+			m.row(i, seq(0, n), false, i < n-1)
+		}
+		g.table("W montgomery reduction ends", w)
+	} else {
+		g.title("montgomery reduction q1")
+		g.Comment("| clear flags")
+		g.XORQ(RAX, RAX)
+		for i := 0; i < quarter; i++ {
+			m.row(i, seq(0, quarter), true, i < quarter-1)
+		}
+		g.table("W montgomery reduction q1 ends", w)
+		if n == quarter+1 {
+			m.reduceSingleColumn()
+		} else {
+			m.reduceQuarters()
+		}
+	}
+
+	g.title("modular reduction")
+	long := m.carry.(Register)
+	diff := make([]Op, n)
+	inUse := append(w, RDX, m.modulus, long)
+	for k := 0; k < n; k++ {
+		if r, ok := idle(inUse...); ok {
+			diff[k] = r
+			inUse = append(inUse, r)
+		} else {
+			diff[k] = f.alloc()
+		}
+		r := diff[k]
+		if isStack(r) {
+			r = RDX
+		}
+		g.MOVQ(w[n+k], r)
+		if k == 0 {
+			g.SUBQ(at(m.modulus, k), r)
+		} else {
+			g.SBBQ(at(m.modulus, k), r)
+		}
+		if isStack(diff[k]) {
+			g.MOVQ(r, diff[k])
+		}
+	}
+	g.SBBQ(U8(0), long)
+
+	g.title("out")
+	g.Load(g.Param("c"), long)
+	for k := 0; k < n; k++ {
+		r := w[n+k]
+		if isStack(r) {
+			g.MOVQ(r, RDX)
+			r = RDX
+		}
+		g.CMOVQCC(diff[k], r)
+		g.MOVQ(r, at(long, k))
+	}
+	g.RET()
+	g.end()
+}
+
+// product accumulates products of limbs of a at rows xs and limbs of b at
+// columns ys into w, the top word is kept in DI when a is not needed anymore
+func (g *gen) product(f *frame, w []Op, xs, ys []int, last bool) {
+	rows, cols := len(xs), len(ys)
+	spill := len(w) - len(productRegisters)
+	if last {
+		spill--
+	}
+	next := 0
+	round := func() Register {
+		r := productRegisters[next%len(productRegisters)]
+		next++
+		return r
+	}
+	for i := 0; i < rows; i++ {
+		g.title(fmt.Sprintf("i = %d", xs[i]))
+		g.Comment(fmt.Sprintf("| a%d @ DX", xs[i]))
+		g.MOVQ(at(RDI, xs[i]), RDX)
+		if i == 0 {
+			for j := 0; j < cols; j++ {
+				g.Comment(fmt.Sprintf("| a%d * b%d ", xs[i], ys[j]))
+				if j == 0 {
+					if spill > 0 {
+						w[1] = round()
+						w[0] = f.alloc()
+						g.MULXQ(at(RSI, ys[0]), RAX, w[1])
+						g.MOVQ(RAX, w[0])
+					} else {
+						w[0], w[1] = round(), round()
+						g.MULXQ(at(RSI, ys[0]), w[0], w[1])
+					}
+					continue
+				}
+				w[j+1] = round()
+				g.MULXQ(at(RSI, ys[j]), RAX, w[j+1])
+				g.ADCXQ(RAX, w[j])
+			}
+			if cols > 1 {
+				g.ADCQ(U8(0), w[cols])
+			}
+			continue
+		}
+		busy := false
+		if i == rows-1 && last {
+			w[i+cols] = RDI
+		} else {
+			w[i+cols] = round()
+			for _, op := range w[:i+cols] {
+				busy = busy || op == w[i+cols]
+			}
+		}
+		if busy {
+			// register is freed after spilling w_i
+			g.XORQ(RAX, RAX)
+		} else {
+			g.XORQ(w[i+cols], w[i+cols])
+		}
+		for j := 0; j < cols; j++ {
+			g.Comment(fmt.Sprintf("| a%d * b%d ", xs[i], ys[j]))
+			g.MULXQ(at(RSI, ys[j]), RAX, RBX)
+			g.ADOXQ(RAX, w[i+j])
+			switch {
+			case j < cols-1:
+				g.ADCXQ(RBX, w[i+j+1])
+			case i < rows-1:
+				g.ADOXQ(w[i+cols], w[i+cols])
+				g.ADCXQ(RBX, w[i+cols])
+			default:
+				g.ADOXQ(RBX, w[i+cols])
+				g.ADCQ(U8(0), w[i+cols])
+			}
+			if j == 0 && i < spill {
+				slot := f.alloc()
+				g.MOVQ(w[i], slot)
+				if busy {
+					g.zero(w[i+cols])
+				}
+				w[i] = slot
+			}
+		}
+	}
+}
+
+// splitProduct multiplies the first quarter of columns of b and the rest
+// separately, since there are not enough registers to keep all words
+func (g *gen) splitProduct(f *frame, w []Op) {
+	n := g.size
+	g.product(f, w[:n+quarter], seq(0, n), seq(0, quarter), false)
+	g.title("\t\t\t")
+	g.table("W right", w)
+	for k := range w {
+		if w[k] != nil && !isStack(w[k]) {
+			slot := f.alloc()
+			g.MOVQ(w[k], slot)
+			w[k] = slot
+		}
+	}
+	g.table("W right at stack", w)
+	g.XORQ(RAX, RAX)
+
+	left := make([]Op, 2*n)
+	g.product(f, left[quarter:], seq(0, n), seq(quarter, n), true)
+	g.title("\t\t\t")
+	g.table("W left", left)
+	g.table("W right", w)
+	g.combine(f, w, left, quarter)
+	g.table("W combined", w)
+}
+
+// mont holds state of montgomery reduction with mulx
+type mont struct {
+	*gen
+	f *frame
+	w []Op
+	// hi is the register for higher part of products, carry is the long
+	// carry between rows
+	modulus, hi Register
+	carry       Op
+	// multipliers saved in first and third quarters
+	us []Mem
+}
+
+// row reduces the word i, multiplier is calculated in place
+func (m *mont) row(i int, cols []int, save, clear bool) {
+	w, hi := m.w, m.hi
+	m.title(fmt.Sprintf("i = %d", i))
+	m.table("W", w)
+	m.Comment(fmt.Sprintf("| | u%d = w%d * inp", i, i))
+	m.MOVQ(w[i], RDX)
+	m.MULXQ(m.inp(), RDX, hi)
+	if save {
+		m.Comment(fmt.Sprintf("| save u%d", i))
+		slot := m.f.alloc()
+		m.MOVQ(RDX, slot)
+		m.us = append(m.us, slot)
+	}
+	m.title("")
+	for k, j := range cols {
+		m.Comment(fmt.Sprintf("| j%d\n", j))
+		m.Comment(fmt.Sprintf("| w%d @ %s", i+j, w[i+j].Asm()))
+		m.MULXQ(at(m.modulus, j), RAX, hi)
+		m.ADOXQ(RAX, w[i+j])
+		if k < len(cols)-1 {
+			m.ADCXQ(hi, w[i+j+1])
+		}
+	}
+	t := i + len(cols)
+	if isStack(w[t]) {
+		m.Comment(fmt.Sprintf("| w%d @ %s", t, w[t].Asm()))
+		inUse := append(w, RAX, RDX, m.modulus, hi, m.carry)
+		if r, ok := idle(inUse...); ok {
+			m.Comment("| move to an idle register")
+			m.MOVQ(w[t], r)
+			m.ADCXQ(hi, r)
+			m.ADOXQ(m.carry, r)
+			m.f.free(w[t])
+			w[t] = r
+		} else {
+			m.Comment("| move to temp register")
+			m.MOVQ(w[t], RAX)
+			m.ADCXQ(hi, RAX)
+			m.ADOXQ(m.carry, RAX)
+			m.Comment("| move to an idle register", fmt.Sprintf("| w%d @ AX", t))
+			m.MOVQ(RAX, m.carry)
+			m.f.free(w[t])
+			w[t] = m.carry
+		}
+	} else {
+		m.ADCXQ(hi, w[t])
+		m.ADOXQ(m.carry, w[t])
+	}
+	m.ADCXQ(w[i], w[i])
+	m.zero(RAX)
+	m.ADOXQ(RAX, w[i])
+	m.carry, w[i] = w[i], nil
+	if clear {
+		m.Comment("| clear flags")
+		m.XORQ(RAX, RAX)
+	}
+}
+
+// reduceSingleColumn finishes reduction when the only column left after
+// the first quarter is the last limb of the modulus
+func (m *mont) reduceSingleColumn() {
+	n, w, hi := m.size, m.w, m.hi
+	long := m.carry.(Register)
+	m.Comment(fmt.Sprintf("| long carry %s should be added to w%d", long.Asm(), 2*quarter))
+	m.title("montgomerry reduction q2")
+	m.MOVQ(at(m.modulus, quarter), RDX)
+	m.XORQ(RAX, RAX)
+	for i := 0; i < quarter; i++ {
+		m.title(fmt.Sprintf("i = %d", i))
+		m.Comment(fmt.Sprintf("| w%d @ %s", quarter+i, w[quarter+i].Asm()))
+		m.MULXQ(m.us[i], RAX, hi)
+		m.ADOXQ(RAX, w[quarter+i])
+		if i < quarter-1 {
+			m.ADCXQ(hi, w[quarter+i+1])
+			continue
+		}
+		m.Comment(
+			"| aggregate carries",
+			fmt.Sprintf("| %s + %s should be added to w%d @ %s", long.Asm(), hi.Asm(), 2*quarter, w[2*quarter].Asm()),
+			"| notice that aggregated value can be at most (2^64 - 1)",
+		)
+		m.ADCXQ(hi, long)
+		m.zero(RAX)
+		m.ADOXQ(RAX, long)
+	}
+	m.table("q2 ends", w)
+
+	m.title("montgomerry reduction q3 & q4")
+	m.title(fmt.Sprintf("i = %d", quarter))
+	u := w[quarter].(Register)
+	m.Comment(fmt.Sprintf("| | u%d = w%d * inp", quarter, quarter))
+	m.XORQ(RAX, RAX)
+	m.MOVQ(u, RDX)
+	m.MULXQ(m.inp(), RDX, hi)
+	// w18 is accumulated in the long carry register and
+	// w19 takes the register of w9 which is not needed anymore
+	dst := func(k int) Op {
+		switch k {
+		case 2 * quarter:
+			return long
+		case 2*quarter + 1:
+			return u
+		}
+		return w[k]
+	}
+	for j := 0; j < n; j++ {
+		k := quarter + j
+		m.Comment(fmt.Sprintf("| j%d\n", j))
+		m.Comment(fmt.Sprintf("| w%d @ %s", k, w[k].Asm()))
+		m.MULXQ(at(m.modulus, j), RAX, hi)
+		m.ADOXQ(RAX, dst(k))
+		m.ADCXQ(hi, dst(k+1))
+	}
+	m.Comment("| the last bit")
+	m.zero(hi)
+	m.ADOXQ(hi, u)
+	m.ADDQ(w[2*quarter], long)
+	m.ADCQ(w[2*quarter+1], u)
+	m.ADCQ(U8(0), hi)
+	m.f.free(w[2*quarter])
+	m.f.free(w[2*quarter+1])
+	w[quarter], w[2*quarter], w[2*quarter+1] = nil, long, u
+	m.table("q3 ends", w)
+	m.carry = hi
+}
+
+// reduceQuarters finishes reduction of longer inputs. The second quarter
+// adds multiples of higher limbs of the modulus with multipliers saved in
+// the first quarter, the third quarter reduces the rest of the words and
+// the fourth quarter adds higher limbs for multipliers of the third.
+func (m *mont) reduceQuarters() {
+	n, w, f, hi := m.size, m.w, m.f, m.hi
+	long := m.carry.(Register)
+	m.Comment(fmt.Sprintf("| carry from q1 should be added to w%d", 2*quarter))
+	saved := f.alloc()
+	m.MOVQ(long, saved)
+
+	m.title("montgomerry reduction q2")
+	m.Comment("| clear flags")
+	m.XORQ(long, long)
+	// higher words at stack are moved into registers of lower words
+	// those are spilled after their multipliers are consumed
+	s := 0
+	for i := 0; i < quarter; i++ {
+		if isStack(w[i+n]) {
+			s++
+		}
+	}
+	for i := 0; i < quarter; i++ {
+		m.title(fmt.Sprintf("i = %d", i))
+		m.table("W", w)
+		m.Comment(fmt.Sprintf("| u%d @\u00a0%s", i, m.us[i].Asm()))
+		m.MOVQ(m.us[i], RDX)
+		m.title("")
+		for j := quarter; j < n; j++ {
+			k := i + j
+			m.Comment(fmt.Sprintf("| j%d\n", j))
+			m.Comment(fmt.Sprintf("| w%d @ %s", k, w[k].Asm()))
+			m.MULXQ(at(m.modulus, j), RAX, hi)
+			m.ADOXQ(RAX, w[k])
+			if j == quarter && i > 0 && i <= s {
+				f.free(m.us[i-1])
+				slot := f.alloc()
+				m.MOVQ(w[k], slot)
+				w[k] = slot
+			}
+			if j < n-1 {
+				m.ADCXQ(hi, w[k+1])
+			}
+		}
+		t := i + n
+		if isStack(w[t]) {
+			m.moveIdle(t, append(w, RAX, RDX, m.modulus, hi, long))
+			m.Comment(fmt.Sprintf("| w%d @ %s", t, w[t].Asm()))
+		}
+		m.ADCXQ(hi, w[t])
+		m.ADOXQ(long, w[t])
+		if t+1 == 2*quarter {
+			m.Comment("| bring the carry from q1")
+			m.MOVQ(saved, long)
+			m.zero(RAX)
+			m.ADCXQ(RAX, long)
+			m.ADOXQ(RAX, long)
+		} else {
+			m.zero(long)
+			m.ADCXQ(long, long)
+			m.zero(RAX)
+			m.ADOXQ(RAX, long)
+		}
+		if i < quarter-1 {
+			m.Comment("| clear flags")
+			m.XORQ(RAX, RAX)
+		}
+	}
+	m.table("q2 ends", w)
+	m.Comment("| save the carry from q2", fmt.Sprintf("| should be added to w%d", n+quarter))
+	m.MOVQ(long, saved)
+
+	m.title("q2 q3 transition swap")
+	m.swap(w, f, []Register{long}, quarter+1, quarter+1+s, 0)
+	m.table("W q2 q3 transition", w)
+	for _, u := range m.us[s:] {
+		f.free(u)
+	}
+	m.us = nil
+
+	m.title("montgomery reduction q3")
+	m.Comment("| clear flags")
+	m.XORQ(RAX, RAX)
+	m.carry = w[quarter]
+	for i := quarter; i < n; i++ {
+		m.row(i, seq(0, quarter), true, i < n-1)
+	}
+	m.table("W q3", w)
+	long = m.carry.(Register)
+	m.Comment("| aggregate carries from q2 & q3", fmt.Sprintf("| should be added to w%d", n+quarter))
+	m.ADCQ(saved, long)
+	f.free(saved)
+
+	m.title("montgomerry reduction q4")
+	m.Comment("| clear flags")
+	m.XORQ(RAX, RAX)
+	for r := 0; r < n-quarter; r++ {
+		m.title(fmt.Sprintf("i = %d", r))
+		m.table("W", w)
+		m.Comment(fmt.Sprintf("| u%d @\u00a0%s", r, m.us[r].Asm()))
+		m.MOVQ(m.us[r], RDX)
+		m.title("")
+		for j := quarter; j < n; j++ {
+			k := r + j + quarter
+			m.Comment(fmt.Sprintf("| j%d\n", j))
+			m.Comment(fmt.Sprintf("| w%d @ %s", k, w[k].Asm()))
+			m.MULXQ(at(m.modulus, j), RAX, hi)
+			m.ADOXQ(RAX, w[k])
+			if j < n-1 {
+				m.ADCXQ(hi, w[k+1])
+			}
+			if j == quarter && r < n-quarter-1 {
+				slot := f.alloc()
+				m.MOVQ(w[k], slot)
+				w[k] = slot
+			}
+		}
+		t := r + n + quarter
+		m.moveIdle(t, append(w, RAX, RDX, m.modulus, hi, long))
+		m.ADCXQ(hi, w[t])
+		if r == 0 {
+			m.Comment("| bring carry from q2 & q3")
+		}
+		m.Comment(fmt.Sprintf("| w%d @ %s", t, w[t].Asm()))
+		m.ADOXQ(long, w[t])
+		m.zero(long)
+		m.ADCXQ(long, long)
+		m.zero(hi)
+		m.ADOXQ(hi, long)
+	}
+	m.table("W q4", w)
+	for _, u := range m.us {
+		f.free(u)
+	}
+}
+
+// moveIdle brings the word t at stack into a register that is not in use
+func (m *mont) moveIdle(t int, inUse []Op) {
+	if !isStack(m.w[t]) {
+		return
+	}
+	r, ok := idle(inUse...)
+	if !ok {
+		r = RAX
+	}
+	m.Comment(fmt.Sprintf("| w%d @ %s", t, m.w[t].Asm()), "| move to an idle register")
+	m.MOVQ(m.w[t], r)
+	m.f.free(m.w[t])
+	m.w[t] = r
+}
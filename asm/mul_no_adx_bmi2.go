@@ -0,0 +1,620 @@
+package main
+
+import (
+	"fmt"
+
+	. "github.com/mmcloughlin/avo/operand"
+	. "github.com/mmcloughlin/avo/reg"
+)
+
+// registers that are used for partial products without mulx
+var productRegistersNoADX = []Register{R8, R9, R10, R11, R12, R13, R14, R15}
+
+// preference order of registers that are left after products are loaded
+var montRegistersNoADX = []Register{RBX, R8, R9, R10, R11, R12, R13, R14, R15, RDI, RSI}
+
+// number of limbs that can be reduced at once without mulx,
+// longer inputs are multiplied and reduced in quarters
+const quarterNoADX = 8
+
+func pick(order []Register, inUse ...Op) (Register, bool) {
+	for _, r := range order {
+		used := false
+		for _, op := range inUse {
+			if op == r {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+func seq(from, to int) []int {
+	s := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func (g *gen) mulNoADXBMI2() {
+	g.function("mul_no_adx_bmi2_", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, b *[%[1]d]uint64, p *[%[1]d]uint64, inp uint64)")
+	n := g.size
+	f := &frame{g: g}
+	w := make([]Op, 2*n)
+
+	g.title("inputs")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("b"), RSI)
+	if n > quarterNoADX {
+		g.splitProductNoADX(f, w)
+	} else {
+		g.productNoADX(f, w, RDI, RSI, seq(0, n), seq(0, n))
+		g.title("\t\t\t")
+		g.table("W", w)
+	}
+	g.montgomeryNoADX(f, w)
+}
+
+// montgomeryNoADX applies reduction to the double sized product w
+// and writes the result to c
+func (g *gen) montgomeryNoADX(f *frame, w []Op) {
+	n := g.size
+	m := &montNoADX{gen: g, f: f, w: w, modulus: RSI, u: RDI, carry: RCX}
+	if 2*n > 9 {
+		g.swap(w, f, []Register{RCX, RDI, RSI}, 0, 9, 3)
+		m.carry, _ = pick(montRegistersNoADX, w...)
+		m.u, _ = pick(montRegistersNoADX, append(w, m.carry)...)
+		m.modulus, _ = pick(montRegistersNoADX, append(w, m.carry, m.u)...)
+	}
+	g.Comment("| fetch modulus")
+	g.Load(g.Param("p"), m.modulus)
+
+	switch {
+	case n < quarterNoADX:
+		g.title("montgomery reduction")
+		for i := 0; i < n; i++ {
+			m.row(i, seq(0, n), i == 0, false)
+		}
+		g.table("W montgomerry reduction ends", w)
+	case n == quarterNoADX:
+		g.title("montgomery reduction q1")
+		for i := 0; i < n; i++ {
+			m.row(i, seq(0, n), i == 0, true)
+		}
+		g.table("W montgomerry reduction ends", w)
+	case n == quarterNoADX+1:
+		m.reduceSingleColumn()
+	default:
+		m.reduceQuarters()
+	}
+
+	g.title("modular reduction")
+	long := m.long
+	diff := make([]Op, n)
+	inUse := append(w, m.modulus, m.u, m.carry, long)
+	for k := 0; k < n; k++ {
+		if r, ok := pick(productRegistersNoADX, inUse...); ok {
+			diff[k] = r
+			inUse = append(inUse, r)
+		} else {
+			diff[k] = f.alloc()
+		}
+		r := diff[k]
+		if isStack(r) {
+			r = RDX
+		}
+		g.MOVQ(w[n+k], r)
+		if k == 0 {
+			g.SUBQ(at(m.modulus, k), r)
+		} else {
+			g.SBBQ(at(m.modulus, k), r)
+		}
+		if isStack(diff[k]) {
+			g.MOVQ(r, diff[k])
+		}
+	}
+	g.SBBQ(U8(0), long)
+
+	g.title("out")
+	g.Load(g.Param("c"), long)
+	for k := 0; k < n; k++ {
+		r := w[n+k]
+		if isStack(r) {
+			g.MOVQ(r, RDX)
+			r = RDX
+		}
+		g.CMOVQCC(diff[k], r)
+		g.MOVQ(r, at(long, k))
+	}
+	g.RET()
+	g.end()
+}
+
+// productNoADX accumulates products of limbs of x at rows xs and limbs of y
+// at columns ys into w, lower words are spilled to stack as they are ready
+func (g *gen) productNoADX(f *frame, w []Op, x, y Register, xs, ys []int) {
+	rows, cols := len(xs), len(ys)
+	spill := 0
+	if len(w) > 9 {
+		spill = len(w) - 9
+	}
+	start := 0
+	if spill > 0 {
+		start = 1
+	}
+	top := len(w) - 1
+	for k := start; k < top; k++ {
+		w[k] = productRegistersNoADX[(k-start)%len(productRegistersNoADX)]
+	}
+	w[top] = RBX
+	for k := 2; k < top; k++ {
+		if k-start < len(productRegistersNoADX) {
+			g.zero(w[k])
+		}
+	}
+	if cols == 2 {
+		g.zero(RBX)
+	}
+	xName, yName := "a", "b"
+	if x != RDI {
+		xName, yName = yName, xName
+	}
+	for i := 0; i < rows; i++ {
+		g.title(fmt.Sprintf("i = %d", xs[i]))
+		g.Comment(fmt.Sprintf("| %s%d @ CX", xName, xs[i]))
+		g.MOVQ(at(x, xs[i]), RCX)
+		if (i > 0 || rows == 1) && cols > 2 {
+			g.zero(RBX)
+		}
+		for j := 0; j < cols; j++ {
+			if x == RDI {
+				g.Comment(fmt.Sprintf("| %s%d * %s%d ", xName, xs[i], yName, ys[j]))
+			} else {
+				g.Comment(fmt.Sprintf("| %s%d * %s%d ", yName, ys[j], xName, xs[i]))
+			}
+			g.MOVQ(at(y, ys[j]), RAX)
+			g.MULQ(RCX)
+			if i == 0 {
+				if j == 0 {
+					if spill > 0 {
+						w[0] = f.alloc()
+					}
+					g.MOVQ(RAX, w[0])
+					g.MOVQ(RDX, w[1])
+					continue
+				}
+				g.ADDQ(RAX, w[j])
+				g.ADCQ(RDX, w[j+1])
+				continue
+			}
+			g.ADDQ(RAX, w[i+j])
+			g.ADCQ(RDX, w[i+j+1])
+			switch {
+			case j == cols-1:
+			case cols == 2:
+				g.ADCQ(U8(0), w[i+2])
+			case j == 0:
+				g.ADCQ(U8(0), w[i+2])
+				g.ADCQ(U8(0), RBX)
+			case j == cols-2 && i == rows-1:
+				g.ADCQ(U8(0), RBX)
+			case j == cols-2:
+				g.ADCQ(RBX, w[i+cols])
+			default:
+				g.ADCQ(RBX, w[i+j+2])
+				g.zero(RBX)
+				g.ADCQ(U8(0), RBX)
+			}
+			if j == 0 && i < spill {
+				slot := f.alloc()
+				g.MOVQ(w[i], slot)
+				g.zero(w[i])
+				w[i] = slot
+			}
+		}
+	}
+}
+
+// splitProductNoADX multiplies the first quarter of columns of b and the
+// rest separately, since there are not enough registers to keep all words
+func (g *gen) splitProductNoADX(f *frame, w []Op) {
+	n, q := g.size, quarterNoADX
+	g.productNoADX(f, w[:n+q], RDI, RSI, seq(0, n), seq(0, q))
+	g.title("\t\t\t")
+	g.table("W part 1 multiplication", w)
+	for k := range w {
+		if w[k] != nil && !isStack(w[k]) {
+			slot := f.alloc()
+			g.MOVQ(w[k], slot)
+			w[k] = slot
+		}
+	}
+	g.table("W part 1 moved to stack", w)
+
+	hi := make([]Op, 2*n)
+	if n-q == 1 {
+		g.productNoADX(f, hi[q:], RSI, RDI, []int{q}, seq(0, n))
+	} else {
+		g.productNoADX(f, hi[q:], RDI, RSI, seq(0, n), seq(q, n))
+	}
+	g.title("\t\t\t")
+	g.table("W part 2 multiplication", hi)
+	g.table("W part 1", w)
+	g.combine(f, w, hi, q)
+	g.table("W combined", w)
+}
+
+// swap brings words in [lo, hi) from stack into registers, highest words
+// that are in registers are moved to stack in exchange until there are
+// spare registers left beside loaded words
+func (g *gen) swap(w []Op, f *frame, free []Register, lo, hi, spare int) {
+	top := len(w) - 1
+	evict := func() Register {
+		for isStack(w[top]) {
+			top--
+		}
+		r := w[top].(Register)
+		slot := f.alloc()
+		g.MOVQ(r, slot)
+		w[top] = slot
+		top--
+		return r
+	}
+	for k := lo; k < hi; k++ {
+		if !isStack(w[k]) {
+			continue
+		}
+		var r Register
+		if len(free) > 0 {
+			r, free = free[0], free[1:]
+		} else {
+			r = evict()
+		}
+		g.MOVQ(w[k], r)
+		f.free(w[k])
+		w[k] = r
+	}
+	for left := len(free); left < spare; left++ {
+		evict()
+	}
+}
+
+// combine adds words of hi to w from the word k on
+func (g *gen) combine(f *frame, w, hi []Op, k int) {
+	for first := k; k < len(w); k++ {
+		lo := w[k]
+		w[k] = hi[k]
+		if lo == nil {
+			g.ADCQ(U8(0), hi[k])
+			continue
+		}
+		f.free(lo)
+		if isStack(hi[k]) {
+			g.MOVQ(lo, RAX)
+			lo = RAX
+		}
+		if k == first {
+			g.ADDQ(lo, hi[k])
+		} else {
+			g.ADCQ(lo, hi[k])
+		}
+	}
+}
+
+// montNoADX holds state of montgomery reduction without mulx
+type montNoADX struct {
+	*gen
+	f *frame
+	w []Op
+	// u is the register of current multiplier, carry is the short carry
+	// in a row and long is the carry between rows
+	modulus, u, carry Register
+	long              Register
+	// multipliers saved in first and third quarters
+	us []Mem
+}
+
+// row reduces the word i, multiplier is calculated in place
+func (m *montNoADX) row(i int, cols []int, first, save bool) {
+	w := m.w
+	m.title(fmt.Sprintf("i = %d", i))
+	m.table("W", w)
+	m.Comment(fmt.Sprintf("| | u%d = w%d * inp", i, i))
+	m.MOVQ(w[i], RAX)
+	m.MULQ(m.inp())
+	m.MOVQ(RAX, m.u)
+	m.zero(m.carry)
+	m.title("")
+	if save {
+		m.Comment(fmt.Sprintf("| save u%d", i))
+		slot := m.f.alloc()
+		m.MOVQ(m.u, slot)
+		m.us = append(m.us, slot)
+	}
+	m.accumulate(i, cols, m.u, first, nil, nil, "")
+	if first {
+		m.long = w[i].(Register)
+	}
+	w[i] = nil
+	t := i + cols[len(cols)-1] + 1
+	if isStack(w[t]) {
+		m.Comment("| move to idle register")
+		r, _ := idle(append(w, RAX, RDX, m.modulus, m.u, m.carry, m.long)...)
+		m.MOVQ(w[t], r)
+		m.f.free(w[t])
+		w[t] = r
+	}
+	m.carryOut(t, first, !first, nil)
+}
+
+// accumulate adds u * p_j for j in cols to words starting from i,
+// spill is called after the first column and extra is added
+// to the very last word of the first row with a note
+func (m *montNoADX) accumulate(i int, cols []int, u Op, first bool, spill func(), extra Op, note string) {
+	w := m.w
+	for k, j := range cols {
+		m.Comment(fmt.Sprintf("| j%d\n", j))
+		m.Comment(fmt.Sprintf("| w%d @ %s", i+j, w[i+j].Asm()))
+		m.MOVQ(at(m.modulus, j), RAX)
+		m.MULQ(u)
+		m.ADDQ(RAX, w[i+j])
+		switch {
+		case k == 0:
+			m.ADCQ(RDX, m.carry)
+			if spill != nil {
+				spill()
+			}
+		case k < len(cols)-1:
+			m.ADCQ(U8(0), RDX)
+			m.ADDQ(m.carry, w[i+j])
+			m.zero(m.carry)
+			m.ADCQ(RDX, m.carry)
+		case first:
+			if extra != nil {
+				if note != "" {
+					m.Comment(note)
+				}
+				m.ADCQ(extra, RDX)
+			} else {
+				m.ADCQ(U8(0), RDX)
+			}
+			m.ADDQ(m.carry, w[i+j])
+		default:
+			m.ADCQ(RDX, m.long)
+			m.ADDQ(m.carry, w[i+j])
+		}
+	}
+}
+
+// carryOut adds the high part of the last product to the word t and
+// starts the next long carry, in is loaded as the long carry if given
+func (m *montNoADX) carryOut(t int, first, clear bool, in Op) {
+	w := m.w
+	label := t
+	if t == len(w)-1 {
+		label = -1
+	}
+	m.Comment(fmt.Sprintf("| w%d @ %s", label, w[t].Asm()))
+	if first {
+		m.ADCQ(RDX, w[t])
+	} else {
+		m.ADCQ(m.long, w[t])
+	}
+	switch {
+	case in != nil:
+		m.Comment("| bring the carry from q1")
+		m.MOVQ(in, m.long)
+	case clear:
+		m.zero(m.long)
+	}
+	m.ADCQ(U8(0), m.long)
+}
+
+// reduceSingleColumn reduces the product when there is only one limb
+// beyond the first quarter, that limb is handled column-wise
+func (m *montNoADX) reduceSingleColumn() {
+	w, n, q := m.w, m.size, quarterNoADX
+	m.title("montgomery reduction q1")
+	for i := 0; i < q; i++ {
+		m.row(i, seq(0, q), i == 0, true)
+	}
+	m.table("W q1", w)
+
+	m.title("montgomerry reduction q2")
+	carry := m.carry
+	m.zero(carry)
+	for i := 0; i < q; i++ {
+		m.title(fmt.Sprintf("i = %d", i))
+		m.Comment(fmt.Sprintf("| w%d @ %s", q+i, w[q+i].Asm()))
+		m.MOVQ(at(m.modulus, q), RAX)
+		m.MULQ(m.us[i])
+		m.ADDQ(RAX, w[q+i])
+		if i == 0 {
+			m.ADCQ(RDX, w[q+i+1])
+			m.ADCQ(U8(0), carry)
+			continue
+		}
+		m.f.free(m.us[i])
+		m.ADCQ(carry, RDX)
+		m.zero(carry)
+		m.ADDQ(RDX, w[q+i+1])
+		if q+i+1 == 2*q-1 {
+			m.Comment("| carry from q1")
+			m.ADCQ(m.long, carry)
+		} else {
+			m.ADCQ(U8(0), carry)
+		}
+	}
+	m.table("W q2", w)
+
+	// long carry of q1 is the short carry of the last row and
+	// carry of q2 goes to the last word
+	m.title("montgomery reduction q3")
+	m.title(fmt.Sprintf("i = %d", q))
+	m.Comment(fmt.Sprintf("| | u%d = w%d * inp", q, q))
+	m.MOVQ(w[q], RAX)
+	m.MULQ(m.inp())
+	m.MOVQ(RAX, m.u)
+	m.carry, m.long = m.long, carry
+	m.zero(m.carry)
+	for j := 0; j < n; j++ {
+		m.Comment(fmt.Sprintf("| j%d\n", j))
+		m.Comment(fmt.Sprintf("| w%d @ %s", q+j, w[q+j].Asm()))
+		if t := w[q+j]; isStack(t) {
+			m.Comment("| move to idle register")
+			r, _ := idle(append(w, RAX, RDX, m.modulus, m.u, m.carry, m.long)...)
+			m.MOVQ(t, r)
+			w[q+j] = r
+		}
+		m.MOVQ(at(m.modulus, j), RAX)
+		m.MULQ(m.u)
+		m.ADDQ(RAX, w[q+j])
+		switch {
+		case j == 0:
+			m.ADCQ(RDX, m.carry)
+			w[q] = nil
+		case j < n-1:
+			m.ADCQ(U8(0), RDX)
+			m.ADDQ(m.carry, w[q+j])
+			m.zero(m.carry)
+			m.ADCQ(RDX, m.carry)
+		default:
+			m.ADCQ(RDX, m.long)
+			m.ADDQ(m.carry, w[q+j])
+		}
+	}
+	m.Comment(fmt.Sprintf("| w%d @ %s", 2*n-1, w[2*n-1].Asm()))
+	m.ADCQ(w[2*n-1], m.long)
+	w[2*n-1] = m.long
+	m.Comment("| care the last bit")
+	m.carry, m.long = m.long, m.carry
+	m.zero(m.long)
+	m.ADCQ(U8(0), m.long)
+	m.table("W q3", w)
+}
+
+// reduceQuarters reduces the product in four quarters, first and third
+// quarters are lower limbs of the modulus against lower and higher words,
+// second and fourth quarters are higher limbs of the modulus against
+// saved multipliers
+func (m *montNoADX) reduceQuarters() {
+	w, n, q := m.w, m.size, quarterNoADX
+	high := seq(q, n)
+	m.title("montgomery reduction q1")
+	for i := 0; i < q; i++ {
+		m.row(i, seq(0, q), i == 0, true)
+	}
+	m.table("W q1", w)
+	m.Comment("| save the carry from q1", fmt.Sprintf("| should be added to w%d", 2*q))
+	saved := m.f.alloc()
+	m.MOVQ(m.long, saved)
+
+	m.title("montgomerry reduction q2")
+	us := m.us
+	// multiplier register is free in this quarter and taken first
+	free := m.u
+	for i := 0; i < q; i++ {
+		m.title(fmt.Sprintf("i = %d", i))
+		m.table("W", w)
+		m.zero(m.carry)
+		m.title("")
+		var spill func()
+		if i > 0 && i < len(high)-1 {
+			// previous multiplier is not needed anymore
+			spill = func() {
+				m.MOVQ(w[i+q], us[i-1])
+				w[i+q] = us[i-1]
+			}
+		}
+		var extra Op
+		if i+n == 2*q {
+			extra = saved
+		}
+		m.accumulate(i, high, us[i], i == 0, spill, extra, "| bring the carry from q1")
+		t := i + n
+		if isStack(w[t]) {
+			if i < q-1 {
+				m.Comment("| move to an idle register")
+				r, _ := idle(append(w, RAX, RDX, m.modulus, m.carry, m.long)...)
+				if free != nil {
+					r, free = free, nil
+				}
+				m.MOVQ(w[t], r)
+				w[t] = r
+			} else {
+				m.Comment("| tolarete this limb to stay in stack")
+			}
+		}
+		var in Op
+		if t+1 == 2*q {
+			in = saved
+		}
+		m.carryOut(t, i == 0, true, in)
+	}
+	m.table("q2", w)
+	m.Comment("| save the carry from q2", fmt.Sprintf("| should be added to w%d", n+q))
+	m.f.free(saved)
+	saved = m.f.alloc()
+	m.MOVQ(m.long, saved)
+	if len(high) > 2 {
+		m.title("q2 q3 transition swap")
+		m.swap(w, m.f, []Register{m.long}, q+1, n, 1)
+	}
+	m.table("W q2 q3 transition", w)
+	for _, slot := range us[len(high)-2:] {
+		m.f.free(slot)
+	}
+
+	m.title("montgomery reduction q3")
+	m.us = nil
+	m.u, _ = idle(append(w, RAX, RDX, m.modulus, m.carry)...)
+	for i := q; i < n; i++ {
+		m.row(i, seq(0, q), i == q, true)
+	}
+	m.table("W q3", w)
+	m.Comment("| aggregate carries from q2 & q3", fmt.Sprintf("| should be added to w%d", n+q))
+	m.ADCQ(m.long, saved)
+
+	m.title("montgomerry reduction q4")
+	for i := 0; i < len(high); i++ {
+		m.title(fmt.Sprintf("i = %d", i))
+		m.table("W", w)
+		m.zero(m.carry)
+		m.title("")
+		var spill func()
+		if i > 0 && i < len(high)-1 {
+			spill = func() {
+				slot := m.f.alloc()
+				m.MOVQ(w[i+2*q], slot)
+				w[i+2*q] = slot
+			}
+		}
+		var extra Op
+		if i == 0 {
+			extra = saved
+		}
+		m.accumulate(q+i, high, m.us[i], i == 0, spill, extra, "")
+		t := q + i + n
+		switch {
+		case i == len(high)-1:
+			m.Comment("| very last limb goes to short carry register")
+			m.MOVQ(w[t], m.carry)
+			m.f.free(w[t])
+			w[t] = m.carry
+		case isStack(w[t]):
+			r, _ := idle(append(w, RAX, RDX, m.modulus, m.carry, m.long)...)
+			m.MOVQ(w[t], r)
+			if i > 0 {
+				m.f.free(w[t])
+			}
+			w[t] = r
+		}
+		m.carryOut(t, i == 0, true, nil)
+	}
+	m.table("W q4", w)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+
+	. "github.com/mmcloughlin/avo/operand"
+	. "github.com/mmcloughlin/avo/reg"
+)
+
+// triangle places words of a square, products of distinct limbs are
+// calculated once and doubled, then squares of limbs are added
+type triangle struct {
+	*gen
+	f    *frame
+	w    []Op
+	pool []Register
+}
+
+// word places the new word k into a free register, the lowest word that
+// is in a register is moved to stack if there is none left
+func (t *triangle) word(k int) Register {
+	if r, ok := pick(t.pool, t.w...); ok {
+		t.w[k] = r
+		return r
+	}
+	low := 0
+	for t.w[low] == nil || isStack(t.w[low]) {
+		low++
+	}
+	r := t.w[low].(Register)
+	slot := t.f.alloc()
+	t.MOVQ(r, slot)
+	t.w[low], t.w[k] = slot, r
+	return r
+}
+
+// place puts the new word k into a free register or to stack
+func (t *triangle) place(k int) Op {
+	if r, ok := pick(t.pool, t.w...); ok {
+		t.w[k] = r
+	} else {
+		t.w[k] = t.f.alloc()
+	}
+	return t.w[k]
+}
+
+// into applies op to the word k, mulx arithmetic can not address
+// memory so words at stack pass through tmp
+func (t *triangle) into(op func(Op, Op), src Op, k int, tmp Register) {
+	if !isStack(t.w[k]) {
+		op(src, t.w[k])
+		return
+	}
+	t.MOVQ(t.w[k], tmp)
+	op(src, tmp)
+	t.MOVQ(tmp, t.w[k])
+}
+
+func (g *gen) square() {
+	g.function("square", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, p *[%[1]d]uint64, inp uint64)")
+	if g.size == 1 {
+		g.squareSingle()
+		return
+	}
+	n := g.size
+	t := &triangle{gen: g, f: &frame{g: g}, w: make([]Op, 2*n), pool: productRegisters}
+	w := t.w
+
+	g.title("inputs")
+	g.Load(g.Param("a"), RDI)
+	g.XORQ(RAX, RAX)
+
+	// products of distinct limbs, a is kept at DI until the last square
+	for i := 0; i < n-1; i++ {
+		g.title(fmt.Sprintf("i = %d", i))
+		g.Comment(fmt.Sprintf("| a%d @ DX", i))
+		g.MOVQ(at(RDI, i), RDX)
+		if i == 0 {
+			for j := 1; j < n; j++ {
+				g.Comment(fmt.Sprintf("| a0 * a%d ", j))
+				if j == 1 {
+					lo := t.word(1)
+					g.MULXQ(at(RDI, 1), lo, t.word(2))
+					continue
+				}
+				g.MULXQ(at(RDI, j), RAX, t.word(j+1))
+				t.into(g.ADCXQ, RAX, j, RSI)
+			}
+			if n > 2 {
+				g.ADCQ(U8(0), w[n])
+			}
+			continue
+		}
+		top := t.word(i + n)
+		g.XORQ(top, top)
+		for j := i + 1; j < n; j++ {
+			g.Comment(fmt.Sprintf("| a%d * a%d ", i, j))
+			g.MULXQ(at(RDI, j), RAX, RBX)
+			t.into(g.ADOXQ, RAX, i+j, RSI)
+			if j < n-1 {
+				t.into(g.ADCXQ, RBX, i+j+1, RSI)
+			} else {
+				g.ADOXQ(top, top)
+				g.ADCXQ(RBX, top)
+			}
+		}
+	}
+	g.title("\t\t\t")
+	g.table("W off diagonal", w)
+
+	// doubling goes in carry chain and squares in overflow chain
+	g.title("doubling and squares")
+	g.XORQ(RAX, RAX)
+	for i := 0; i < n; i++ {
+		g.Comment(fmt.Sprintf("| a%d * a%d ", i, i))
+		g.MOVQ(at(RDI, i), RDX)
+		switch i {
+		case 0:
+			if lo := t.place(0); isStack(lo) {
+				g.MULXQ(RDX, RAX, RBX)
+				g.MOVQ(RAX, lo)
+			} else {
+				g.MULXQ(RDX, lo, RBX)
+			}
+		case n - 1:
+			g.MULXQ(RDX, RAX, RDI)
+			w[2*n-1] = RDI
+		default:
+			g.MULXQ(RDX, RAX, RBX)
+		}
+		if i > 0 {
+			t.double(2*i, RAX)
+		}
+		if i < n-1 {
+			t.double(2*i+1, RBX)
+			continue
+		}
+		g.zero(RAX)
+		g.ADCXQ(RAX, RDI)
+		g.ADOXQ(RAX, RDI)
+	}
+	g.title("\t\t\t")
+	g.table("W", w)
+	g.montgomery(t.f, w)
+}
+
+// double doubles the word k in carry chain and adds a part of
+// a square in overflow chain
+func (t *triangle) double(k int, sq Register) {
+	r := t.w[k]
+	if isStack(r) {
+		t.MOVQ(r, RSI)
+		r = RSI
+	}
+	t.ADCXQ(r, r)
+	t.ADOXQ(sq, r)
+	if isStack(t.w[k]) {
+		t.MOVQ(r, t.w[k])
+	}
+}
+
+// squareSingle is the single limb square, reduction is a single step
+func (g *gen) squareSingle() {
+	g.title("inputs")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("p"), RSI)
+
+	g.title("square")
+	g.MOVQ(at(RDI, 0), RDX)
+	g.MULXQ(RDX, R8, R9)
+
+	g.title("montgomery reduction")
+	g.MOVQ(R8, RDX)
+	g.MULXQ(g.inp(), RDX, RDI)
+	g.MULXQ(at(RSI, 0), RAX, RDI)
+	g.ADDQ(RAX, R8)
+	g.ADCQ(RDI, R9)
+	g.ADCQ(U8(0), R8)
+
+	g.title("modular reduction")
+	g.MOVQ(R9, RAX)
+	g.SUBQ(at(RSI, 0), RAX)
+	g.SBBQ(U8(0), R8)
+
+	g.title("out")
+	g.Load(g.Param("c"), RDI)
+	g.CMOVQCC(RAX, R9)
+	g.MOVQ(R9, at(RDI, 0))
+	g.RET()
+	g.end()
+}
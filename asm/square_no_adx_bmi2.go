@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+
+	. "github.com/mmcloughlin/avo/operand"
+	. "github.com/mmcloughlin/avo/reg"
+)
+
+// registers that are used for words of a square without mulx
+var squareRegistersNoADX = []Register{R8, R9, R10, R11, R12, R13, R14, R15, RBX}
+
+func (g *gen) squareNoADXBMI2() {
+	g.function("square_no_adx_bmi2_", "func(c *[%[1]d]uint64, a *[%[1]d]uint64, p *[%[1]d]uint64, inp uint64)")
+	if g.size == 1 {
+		g.squareSingleNoADX()
+		return
+	}
+	n := g.size
+	t := &triangle{gen: g, f: &frame{g: g}, w: make([]Op, 2*n), pool: squareRegistersNoADX}
+	w := t.w
+
+	g.title("inputs")
+	g.Load(g.Param("a"), RDI)
+
+	// products of distinct limbs, carry of a row is kept at SI
+	for i := 0; i < n-1; i++ {
+		g.title(fmt.Sprintf("i = %d", i))
+		g.Comment(fmt.Sprintf("| a%d @ CX", i))
+		g.MOVQ(at(RDI, i), RCX)
+		for j := i + 1; j < n; j++ {
+			g.Comment(fmt.Sprintf("| a%d * a%d ", i, j))
+			g.MOVQ(at(RDI, j), RAX)
+			g.MULQ(RCX)
+			if j > i+1 {
+				g.ADDQ(RSI, RAX)
+				g.ADCQ(U8(0), RDX)
+			}
+			if i == 0 {
+				g.MOVQ(RAX, t.word(j))
+			} else {
+				g.ADDQ(RAX, w[i+j])
+				g.ADCQ(U8(0), RDX)
+			}
+			if j < n-1 {
+				g.MOVQ(RDX, RSI)
+			} else {
+				g.MOVQ(RDX, t.word(i+n))
+			}
+		}
+	}
+	g.title("\t\t\t")
+	g.table("W off diagonal", w)
+
+	g.title("doubling")
+	top := t.word(2*n - 1)
+	g.zero(top)
+	for k := 1; k < 2*n-1; k++ {
+		r := w[k]
+		if isStack(r) {
+			g.MOVQ(r, RAX)
+			r = RAX
+		}
+		if k == 1 {
+			g.ADDQ(r, w[k])
+		} else {
+			g.ADCQ(r, w[k])
+		}
+	}
+	g.ADCQ(U8(0), top)
+
+	// carry between squares is kept at SI
+	g.title("squares")
+	for i := 0; i < n; i++ {
+		g.Comment(fmt.Sprintf("| a%d * a%d ", i, i))
+		g.MOVQ(at(RDI, i), RAX)
+		g.MULQ(RAX)
+		if i == 0 {
+			g.MOVQ(RAX, t.place(0))
+			g.ADDQ(RDX, w[1])
+		} else {
+			g.ADDQ(RSI, RAX)
+			g.ADCQ(U8(0), RDX)
+			g.ADDQ(RAX, w[2*i])
+			g.ADCQ(RDX, w[2*i+1])
+		}
+		if i < n-1 {
+			g.zero(RSI)
+			g.ADCQ(U8(0), RSI)
+		}
+	}
+	g.title("\t\t\t")
+	g.table("W", w)
+	g.montgomeryNoADX(t.f, w)
+}
+
+// squareSingleNoADX is the single limb square, reduction is a single step
+func (g *gen) squareSingleNoADX() {
+	g.title("inputs")
+	g.Load(g.Param("a"), RDI)
+	g.Load(g.Param("p"), RSI)
+
+	g.title("square")
+	g.MOVQ(at(RDI, 0), RAX)
+	g.MULQ(RAX)
+	g.MOVQ(RAX, R8)
+	g.MOVQ(RDX, R9)
+
+	g.title("montgomery reduction")
+	g.MOVQ(R8, RAX)
+	g.MULQ(g.inp())
+	g.MOVQ(RAX, RCX)
+	g.MOVQ(at(RSI, 0), RAX)
+	g.MULQ(RCX)
+	g.ADDQ(RAX, R8)
+	g.ADCQ(RDX, R9)
+	g.ADCQ(U8(0), R8)
+
+	g.title("modular reduction")
+	g.MOVQ(R9, RAX)
+	g.SUBQ(at(RSI, 0), RAX)
+	g.SBBQ(U8(0), R8)
+
+	g.title("out")
+	g.Load(g.Param("c"), RDI)
+	g.CMOVQCC(RAX, R9)
+	g.MOVQ(R9, at(RDI, 0))
+	g.RET()
+	g.end()
+}
@@ -2,6 +2,7 @@ package eip
 
 import (
 	"math/big"
+	"sync"
 )
 
 type blsInstance struct {
@@ -15,6 +16,7 @@ type blsInstance struct {
 	t12         []*fe12
 	preferNaf   bool
 	zNaf        []int8
+	workers     int
 }
 
 func newBLSInstance(z *big.Int, zIsnegative bool, twistType int, g1 *g1, g2 *g22, fq12 *fq12, forceNoNaf bool) *blsInstance {
@@ -40,6 +42,7 @@ func newBLSInstance(z *big.Int, zIsnegative bool, twistType int, g1 *g1, g2 *g22
 		fq12:        fq12,
 		preferNaf:   preferNaf,
 		zNaf:        naf,
+		workers:     1,
 	}
 	bls.t2 = make([]*fe2, 17)
 	bls.t12 = make([]*fe12, 17)
@@ -47,9 +50,35 @@ func newBLSInstance(z *big.Int, zIsnegative bool, twistType int, g1 *g1, g2 *g22
 		bls.t2[i] = bls.fq12.f.f.new()
 		bls.t12[i] = bls.fq12.new()
 	}
+	// fast subgroup tests if the order is of the family
+	g1.subgroup, g2.subgroup = blsSubgroupTest(z, g1.q), blsSubgroupTest(z, g2.q)
+	g2.gls = newGLS(fq12.fq6().nonResidue, twistType)
 	return bls
 }
 
+// setWorkers sets the number of goroutines that miller loop of a multi
+// pairing is split across
+func (bls *blsInstance) setWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	bls.workers = n
+}
+
+// clone returns an engine with its own scratch space for a worker
+func (bls *blsInstance) clone() *blsInstance {
+	fq12 := bls.fq12.clone()
+	g2 := bls.g2.clone(fq12.f.f)
+	c := *bls
+	c.fq12, c.g2, c.workers = fq12, g2, 1
+	c.t2, c.t12 = make([]*fe2, len(bls.t2)), make([]*fe12, len(bls.t12))
+	for i := range c.t2 {
+		c.t2[i] = fq12.f.f.new()
+		c.t12[i] = fq12.new()
+	}
+	return &c
+}
+
 func (bls *blsInstance) gt() *fq12 {
 	return bls.fq12
 }
@@ -184,16 +213,17 @@ func (bls *blsInstance) additionStep(coeff *fe6C, r *pointG22, q *pointG22) {
 }
 
 func (bls *blsInstance) ell(f *fe12, coeffs *fe6C, p *pointG1) {
-	fq2 := bls.fq12.f.f
+	// coefficients are kept intact so that they can be reused
+	fq2, t := bls.fq12.f.f, bls.t2
 	switch bls.twistType {
 	case 1: // M
-		fq2.mulByFq(&coeffs[2], &coeffs[2], p[1])
-		fq2.mulByFq(&coeffs[1], &coeffs[1], p[0])
-		bls.fq12.mulBy014(f, &coeffs[0], &coeffs[1], &coeffs[2])
+		fq2.mulByFq(t[0], &coeffs[2], p[1])
+		fq2.mulByFq(t[1], &coeffs[1], p[0])
+		bls.fq12.mulBy014(f, &coeffs[0], t[1], t[0])
 	case 2: // D
-		fq2.mulByFq(&coeffs[0], &coeffs[0], p[1])
-		fq2.mulByFq(&coeffs[1], &coeffs[1], p[0])
-		bls.fq12.mulBy034(f, &coeffs[0], &coeffs[1], &coeffs[2])
+		fq2.mulByFq(t[0], &coeffs[0], p[1])
+		fq2.mulByFq(t[1], &coeffs[1], p[0])
+		bls.fq12.mulBy034(f, t[0], t[1], &coeffs[2])
 	}
 }
 
@@ -244,15 +274,41 @@ func (bls *blsInstance) prepareWithoutNaf(coeffs *[]fe6C, T, Q *pointG22, twoInv
 	}
 }
 
+// prepareG2 returns line coefficients of miller loop for a g2 point, they
+// can be used in any number of pairings with the same engine parameters
+func (bls *blsInstance) prepareG2(Q *pointG22) ([]fe6C, bool) {
+	coeffs := make([]fe6C, bls.calculateCoeffLength())
+	if ok := bls.prepare(&coeffs, Q); !ok {
+		return nil, false
+	}
+	return coeffs, true
+}
+
 func (bls *blsInstance) millerLoop(f *fe12, g1Points []*pointG1, g2Points []*pointG22) bool {
+	if bls.workers > 1 && len(g1Points) > 1 {
+		return bls.parallelMillerLoop(f, len(g1Points), func(e *blsInstance, f *fe12, from, to int) bool {
+			return e.millerLoop(f, g1Points[from:to], g2Points[from:to])
+		})
+	}
 	coeffs := make([][]fe6C, len(g1Points))
-	coeffsLen := bls.calculateCoeffLength()
 	for i := 0; i < len(g1Points); i++ {
-		coeffs[i] = make([]fe6C, coeffsLen)
-		if ok := bls.prepare(&coeffs[i], g2Points[i]); !ok {
+		var ok bool
+		if coeffs[i], ok = bls.prepareG2(g2Points[i]); !ok {
 			return false
 		}
 	}
+	bls.millerLoopPrepared(f, coeffs, g1Points)
+	return true
+}
+
+func (bls *blsInstance) millerLoopPrepared(f *fe12, coeffs [][]fe6C, g1Points []*pointG1) {
+	if bls.workers > 1 && len(g1Points) > 1 {
+		bls.parallelMillerLoop(f, len(g1Points), func(e *blsInstance, f *fe12, from, to int) bool {
+			e.millerLoopPrepared(f, coeffs[from:to], g1Points[from:to])
+			return true
+		})
+		return
+	}
 	if bls.preferNaf {
 		bls.millerLoopWithNaf(f, coeffs, g1Points)
 	} else {
@@ -261,6 +317,45 @@ func (bls *blsInstance) millerLoop(f *fe12, g1Points []*pointG1, g2Points []*poi
 	if bls.zIsnegative {
 		bls.fq12.conjugate(f, f)
 	}
+}
+
+// parallelMillerLoop splits n pairs into chunks, runs loop for each chunk
+// on a separate engine and accumulator and multiplies partial results into
+// f. Miller loop of a product is the product of miller loops of chunks.
+func (bls *blsInstance) parallelMillerLoop(f *fe12, n int, loop func(e *blsInstance, f *fe12, from, to int) bool) bool {
+	workers := bls.workers
+	if workers > n {
+		workers = n
+	}
+	size := (n + workers - 1) / workers
+	chunks := (n + size - 1) / size
+	acc, ok := make([]*fe12, chunks), make([]bool, chunks)
+	var wg sync.WaitGroup
+	for i := 0; i < chunks; i++ {
+		from, to := i*size, (i+1)*size
+		if to > n {
+			to = n
+		}
+		// first chunk goes on with f itself
+		acc[i] = f
+		if i > 0 {
+			acc[i] = bls.fq12.one()
+		}
+		wg.Add(1)
+		go func(i, from, to int) {
+			defer wg.Done()
+			ok[i] = loop(bls.clone(), acc[i], from, to)
+		}(i, from, to)
+	}
+	wg.Wait()
+	for i := range acc {
+		if !ok[i] {
+			return false
+		}
+		if i > 0 {
+			bls.fq12.mul(f, f, acc[i])
+		}
+	}
 	return true
 }
 
@@ -412,6 +507,31 @@ func (bls *blsInstance) multiPair(g1Points []*pointG1, g2Points []*pointG22) (*f
 	return f, true
 }
 
+// multiPairPrepared is multiPair with prepared g2 points, nil coefficients
+// stand for the point at infinity
+func (bls *blsInstance) multiPairPrepared(g1Points []*pointG1, coeffs [][]fe6C) (*fe12, bool) {
+	if len(g1Points) != len(coeffs) {
+		return nil, false
+	}
+	var _g1Points []*pointG1
+	var _coeffs [][]fe6C
+	for i := 0; i < len(g1Points); i++ {
+		if !bls.g1.isZero(g1Points[i]) && coeffs[i] != nil {
+			_g1Points = append(_g1Points, g1Points[i])
+			_coeffs = append(_coeffs, coeffs[i])
+		}
+	}
+	f := bls.fq12.one()
+	if len(_g1Points) == 0 {
+		return f, true
+	}
+	bls.millerLoopPrepared(f, _coeffs, _g1Points)
+	if ok := bls.finalExp(f); !ok {
+		return nil, false
+	}
+	return f, true
+}
+
 func (bls *blsInstance) calculateCoeffLength() int {
 	j := 0
 	if bls.preferNaf {
@@ -0,0 +1,559 @@
+package eip
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// bls signatures of draft-irtf-cfrg-bls-signature over bls12-381 with proof
+// of possession scheme. points are serialized in zcash format as ethereum
+// consensus does: compressed g1 is 48 bytes, compressed g2 is x1 || x0 in 96
+// bytes, three most significant bits are compression, infinity and sort flags
+
+const (
+	blsSigDSTMinPubkeySize = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	blsPopDSTMinPubkeySize = "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	blsSigDSTMinSigSize    = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+	blsPopDSTMinSigSize    = "BLS_POP_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+	blsKeyGenSalt          = "BLS-SIG-KEYGEN-SALT-"
+	blsSecretKeySize       = 32
+	// window of generator table for public key derivation
+	blsGeneratorTableWindow = 5
+)
+
+const (
+	zcashCompressionFlag byte = 0x80
+	zcashInfinityFlag    byte = 0x40
+	zcashSortFlag        byte = 0x20
+)
+
+type blsSignatureScheme struct {
+	e      *blsInstance
+	minPk  bool
+	sigDST []byte
+	popDST []byte
+	g1m    *g1Mapper
+	g2m    *g22Mapper
+	g1One  *pointG1
+	g2One  *pointG22
+	// multiples of the generator of public key group
+	pkTable *FixedBaseTable
+}
+
+// newBLSSignatureScheme returns minimal public key size variant where public
+// keys are in g1 and signatures are in g2 if minPk is set, otherwise roles of
+// groups are swapped
+func newBLSSignatureScheme(minPk bool) (*blsSignatureScheme, error) {
+	e, g1One, g2One, err := newBLS12381Instance()
+	if err != nil {
+		return nil, err
+	}
+	s := &blsSignatureScheme{
+		e:     e,
+		minPk: minPk,
+		g1m:   bls12381G1Mapper(e.g1),
+		g2m:   bls12381G2Mapper(e.g2),
+		g1One: g1One,
+		g2One: g2One,
+	}
+	bits := e.g1.q.BitLen()
+	if minPk {
+		s.sigDST, s.popDST = []byte(blsSigDSTMinPubkeySize), []byte(blsPopDSTMinPubkeySize)
+		s.pkTable, err = e.g1.newFixedBaseTable(g1One, bits, blsGeneratorTableWindow)
+	} else {
+		s.sigDST, s.popDST = []byte(blsSigDSTMinSigSize), []byte(blsPopDSTMinSigSize)
+		s.pkTable, err = e.g2.newFixedBaseTable(g2One, bits, blsGeneratorTableWindow)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newBLS12381Instance constructs pairing engine of bls12-381 with the
+// standard generators
+func newBLS12381Instance() (*blsInstance, *pointG1, *pointG22, error) {
+	p, _ := new(big.Int).SetString(bls12381Modulus[2:], 16)
+	q, _ := new(big.Int).SetString(bls12381Order[2:], 16)
+	f, err := newField(p.Bytes())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonResidue := f.new()
+	f.neg(nonResidue, f.one)
+	fq2, err := newFq2(f, f.toBytes(nonResidue))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fq2.calculateFrobeniusCoeffs()
+	nonResidue2 := fq2.one()
+	f.copy(nonResidue2[1], f.one)
+	fq6, err := newFq6Cubic(fq2, fq2.toBytes(nonResidue2))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fq6.calculateFrobeniusCoeffs()
+	fq12, err := newFq12(fq6, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fq12.calculateFrobeniusCoeffs()
+	a, b := f.new(), f.new()
+	f.double(b, f.one)
+	f.double(b, b)
+	g1, err := newG1(f, a, b, q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	a2, b2 := fq2.new(), fq2.new()
+	fq2.mulByFq(b2, nonResidue2, b)
+	g2, err := newG22(fq2, a2, b2, q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	g1One := g1.newPoint()
+	g1One[0], _ = f.fromString("0x17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb")
+	g1One[1], _ = f.fromString("0x08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1")
+	f.copy(g1One[2], f.one)
+	g2One := g2.newPoint()
+	g2One[0][0], _ = f.fromString("0x024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8")
+	g2One[0][1], _ = f.fromString("0x13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e")
+	g2One[1][0], _ = f.fromString("0x0ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801")
+	g2One[1][1], _ = f.fromString("0x0606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be")
+	fq2.copy(g2One[2], fq2.one())
+	z, _ := new(big.Int).SetString("d201000000010000", 16)
+	return newBLSInstance(z, true, TWIST_M, g1, g2, fq12, false), g1One, g2One, nil
+}
+
+// blsKeyGen is KeyGen with hkdf of sha256, ikm is expected to be at least 32
+// bytes of secret randomness
+func blsKeyGen(ikm, keyInfo []byte) (*big.Int, error) {
+	if len(ikm) < 32 {
+		return nil, errors.New("key material should be at least 32 bytes")
+	}
+	// L = ceil((3 * ceil(log2(r))) / 16)
+	const l = 48
+	q, _ := new(big.Int).SetString(bls12381Order[2:], 16)
+	salt := []byte(blsKeyGenSalt)
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		prk := hkdfExtract(salt, append(append([]byte{}, ikm...), 0))
+		info := append(append([]byte{}, keyInfo...), 0, l)
+		sk.SetBytes(hkdfExpand(prk, info, l))
+		sk.Mod(sk, q)
+	}
+	return sk, nil
+}
+
+func hkdfExtract(salt, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+func hkdfExpand(prk, info []byte, outLen int) []byte {
+	out := make([]byte, 0, outLen+sha256.Size)
+	var t []byte
+	for i := byte(1); len(out) < outLen; i++ {
+		mac := hmac.New(sha256.New, prk)
+		mac.Write(t)
+		mac.Write(info)
+		mac.Write([]byte{i})
+		t = mac.Sum(nil)
+		out = append(out, t...)
+	}
+	return out[:outLen]
+}
+
+func (s *blsSignatureScheme) secretKeyFromBytes(in []byte) (*big.Int, error) {
+	if len(in) != blsSecretKeySize {
+		return nil, fmt.Errorf("secret key should be %d bytes given %d", blsSecretKeySize, len(in))
+	}
+	sk := new(big.Int).SetBytes(in)
+	if sk.Sign() == 0 || sk.Cmp(s.e.g1.q) != -1 {
+		return nil, errors.New("secret key is out of range")
+	}
+	return sk, nil
+}
+
+func (s *blsSignatureScheme) secretKeyToBytes(sk *big.Int) []byte {
+	out := make([]byte, blsSecretKeySize)
+	b := sk.Bytes()
+	copy(out[blsSecretKeySize-len(b):], b)
+	return out
+}
+
+func (s *blsSignatureScheme) checkSecretKey(sk *big.Int) error {
+	if sk == nil || sk.Sign() != 1 || sk.Cmp(s.e.g1.q) != -1 {
+		return errors.New("secret key is out of range")
+	}
+	return nil
+}
+
+// skToPk is SkToPk, generator is multiplied with the precomputed table
+func (s *blsSignatureScheme) skToPk(sk *big.Int) ([]byte, error) {
+	if err := s.checkSecretKey(sk); err != nil {
+		return nil, err
+	}
+	if s.minPk {
+		g := s.e.g1
+		return encodeG1Point(g, g.mulFixedBaseCT(g.newPoint(), s.pkTable, sk)), nil
+	}
+	g := s.e.g2
+	return encodeG2Point(g, g.mulFixedBaseCT(g.newPoint(), s.pkTable, sk)), nil
+}
+
+// keyValidate is KeyValidate, identity is rejected
+func (s *blsSignatureScheme) keyValidate(pk []byte) bool {
+	_, _, err := s.decodePublicKey(pk)
+	return err == nil
+}
+
+func (s *blsSignatureScheme) sign(sk *big.Int, msg []byte) ([]byte, error) {
+	return s.coreSign(sk, msg, s.sigDST)
+}
+
+func (s *blsSignatureScheme) verify(pk, msg, sig []byte) bool {
+	return s.coreAggregateVerify([][]byte{pk}, [][]byte{msg}, sig, s.sigDST)
+}
+
+// aggregateVerify is AggregateVerify of proof of possession scheme which
+// does not require messages to be distinct
+func (s *blsSignatureScheme) aggregateVerify(pks, msgs [][]byte, sig []byte) bool {
+	return s.coreAggregateVerify(pks, msgs, sig, s.sigDST)
+}
+
+// fastAggregateVerify verifies a signature of a single message signed by all
+// public keys, keys are expected to have verified proofs of possession
+func (s *blsSignatureScheme) fastAggregateVerify(pks [][]byte, msg, sig []byte) bool {
+	if len(pks) == 0 {
+		return false
+	}
+	pk, err := s.aggregatePublicKeys(pks)
+	if err != nil {
+		return false
+	}
+	return s.coreAggregateVerify([][]byte{pk}, [][]byte{msg}, sig, s.sigDST)
+}
+
+// popProve is PopProve, proof is a signature of the serialized public key
+func (s *blsSignatureScheme) popProve(sk *big.Int) ([]byte, error) {
+	pk, err := s.skToPk(sk)
+	if err != nil {
+		return nil, err
+	}
+	return s.coreSign(sk, pk, s.popDST)
+}
+
+func (s *blsSignatureScheme) popVerify(pk, proof []byte) bool {
+	return s.coreAggregateVerify([][]byte{pk}, [][]byte{pk}, proof, s.popDST)
+}
+
+// aggregateSignatures is Aggregate, signatures are checked to be in the
+// subgroup
+func (s *blsSignatureScheme) aggregateSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("no signatures to aggregate")
+	}
+	if s.minPk {
+		g := s.e.g2
+		acc := g.zero()
+		for _, in := range sigs {
+			p, err := decodeG2Point(g, in)
+			if err != nil {
+				return nil, err
+			}
+			if !g.checkCorrectSubgroup(p) {
+				return nil, errors.New("signature is not in the subgroup")
+			}
+			g.add(acc, acc, p)
+		}
+		return encodeG2Point(g, acc), nil
+	}
+	g := s.e.g1
+	acc := g.zero()
+	for _, in := range sigs {
+		p, err := decodeG1Point(g, in)
+		if err != nil {
+			return nil, err
+		}
+		if !g.checkCorrectSubgroup(p) {
+			return nil, errors.New("signature is not in the subgroup")
+		}
+		g.add(acc, acc, p)
+	}
+	return encodeG1Point(g, acc), nil
+}
+
+// aggregatePublicKeys sums public keys that are validated with KeyValidate
+func (s *blsSignatureScheme) aggregatePublicKeys(pks [][]byte) ([]byte, error) {
+	if len(pks) == 0 {
+		return nil, errors.New("no public keys to aggregate")
+	}
+	if s.minPk {
+		g := s.e.g1
+		acc := g.zero()
+		for _, in := range pks {
+			p, _, err := s.decodePublicKey(in)
+			if err != nil {
+				return nil, err
+			}
+			g.add(acc, acc, p)
+		}
+		return encodeG1Point(g, acc), nil
+	}
+	g := s.e.g2
+	acc := g.zero()
+	for _, in := range pks {
+		_, p, err := s.decodePublicKey(in)
+		if err != nil {
+			return nil, err
+		}
+		g.add(acc, acc, p)
+	}
+	return encodeG2Point(g, acc), nil
+}
+
+// decodePublicKey returns public key in the group of the variant, other
+// point is nil
+func (s *blsSignatureScheme) decodePublicKey(in []byte) (*pointG1, *pointG22, error) {
+	if s.minPk {
+		g := s.e.g1
+		p, err := decodeG1Point(g, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		if g.isZero(p) || !g.checkCorrectSubgroup(p) {
+			return nil, nil, errors.New("invalid public key")
+		}
+		return p, nil, nil
+	}
+	g := s.e.g2
+	p, err := decodeG2Point(g, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	if g.isZero(p) || !g.checkCorrectSubgroup(p) {
+		return nil, nil, errors.New("invalid public key")
+	}
+	return nil, p, nil
+}
+
+func (s *blsSignatureScheme) coreSign(sk *big.Int, msg, dst []byte) ([]byte, error) {
+	if err := s.checkSecretKey(sk); err != nil {
+		return nil, err
+	}
+	if s.minPk {
+		h, err := s.g2m.hashToCurve(msg, dst)
+		if err != nil {
+			return nil, err
+		}
+		g := s.e.g2
+		return encodeG2Point(g, g.mulScalarCT(h, h, sk)), nil
+	}
+	h, err := s.g1m.hashToCurve(msg, dst)
+	if err != nil {
+		return nil, err
+	}
+	g := s.e.g1
+	return encodeG1Point(g, g.mulScalarCT(h, h, sk)), nil
+}
+
+// coreAggregateVerify checks e(pk_1, H(m_1)) * ... * e(pk_n, H(m_n)) = e(g, sig)
+// with a single multi pairing, arguments are swapped in minimal signature
+// size variant. miller loop expects affine points
+func (s *blsSignatureScheme) coreAggregateVerify(pks, msgs [][]byte, sig, dst []byte) bool {
+	n := len(pks)
+	if n == 0 || n != len(msgs) {
+		return false
+	}
+	g1Points := make([]*pointG1, n+1)
+	g2Points := make([]*pointG22, n+1)
+	if s.minPk {
+		g := s.e.g2
+		p, err := decodeG2Point(g, sig)
+		if err != nil || !g.checkCorrectSubgroup(p) {
+			return false
+		}
+		g1Points[n] = s.e.g1.neg(s.e.g1.newPoint(), s.g1One)
+		g2Points[n] = p
+	} else {
+		g := s.e.g1
+		p, err := decodeG1Point(g, sig)
+		if err != nil || !g.checkCorrectSubgroup(p) {
+			return false
+		}
+		g1Points[n] = g.neg(p, p)
+		g2Points[n] = s.g2One
+	}
+	for i := 0; i < n; i++ {
+		pk1, pk2, err := s.decodePublicKey(pks[i])
+		if err != nil {
+			return false
+		}
+		if s.minPk {
+			h, err := s.g2m.hashToCurve(msgs[i], dst)
+			if err != nil {
+				return false
+			}
+			g1Points[i], g2Points[i] = pk1, h
+		} else {
+			h, err := s.g1m.hashToCurve(msgs[i], dst)
+			if err != nil {
+				return false
+			}
+			g1Points[i], g2Points[i] = h, pk2
+		}
+	}
+	if s.minPk {
+		s.e.g2.batchAffine(g2Points[:n])
+	} else {
+		s.e.g1.batchAffine(g1Points[:n])
+	}
+	f, ok := s.e.multiPair(g1Points, g2Points)
+	return ok && s.e.fq12.isOne(f)
+}
+
+// isLexicographicallyLargest reports whether a > p - 1 - a in canonical
+// representation
+func isLexicographicallyLargest(f *fq, a fe) bool {
+	neg := f.new()
+	f.neg(neg, a)
+	return bytes.Compare(f.toBytes(a), f.toBytes(neg)) == 1
+}
+
+func encodeG1Point(g *g1, p *pointG1) []byte {
+	byteLen := g.f.byteSize()
+	if g.isZero(p) {
+		out := make([]byte, byteLen)
+		out[0] = zcashCompressionFlag | zcashInfinityFlag
+		return out
+	}
+	a := g.affine(g.newPoint(), p)
+	out := g.f.toBytes(a[0])
+	out[0] |= zcashCompressionFlag
+	if isLexicographicallyLargest(g.f, a[1]) {
+		out[0] |= zcashSortFlag
+	}
+	return out
+}
+
+func decodeG1Point(g *g1, in []byte) (*pointG1, error) {
+	byteLen := g.f.byteSize()
+	if len(in) != byteLen {
+		return nil, fmt.Errorf("compressed g1 point should be %d bytes given %d", byteLen, len(in))
+	}
+	x, infinity, large, err := decodeZCashFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	if infinity {
+		return g.zero(), nil
+	}
+	p := g.newPoint()
+	if p[0], err = g.f.fromBytes(x); err != nil {
+		return nil, err
+	}
+	// y^2 = x^3 + b
+	y := p[1]
+	g.f.square(y, p[0])
+	g.f.mul(y, y, p[0])
+	g.f.add(y, y, g.b)
+	if !g.f.sqrt(y, y) {
+		return nil, errors.New("point is not on curve")
+	}
+	if isLexicographicallyLargest(g.f, y) != large {
+		g.f.neg(y, y)
+	}
+	g.f.copy(p[2], g.f.one)
+	return p, nil
+}
+
+func encodeG2Point(g *g22, p *pointG22) []byte {
+	byteLen := g.f.byteSize()
+	out := make([]byte, byteLen)
+	if g.isZero(p) {
+		out[0] = zcashCompressionFlag | zcashInfinityFlag
+		return out
+	}
+	a := g.affine(g.newPoint(), p)
+	fq := g.f.fq()
+	copy(out[:byteLen/2], fq.toBytes(a[0][1]))
+	copy(out[byteLen/2:], fq.toBytes(a[0][0]))
+	out[0] |= zcashCompressionFlag
+	if isLexicographicallyLargestFq2(g.f, a[1]) {
+		out[0] |= zcashSortFlag
+	}
+	return out
+}
+
+func decodeG2Point(g *g22, in []byte) (*pointG22, error) {
+	byteLen := g.f.byteSize()
+	if len(in) != byteLen {
+		return nil, fmt.Errorf("compressed g2 point should be %d bytes given %d", byteLen, len(in))
+	}
+	x, infinity, large, err := decodeZCashFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	if infinity {
+		return g.zero(), nil
+	}
+	fq := g.f.fq()
+	p := g.newPoint()
+	if p[0][1], err = fq.fromBytes(x[:byteLen/2]); err != nil {
+		return nil, err
+	}
+	if p[0][0], err = fq.fromBytes(x[byteLen/2:]); err != nil {
+		return nil, err
+	}
+	// y^2 = x^3 + b
+	y := p[1]
+	g.f.square(y, p[0])
+	g.f.mul(y, y, p[0])
+	g.f.add(y, y, g.b)
+	if !g.f.sqrt(y, y) {
+		return nil, errors.New("point is not on curve")
+	}
+	if isLexicographicallyLargestFq2(g.f, y) != large {
+		g.f.neg(y, y)
+	}
+	g.f.copy(p[2], g.f.one())
+	return p, nil
+}
+
+func isLexicographicallyLargestFq2(f *fq2, a *fe2) bool {
+	fq := f.fq()
+	if fq.isZero(a[1]) {
+		return isLexicographicallyLargest(fq, a[0])
+	}
+	return isLexicographicallyLargest(fq, a[1])
+}
+
+// decodeZCashFlags strips flags off and returns the copy of x coordinate
+func decodeZCashFlags(in []byte) ([]byte, bool, bool, error) {
+	if in[0]&zcashCompressionFlag == 0 {
+		return nil, false, false, errors.New("uncompressed points are not supported")
+	}
+	infinity := in[0]&zcashInfinityFlag != 0
+	large := in[0]&zcashSortFlag != 0
+	x := append([]byte{}, in...)
+	x[0] &^= zcashCompressionFlag | zcashInfinityFlag | zcashSortFlag
+	if infinity {
+		if large {
+			return nil, false, false, errors.New("sort flag is set for point at infinity")
+		}
+		for _, b := range x {
+			if b != 0 {
+				return nil, false, false, errors.New("non canonical encoding of point at infinity")
+			}
+		}
+	}
+	return x, infinity, large, nil
+}
@@ -0,0 +1,375 @@
+package eip
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestBLSSignatureEncoding(t *testing.T) {
+	s, err := newBLSSignatureScheme(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1, g2 := s.e.g1, s.e.g2
+	g1Bytes := fromHex(48, "0x97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb")
+	if !bytes.Equal(encodeG1Point(g1, s.g1One), g1Bytes) {
+		t.Fatal("bad encoding of g1 generator")
+	}
+	g2Bytes := fromHex(96, "0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8")
+	if !bytes.Equal(encodeG2Point(g2, s.g2One), g2Bytes) {
+		t.Fatal("bad encoding of g2 generator")
+	}
+	for i := 0; i < 10; i++ {
+		k, _ := rand.Int(rand.Reader, g1.q)
+		p1 := g1.mulScalar(g1.newPoint(), s.g1One, k)
+		q1, err := decodeG1Point(g1, encodeG1Point(g1, p1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g1.equal(p1, q1) {
+			t.Fatal("g1 encoding round trip failed")
+		}
+		p2 := g2.mulScalar(g2.newPoint(), s.g2One, k)
+		q2, err := decodeG2Point(g2, encodeG2Point(g2, p2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g2.equal(p2, q2) {
+			t.Fatal("g2 encoding round trip failed")
+		}
+	}
+	inf := make([]byte, 48)
+	inf[0] = 0xc0
+	if p, err := decodeG1Point(g1, inf); err != nil || !g1.isZero(p) {
+		t.Fatal("infinity is expected to be decoded")
+	}
+	for _, in := range [][]byte{
+		// uncompressed flag
+		g1Bytes[1:],
+		append([]byte{0x17}, g1Bytes[1:]...),
+		// infinity with sort flag
+		append([]byte{0xe0}, make([]byte, 47)...),
+		// infinity with non zero x
+		append([]byte{0xc0}, g1Bytes[1:]...),
+		// x is larger than modulus
+		fromHex(48, "0x9a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab"),
+	} {
+		if _, err := decodeG1Point(g1, in); err == nil {
+			t.Fatal("bad encoding is expected to fail")
+		}
+	}
+}
+
+func TestBLSKeyGen(t *testing.T) {
+	// master key derivation of eip-2333 is KeyGen with empty key info
+	ikm := fromHex(64, "0xc55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	sk, err := blsKeyGen(ikm, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := new(big.Int).SetString("6083874454709270928345386274498605044986640685124978867557563392430687146096", 10)
+	if sk.Cmp(expected) != 0 {
+		t.Fatal("bad secret key")
+	}
+	if _, err := blsKeyGen(ikm[:31], nil); err == nil {
+		t.Fatal("short key material is expected to fail")
+	}
+}
+
+func TestBLSSignatureVectors(t *testing.T) {
+	// ethereum consensus spec tests, bls/sign and bls/verify
+	s, err := newBLSSignatureScheme(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []struct {
+		sk   string
+		pk   string
+		sigs [3]string
+	}{
+		{
+			"0x263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+			"0xa491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+			[3]string{
+				"0xb6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55",
+				"0x882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c20767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb",
+				"0x91347bccf740d859038fcdcaf233eeceb2a436bcaaee9b2aa3bfb70efe29dfb2677562ccbea1c8e061fb9971b0753c240622fab78489ce96768259fc01360346da5b9f579e5da0d941e4c6ba18a0e64906082375394f337fa1af2b7127b0d121",
+			},
+		},
+		{
+			"0x47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+			"0xb301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+			[3]string{
+				"0xb23c46be3a001c63ca711f87a005c200cc550b9429d5f4eb38d74322144f1b63926da3388979e5321012fb1a0526bcd100b5ef5fe72628ce4cd5e904aeaa3279527843fae5ca9ca675f4f51ed8f83bbf7155da9ecc9663100a885d5dc6df96d9",
+				"0xaf1390c3c47acdb37131a51216da683c509fce0e954328a59f93aebda7e4ff974ba208d9a4a2a2389f892a9d418d618418dd7f7a6bc7aa0da999a9d3a5b815bc085e14fd001f6a1948768a3f4afefc8b8240dda329f984cb345c6363272ba4fe",
+				"0x9674e2228034527f4c083206032b020310face156d4a4685e2fcaec2f6f3665aa635d90347b6ce124eb879266b1e801d185de36a0a289b85e9039662634f2eea1e02e670bc7ab849d006a70b2f93b84597558a05b879c8d445f387a5d5b653df",
+			},
+		},
+		{
+			"0x328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+			"0xb53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+			[3]string{
+				"0x948a7cb99f76d616c2c564ce9bf4a519f1bea6b0a624a02276443c245854219fabb8d4ce061d255af5330b078d5380681751aa7053da2c98bae898edc218c75f07e24d8802a17cd1f6833b71e58f5eb5b94208b4d0bb3848cecb075ea21be115",
+				"0xa4efa926610b8bd1c8330c918b7a5e9bf374e53435ef8b7ec186abf62e1b1f65aeaaeb365677ac1d1172a1f5b44b4e6d022c252c58486c0a759fbdc7de15a756acc4d343064035667a594b4c2a6f0b0b421975977f297dba63ee2f63ffe47bb6",
+				"0xae82747ddeefe4fd64cf9cedb9b04ae3e8a43420cd255e3c7cd06a8d88b7c7f8638543719981c5d16fa3527c468c25f0026704a6951bde891360c7e8d12ddee0559004ccdbe6046b55bae1b257ee97f7cdb955773d7cf29adf3ccbb9975e4eb9",
+			},
+		},
+	}
+	msgs := [][]byte{
+		fromHex(32, "0x0000000000000000000000000000000000000000000000000000000000000000"),
+		fromHex(32, "0x5656565656565656565656565656565656565656565656565656565656565656"),
+		fromHex(32, "0xabababababababababababababababababababababababababababababababab"),
+	}
+	pks := make([][]byte, len(keys))
+	sigs := make([][][]byte, len(keys))
+	for i, v := range keys {
+		sk, err := s.secretKeyFromBytes(fromHex(32, v.sk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(s.secretKeyToBytes(sk), fromHex(32, v.sk)) {
+			t.Fatal("bad secret key encoding")
+		}
+		if pks[i], err = s.skToPk(sk); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pks[i], fromHex(48, v.pk)) {
+			t.Fatalf("bad public key at vector %d", i)
+		}
+		sigs[i] = make([][]byte, len(msgs))
+		for j, msg := range msgs {
+			if sigs[i][j], err = s.sign(sk, msg); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sigs[i][j], fromHex(96, v.sigs[j])) {
+				t.Fatalf("bad signature at vector %d %d", i, j)
+			}
+			if !s.verify(pks[i], msg, sigs[i][j]) {
+				t.Fatalf("signature is expected to be verified at vector %d %d", i, j)
+			}
+		}
+	}
+	infSig := append([]byte{0xc0}, make([]byte, 95)...)
+	infPk := append([]byte{0xc0}, make([]byte, 47)...)
+	tamper := func(sig []byte) []byte {
+		out := append([]byte{}, sig...)
+		copy(out[len(out)-4:], []byte{0xff, 0xff, 0xff, 0xff})
+		return out
+	}
+
+	// bls/aggregate
+	agg0 := fromHex(96, "0x9683b3e6701f9a4b706709577963110043af78a5b41991b998475a3d3fd62abf35ce03b33908418efc95a058494a8ae504354b9f626231f6b3f3c849dfdeaf5017c4780e2aee1850ceaf4b4d9ce70971a3d2cfcd97b7e5ecf6759f8da5f76d31")
+	agg2 := fromHex(96, "0x9712c3edd73a209c742b8250759db12549b3eaf43b5ca61376d9f30e2747dbcf842d8b2ac0901d2a093713e20284a7670fcf6954e9ab93de991bb9b313e664785a075fc285806fa5224c82bde146561b446ccfc706a64b8579513cfc4ff1d930")
+	for i, v := range []struct {
+		sigs     [][]byte
+		expected []byte
+	}{
+		{[][]byte{sigs[0][0], sigs[1][0], sigs[2][0]}, agg0},
+		{[][]byte{sigs[0][2], sigs[1][2], sigs[2][2]}, agg2},
+		{[][]byte{infSig}, infSig},
+		{[][]byte{sigs[0][0], infSig}, sigs[0][0]},
+		// no signatures
+		{nil, nil},
+	} {
+		sig, err := s.aggregateSignatures(v.sigs)
+		if v.expected == nil {
+			if err == nil {
+				t.Fatalf("aggregation is expected to fail at vector %d", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, v.expected) {
+			t.Fatalf("bad aggregate signature at vector %d", i)
+		}
+	}
+
+	// bls/aggregate_verify, messages are signed by the keys in order
+	aggSig, err := s.aggregateSignatures([][]byte{sigs[0][0], sigs[1][1], sigs[2][2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []struct {
+		pks      [][]byte
+		msgs     [][]byte
+		sig      []byte
+		expected bool
+	}{
+		{pks, msgs, aggSig, true},
+		{pks, msgs, tamper(aggSig), false},
+		{[][]byte{pks[0], pks[1], pks[2], infPk}, append(msgs[:3:3], msgs[0]), aggSig, false},
+		{nil, nil, infSig, false},
+		{nil, nil, make([]byte, 96), false},
+	} {
+		if s.aggregateVerify(v.pks, v.msgs, v.sig) != v.expected {
+			t.Fatalf("bad aggregate verification at vector %d", i)
+		}
+	}
+
+	// bls/fast_aggregate_verify
+	for i, v := range []struct {
+		pks      [][]byte
+		msg      []byte
+		sig      []byte
+		expected bool
+	}{
+		{pks, msgs[0], agg0, true},
+		{pks, msgs[2], agg2, true},
+		{pks[:1], msgs[0], sigs[0][0], true},
+		// tampered signature
+		{pks, msgs[0], tamper(agg0), false},
+		// extra public key
+		{append(pks[:3:3], pks[0]), msgs[0], agg0, false},
+		// public key at infinity
+		{append(pks[:3:3], infPk), msgs[0], agg0, false},
+		// no public keys
+		{nil, msgs[0], infSig, false},
+		{nil, msgs[0], make([]byte, 96), false},
+	} {
+		if s.fastAggregateVerify(v.pks, v.msg, v.sig) != v.expected {
+			t.Fatalf("bad fast aggregate verification at vector %d", i)
+		}
+	}
+}
+
+func TestBLSSignatureMinSigVectors(t *testing.T) {
+	// the minimal signature size draft has no signature vectors of its own,
+	// with secret key one the core signature is the hash to g1 of rfc 9380
+	// and the public key is the g2 generator
+	s, err := newBLSSignatureScheme(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1 := s.e.g1
+	pk, err := s.skToPk(big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pk, fromHex(96, "0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8")) {
+		t.Fatal("bad public key")
+	}
+	dst := []byte("QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+	for i, v := range []struct {
+		msg  string
+		x, y string
+	}{
+		{
+			"",
+			"0x052926add2207b76ca4fa57a8734416c8dc95e24501772c814278700eed6d1e4e8cf62d9c09db0fac349612b759e79a1",
+			"0x08ba738453bfed09cb546dbb0783dbb3a5f1f566ed67bb6be0e8c67e2e81a4cc68ee29813bb7994998f3eae0c9c6a265",
+		},
+		{
+			"abc",
+			"0x03567bc5ef9c690c2ab2ecdf6a96ef1c139cc0b2f284dca0a9a7943388a49a3aee664ba5379a7655d3c68900be2f6903",
+			"0x0b9c15f3fe6e5cf4211f346271d7b01c8f3b28be689c8429c85b67af215533311f0b8dfaaa154fa6b88176c229f2885d",
+		},
+	} {
+		expected := g1.newPoint()
+		expected[0], _ = g1.f.fromString(v.x)
+		expected[1], _ = g1.f.fromString(v.y)
+		g1.f.copy(expected[2], g1.f.one)
+		sig, err := s.coreSign(big.NewInt(1), []byte(v.msg), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, encodeG1Point(g1, expected)) {
+			t.Fatalf("bad signature at vector %d", i)
+		}
+		if !s.coreAggregateVerify([][]byte{pk}, [][]byte{[]byte(v.msg)}, sig, dst) {
+			t.Fatalf("signature is expected to be verified at vector %d", i)
+		}
+		if s.coreAggregateVerify([][]byte{pk}, [][]byte{[]byte(v.msg)}, sig, s.sigDST) {
+			t.Fatalf("signature is expected to be domain separated at vector %d", i)
+		}
+	}
+}
+
+func TestBLSSignatureScheme(t *testing.T) {
+	for _, minPk := range []bool{true, false} {
+		s, err := newBLSSignatureScheme(minPk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 4
+		sks := make([]*big.Int, n)
+		pks, sigs, msgs := make([][]byte, n), make([][]byte, n), make([][]byte, n)
+		common := []byte("common message")
+		commonSigs := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			ikm := make([]byte, 32)
+			rand.Read(ikm)
+			if sks[i], err = blsKeyGen(ikm, nil); err != nil {
+				t.Fatal(err)
+			}
+			if pks[i], err = s.skToPk(sks[i]); err != nil {
+				t.Fatal(err)
+			}
+			msgs[i] = []byte{byte(i)}
+			if sigs[i], err = s.sign(sks[i], msgs[i]); err != nil {
+				t.Fatal(err)
+			}
+			if commonSigs[i], err = s.sign(sks[i], common); err != nil {
+				t.Fatal(err)
+			}
+			if !s.verify(pks[i], msgs[i], sigs[i]) {
+				t.Fatal("signature is expected to be verified")
+			}
+			if s.verify(pks[i], common, sigs[i]) {
+				t.Fatal("signature of another message is expected to fail")
+			}
+			proof, err := s.popProve(sks[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.popVerify(pks[i], proof) {
+				t.Fatal("proof of possession is expected to be verified")
+			}
+			if s.verify(pks[i], pks[i], proof) {
+				t.Fatal("proof of possession is expected to be domain separated")
+			}
+		}
+		sig, err := s.aggregateSignatures(sigs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.aggregateVerify(pks, msgs, sig) {
+			t.Fatal("aggregate signature is expected to be verified")
+		}
+		if s.aggregateVerify(pks[1:], msgs[1:], sig) {
+			t.Fatal("aggregate signature with missing signer is expected to fail")
+		}
+		sig, err = s.aggregateSignatures(commonSigs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.fastAggregateVerify(pks, common, sig) {
+			t.Fatal("aggregate signature is expected to be verified")
+		}
+		if s.fastAggregateVerify(pks, msgs[0], sig) {
+			t.Fatal("aggregate signature of another message is expected to fail")
+		}
+		// edge cases of ethereum consensus spec tests
+		var infSig, infPk []byte
+		if minPk {
+			infSig, infPk = encodeG2Point(s.e.g2, s.e.g2.zero()), encodeG1Point(s.e.g1, s.e.g1.zero())
+		} else {
+			infSig, infPk = encodeG1Point(s.e.g1, s.e.g1.zero()), encodeG2Point(s.e.g2, s.e.g2.zero())
+		}
+		if s.keyValidate(infPk) || s.verify(infPk, msgs[0], infSig) {
+			t.Fatal("public key at infinity is expected to fail")
+		}
+		if s.fastAggregateVerify(nil, common, infSig) || s.aggregateVerify(nil, nil, infSig) {
+			t.Fatal("empty set of public keys is expected to fail")
+		}
+		if _, err := s.aggregateSignatures(nil); err == nil {
+			t.Fatal("empty set of signatures is expected to fail")
+		}
+		if _, err := s.sign(big.NewInt(0), common); err == nil {
+			t.Fatal("zero secret key is expected to fail")
+		}
+	}
+}
@@ -2,6 +2,7 @@ package eip
 
 import (
 	"math/big"
+	"sync"
 )
 
 type bnInstance struct {
@@ -17,6 +18,7 @@ type bnInstance struct {
 	t12                      [16]*fe12
 	preferNaf                bool
 	sixUPlus2Naf             []int8
+	workers                  int
 }
 
 func newBNInstance(u *big.Int, uIsNegative bool, twistType int, g1 *g1, g2 *g22, fq12 *fq12, forceNoNaf bool) *bnInstance {
@@ -57,14 +59,39 @@ func newBNInstance(u *big.Int, uIsNegative bool, twistType int, g1 *g1, g2 *g22,
 		nonResidueInPMinus1Over2: nonResidue,
 		preferNaf:                preferNaf,
 		sixUPlus2Naf:             naf,
+		workers:                  1,
 	}
 	for i := 0; i < 16; i++ {
 		bn.t2[i] = fq2.new()
 		bn.t12[i] = fq12.new()
 	}
+	// fast subgroup tests if the order is of the family
+	g1.subgroup, g2.subgroup = bnSubgroupTest(u, uIsNegative, g1.q), bnSubgroupTest(u, uIsNegative, g2.q)
+	g2.gls = newGLS(fq12.fq6().nonResidue, twistType)
 	return bn
 }
 
+// setWorkers sets the number of goroutines that miller loop of a multi
+// pairing is split across
+func (bn *bnInstance) setWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	bn.workers = n
+}
+
+// clone returns an engine with its own scratch space for a worker
+func (bn *bnInstance) clone() *bnInstance {
+	fq12 := bn.fq12.clone()
+	c := *bn
+	c.fq12, c.g2, c.workers = fq12, bn.g2.clone(fq12.f.f), 1
+	for i := range c.t2 {
+		c.t2[i] = fq12.f.f.new()
+		c.t12[i] = fq12.new()
+	}
+	return &c
+}
+
 func (bn *bnInstance) gt() *fq12 {
 	return bn.fq12
 }
@@ -198,16 +225,17 @@ func (bn *bnInstance) additionStep(coeff *fe6C, r *pointG22, q *pointG22) {
 }
 
 func (bn *bnInstance) ell(f *fe12, coeffs *fe6C, p *pointG1) {
-	fq2 := bn.fq12.f.f
+	// coefficients are kept intact so that they can be reused
+	fq2, t := bn.fq12.f.f, bn.t2
 	switch bn.twistType {
 	case 1: // M
-		fq2.mulByFq(&coeffs[2], &coeffs[2], p[1])
-		fq2.mulByFq(&coeffs[1], &coeffs[1], p[0])
-		bn.fq12.mulBy014(f, &coeffs[0], &coeffs[1], &coeffs[2])
+		fq2.mulByFq(t[0], &coeffs[2], p[1])
+		fq2.mulByFq(t[1], &coeffs[1], p[0])
+		bn.fq12.mulBy014(f, &coeffs[0], t[1], t[0])
 	case 2: // D
-		fq2.mulByFq(&coeffs[0], &coeffs[0], p[1])
-		fq2.mulByFq(&coeffs[1], &coeffs[1], p[0])
-		bn.fq12.mulBy034(f, &coeffs[0], &coeffs[1], &coeffs[2])
+		fq2.mulByFq(t[0], &coeffs[0], p[1])
+		fq2.mulByFq(t[1], &coeffs[1], p[0])
+		bn.fq12.mulBy034(f, t[0], t[1], &coeffs[2])
 	}
 }
 
@@ -274,15 +302,41 @@ func (bn *bnInstance) prepareWithoutNaf(coeffs *[]fe6C, T, Q *pointG22, twoInv f
 	}
 }
 
+// prepareG2 returns line coefficients of miller loop for a g2 point, they
+// can be used in any number of pairings with the same engine parameters
+func (bn *bnInstance) prepareG2(Q *pointG22) ([]fe6C, bool) {
+	coeffs := make([]fe6C, bn.calculateCoeffLength())
+	if ok := bn.prepare(&coeffs, Q); !ok {
+		return nil, false
+	}
+	return coeffs, true
+}
+
 func (bn *bnInstance) millerLoop(f *fe12, g1Points []*pointG1, g2Points []*pointG22) bool {
+	if bn.workers > 1 && len(g1Points) > 1 {
+		return bn.parallelMillerLoop(f, len(g1Points), func(e *bnInstance, f *fe12, from, to int) bool {
+			return e.millerLoop(f, g1Points[from:to], g2Points[from:to])
+		})
+	}
 	coeffs := make([][]fe6C, len(g1Points))
-	coeffLength := bn.calculateCoeffLength()
-	for i, _ := range g1Points {
-		coeffs[i] = make([]fe6C, coeffLength)
-		if ok := bn.prepare(&coeffs[i], g2Points[i]); !ok {
+	for i := range g1Points {
+		var ok bool
+		if coeffs[i], ok = bn.prepareG2(g2Points[i]); !ok {
 			return false
 		}
 	}
+	bn.millerLoopPrepared(f, coeffs, g1Points)
+	return true
+}
+
+func (bn *bnInstance) millerLoopPrepared(f *fe12, coeffs [][]fe6C, g1Points []*pointG1) {
+	if bn.workers > 1 && len(g1Points) > 1 {
+		bn.parallelMillerLoop(f, len(g1Points), func(e *bnInstance, f *fe12, from, to int) bool {
+			e.millerLoopPrepared(f, coeffs[from:to], g1Points[from:to])
+			return true
+		})
+		return
+	}
 	if bn.preferNaf {
 		bn.millerLoopWithNaf(f, coeffs, g1Points)
 	} else {
@@ -293,7 +347,7 @@ func (bn *bnInstance) millerLoop(f *fe12, g1Points []*pointG1, g2Points []*point
 		bn.fq12.conjugate(f, f)
 	}
 	// Q1 = π(Q)
-	j := coeffLength - 2
+	j := bn.calculateCoeffLength() - 2
 	for k, point := range g1Points {
 		bn.ell(f, &(coeffs)[k][j], point)
 	}
@@ -302,6 +356,44 @@ func (bn *bnInstance) millerLoop(f *fe12, g1Points []*pointG1, g2Points []*point
 	for k, point := range g1Points {
 		bn.ell(f, &(coeffs)[k][j], point)
 	}
+}
+
+// parallelMillerLoop splits n pairs into chunks, runs loop for each chunk
+// on a separate engine and accumulator and multiplies partial results into f
+func (bn *bnInstance) parallelMillerLoop(f *fe12, n int, loop func(e *bnInstance, f *fe12, from, to int) bool) bool {
+	workers := bn.workers
+	if workers > n {
+		workers = n
+	}
+	size := (n + workers - 1) / workers
+	chunks := (n + size - 1) / size
+	acc, ok := make([]*fe12, chunks), make([]bool, chunks)
+	var wg sync.WaitGroup
+	for i := 0; i < chunks; i++ {
+		from, to := i*size, (i+1)*size
+		if to > n {
+			to = n
+		}
+		// first chunk goes on with f itself
+		acc[i] = f
+		if i > 0 {
+			acc[i] = bn.fq12.one()
+		}
+		wg.Add(1)
+		go func(i, from, to int) {
+			defer wg.Done()
+			ok[i] = loop(bn.clone(), acc[i], from, to)
+		}(i, from, to)
+	}
+	wg.Wait()
+	for i := range acc {
+		if !ok[i] {
+			return false
+		}
+		if i > 0 {
+			bn.fq12.mul(f, f, acc[i])
+		}
+	}
 	return true
 }
 
@@ -491,6 +583,31 @@ func (bn *bnInstance) multiPair(g1Points []*pointG1, g2Points []*pointG22) (*fe1
 	return f, true
 }
 
+// multiPairPrepared is multiPair with prepared g2 points, nil coefficients
+// stand for the point at infinity
+func (bn *bnInstance) multiPairPrepared(g1Points []*pointG1, coeffs [][]fe6C) (*fe12, bool) {
+	if len(g1Points) != len(coeffs) {
+		return nil, false
+	}
+	var _g1Points []*pointG1
+	var _coeffs [][]fe6C
+	for i := 0; i < len(g1Points); i++ {
+		if !bn.g1.isZero(g1Points[i]) && coeffs[i] != nil {
+			_g1Points = append(_g1Points, g1Points[i])
+			_coeffs = append(_coeffs, coeffs[i])
+		}
+	}
+	f := bn.fq12.one()
+	if len(_g1Points) == 0 {
+		return f, true
+	}
+	bn.millerLoopPrepared(f, _coeffs, _g1Points)
+	if ok := bn.finalExp(f); !ok {
+		return nil, false
+	}
+	return f, true
+}
+
 func (bn *bnInstance) calculateCoeffLength() int {
 	j := 0
 	if bn.preferNaf {
@@ -13,14 +13,18 @@ var fuz int = 1
 
 var targetNumberOfLimb int = -1
 
+var timing = false
+
 var from = 1
 var to = 16
 
 func TestMain(m *testing.M) {
 	_fuz := flag.Int("fuzz", 1, "# of iters")
 	nol := flag.Int("nol", 0, "backend bit size")
+	_timing := flag.Bool("timing", false, "run statistical timing tests")
 	flag.Parse()
 	fuz = *_fuz
+	timing = *_timing
 	if *nol > 0 {
 		targetNumberOfLimb = *nol
 		if !(targetNumberOfLimb >= from && targetNumberOfLimb <= to) {
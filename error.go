@@ -11,6 +11,7 @@ const (
 	ERR_MODULUS_EVEN                              = "Modulus is even"
 	ERR_MODULUS_LESS_THREE                        = "Modulus is less than 3"
 	ERR_BASE_FIELD_CONSTRUCTION                   = "Failed to create prime field from modulus"
+	ERR_SQRT_MODULUS_NOT_PRIME                    = "Square root needs a prime modulus"
 	ERR_INPUT_NOT_ENOUGH_FOR_FIELD_ELEMS          = "Input is not long enough"
 	ERR_INPUT_NOT_ENOUGH_FOR_SCAKAR               = "Input is not long enough to get scalar"
 	ERR_GROUP_ORDER_LENGTH_NOT_ENOUGH_BYTE        = "Input is not long enough to get group order length"
@@ -32,6 +33,10 @@ const (
 	ERR_G2_CANT_DECODE_EXT_DEGREE_LENGTH = "cant decode extension degree length"
 	ERR_G2_UNEXPECTED_EXT_DEGREE         = "Extension degree expected to be 2 or 3"
 	ERR_G2_UNKNOWN_OPERATION             = "Unknown G2 operation"
+	// map to curve
+	ERR_MAP_UNSUPPORTED_CURVE = "Mapping to curve is only supported for BLS12-381"
+	ERR_MAP_FIELD_ELEMENT     = "Field element is not encoded properly"
+	ERR_MAP_TO_CURVE_FAILED   = "Failed to map field element to curve"
 	// PAIRING
 	ERR_EXT_FIELD_NON_RESIDUE_FP2_ZERO         = "Non-residue for Fp2 is zero"
 	ERR_EXT_FIELD_NON_RESIDUE_FP2_RESIDUE      = "Non-residue for Fp2 is actually residue"
@@ -65,6 +70,14 @@ const (
 	ERR_PAIRING_EXP_SIGN_UNKNWON               = "Unknown parameter exp sign"
 	ERR_PAIRING_BOOL_NOT_ENOUGH_BYTE           = "Input is not long enough to get boolean"
 	ERR_PAIRING_BOOL_INVALID                   = "Boolean is not encoded properly"
+	// target group
+	ERR_GT_GROUP_MISMATCH = "Elements are of different target groups"
+	// strict decoding
+	ERR_STRICT_MODULUS_NOT_PRIME     = "Modulus is not prime"
+	ERR_STRICT_GROUP_ORDER_NOT_PRIME = "Group order is not prime"
+	ERR_STRICT_CURVE_SINGULAR        = "Curve is singular"
+	ERR_STRICT_POINT_ORDER           = "Point is not annihilated by the group order"
+	ERR_STRICT_EMBEDDING_DEGREE      = "Embedding degree does not match the pairing family"
 	// Family specific
 	ERR_BN_PAIRING_LOW_HAMMING_WEIGHT    = "|6*U + 2| has too large hamming weight"
 	ERR_BN_PAIRING_A_PARAMETER_NOT_ZERO  = "A parameter must be zero for BN curve"
@@ -128,6 +128,14 @@ func (t fqTest) inverse(c, a fieldElement) bool {
 	return t.fq.inverse(c.(fe), a.(fe))
 }
 
+func (t fqTest) batchInverse(c, a []fieldElement) bool {
+	C, A := make([]fe, len(c)), make([]fe, len(a))
+	for i := range c {
+		C[i], A[i] = c[i].(fe), a[i].(fe)
+	}
+	return t.fq.batchInverse(C, A)
+}
+
 func (t fqTest) sqrt(c, a fieldElement) bool {
 	return t.fq.sqrt(c.(fe), a.(fe))
 }
@@ -224,6 +232,14 @@ func (t fq2Test) inverse(c, a fieldElement) bool {
 	return t.fq2.inverse(c.(*fe2), a.(*fe2))
 }
 
+func (t fq2Test) batchInverse(c, a []fieldElement) bool {
+	C, A := make([]*fe2, len(c)), make([]*fe2, len(a))
+	for i := range c {
+		C[i], A[i] = c[i].(*fe2), a[i].(*fe2)
+	}
+	return t.fq2.batchInverse(C, A)
+}
+
 func (t fq2Test) mulByFq(c, a fieldElement, b fieldElement) {
 	t.fq2.mulByFq(c.(*fe2), a.(*fe2), b.(fe))
 }
@@ -324,6 +340,14 @@ func (t fq3Test) inverse(c, a fieldElement) bool {
 	return t.fq3.inverse(c.(*fe3), a.(*fe3))
 }
 
+func (t fq3Test) batchInverse(c, a []fieldElement) bool {
+	C, A := make([]*fe3, len(c)), make([]*fe3, len(a))
+	for i := range c {
+		C[i], A[i] = c[i].(*fe3), a[i].(*fe3)
+	}
+	return t.fq3.batchInverse(C, A)
+}
+
 func (t fq3Test) mulByFq(c, a fieldElement, b fieldElement) {
 	t.fq3.mulByFq(c.(*fe3), a.(*fe3), b.(fe))
 }
@@ -799,6 +823,11 @@ func BenchmarkField(t *testing.B) {
 			field.mul(c, a, b)
 		}
 	})
+	t.Run(fmt.Sprintf("%d_square", bitSize), func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			field.square(c, a)
+		}
+	})
 	t.Run(fmt.Sprintf("%d_cmp", bitSize), func(t *testing.B) {
 		for i := 0; i < t.N; i++ {
 			field.cmp(a, b)
@@ -1419,45 +1448,303 @@ func TestFqInversion(t *testing.T) {
 	}
 }
 
-// func TestFqSquareRoot(t *testing.T) {
-// 	fields = []string{"FQ"}
-// 	for _, ext := range fields {
-// 		for limbSize := from; limbSize < 4; limbSize++ {
-// 			// for limbSize := from; limbSize < to+1; limbSize++ {
-// 			t.Run(fmt.Sprintf("%d_%s", limbSize*64, ext), func(t *testing.T) {
-// 				for i := 0; i < fuz; i++ {
-// 					field := randField(ext, limbSize)
-// 					u := field.new()
-// 					zero := field.zero()
-// 					one := field.one()
-// 					field.sqrt(u, zero)
-// 					if !field.equal(u, zero) {
-// 						t.Errorf("(0^(1/2)) == 0)")
-// 					}
-// 					field.sqrt(u, one)
-// 					if !field.equal(u, one) {
-// 						// t.Errorf("(1^(1/2)) == 1)")
-// 					}
-// 					v, w, negA := field.new(), field.new(), field.new()
-// 					a := field.rand(rand.Reader)
-// 					field.neg(negA, a)
-// 					field.square(u, a)
-// 					field.square(w, negA)
-// 					if !field.equal(w, u) {
-// 						// TODO: why fails?
-// 						// t.Errorf("square of r and -r is not same")
-// 					}
-// 					if hasRoot := field.sqrt(v, u); !hasRoot {
-// 						// t.Errorf("elem has no square-root")
-// 					}
-// 					if !field.equal(a, v) && !field.equal(negA, v) {
-// 						field.debugElement(a)
-// 						field.debugElement(negA)
-// 						field.debugElement(v)
-// 						// t.Errorf("((r)^2)^(1/2) == r)")
-// 					}
-// 				}
-// 			})
-// 		}
-// 	}
-// }
+func TestFqBatchInversion(t *testing.T) {
+	for _, ext := range []string{"FQ", "FQ2", "FQ3"} {
+		for limbSize := from; limbSize < to+1; limbSize++ {
+			t.Run(fmt.Sprintf("%d_%s", limbSize*64, ext), func(t *testing.T) {
+				for i := 0; i < fuz; i++ {
+					field := randField(ext, limbSize)
+					batch := field.(interface {
+						batchInverse(c, a []fieldElement) bool
+					})
+					n := 1 + i%9
+					in, inv := make([]fieldElement, n), make([]fieldElement, n)
+					for j := range in {
+						in[j], inv[j] = field.rand(rand.Reader), field.new()
+					}
+					// zeros are skipped
+					in[i%n] = field.zero()
+					if !batch.batchInverse(inv, in) {
+						t.Fatalf("batch inversion failed")
+					}
+					u := field.new()
+					for j := range in {
+						field.inverse(u, in[j])
+						if !field.equal(u, inv[j]) {
+							t.Fatalf("batch inverse does not match a^-1")
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// randFqMod4 returns a random field with p = r mod 4
+func randFqMod4(limbSize int, r int64) *fq {
+	for {
+		field := randFq(limbSize)
+		if new(big.Int).Mod(field.pbig, big.NewInt(4)).Int64() == r {
+			return field
+		}
+	}
+}
+
+func TestFqSquareRoot(t *testing.T) {
+	for _, r := range []int64{1, 3} {
+		for limbSize := from; limbSize < to+1; limbSize++ {
+			t.Run(fmt.Sprintf("%d_%d_mod_4", limbSize*64, r), func(t *testing.T) {
+				for i := 0; i < fuz; i++ {
+					field := randFqMod4(limbSize, r)
+					u, v, negA := field.new(), field.new(), field.new()
+					if !field.sqrt(u, field.zero) || !field.isZero(u) {
+						t.Fatalf("(0^(1/2)) == 0")
+					}
+					a := field.rand(rand.Reader)
+					field.neg(negA, a)
+					field.square(u, a)
+					if !field.sqrt(v, u) {
+						t.Fatalf("a^2 has no square root")
+					}
+					if !field.equal(a, v) && !field.equal(negA, v) {
+						t.Fatalf("((a)^2)^(1/2) == +-a")
+					}
+					// z^q is a non residue since q is odd, it is -1 for s = 1
+					nonResidue := field.new()
+					field.neg(nonResidue, field.one)
+					if _, rootOfUnity, _ := field.sqrtParams(); rootOfUnity != nil {
+						field.copy(nonResidue, rootOfUnity)
+					}
+					field.mul(u, u, nonResidue)
+					if !field.isZero(u) && field.sqrt(v, u) {
+						t.Fatalf("non residue has a square root")
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestFqSquareRootParams(t *testing.T) {
+	// p = 3 mod 4 needs no non residue
+	field := randFqMod4(6, 3)
+	if _, rootOfUnity, err := field.sqrtParams(); err != nil || field.s != 1 || rootOfUnity != nil {
+		t.Fatalf("non residue search for s = 1")
+	}
+	// p = 1 mod 8 and p = 1 mod l for odd primes l < 256 makes every
+	// element up to 256 a residue by quadratic reciprocity
+	m := big.NewInt(8)
+	for l := int64(3); l < 256; l += 2 {
+		if big.NewInt(l).ProbablyPrime(0) {
+			m.Mul(m, big.NewInt(l))
+		}
+	}
+	p := new(big.Int).Add(m, big.NewInt(1))
+	for !p.ProbablyPrime(20) {
+		p.Add(p, m)
+	}
+	for z := int64(2); z <= 256; z++ {
+		if big.Jacobi(big.NewInt(z), p) != 1 {
+			t.Fatalf("crafted prime has a small non residue %d", z)
+		}
+	}
+	field, err := newField(padBytes(p.Bytes(), 48))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < fuz; i++ {
+		a, u, negA := field.rand(rand.Reader), field.new(), field.new()
+		field.neg(negA, a)
+		field.square(u, a)
+		if !field.sqrt(u, u) || (!field.equal(u, a) && !field.equal(u, negA)) {
+			t.Fatalf("square root with a large least non residue")
+		}
+	}
+	// every element is a quadratic residue modulo a square in jacobi sense
+	q := randFqMod4(3, 1).modulus()
+	field, err = newField(padBytes(q.Mul(q, q).Bytes(), 48))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.sqrtPower != nil {
+		t.Fatalf("square root parameters are calculated in field construction")
+	}
+	a := field.rand(rand.Reader)
+	field.square(a, a)
+	if _, _, err := field.sqrtParams(); err == nil || err.Error() != ERR_SQRT_MODULUS_NOT_PRIME {
+		t.Fatalf("composite modulus is expected to fail")
+	}
+	if field.sqrt(a, a) {
+		t.Fatalf("square root with composite modulus")
+	}
+	// compressed decoding reports the composite modulus
+	g, err := newG1(field, field.zero, field.one, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := make([]byte, 1+field.byteSize())
+	in[0], in[len(in)-1] = compressedSmall, 1
+	if _, err := g.fromBytesCompressed(in); err == nil || err.Error() != ERR_SQRT_MODULUS_NOT_PRIME {
+		t.Fatalf("composite modulus is expected to fail in decompression")
+	}
+}
+
+func TestFq2SquareRoot(t *testing.T) {
+	for _, r := range []int64{1, 3} {
+		for limbSize := from; limbSize < to+1; limbSize++ {
+			t.Run(fmt.Sprintf("%d_%d_mod_4", limbSize*64, r), func(t *testing.T) {
+				for i := 0; i < fuz; i++ {
+					fq := randFqMod4(limbSize, r)
+					fq2, err := newFq2(fq, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+					// -1 is a non residue for p = 3 mod 4
+					if r == 3 && i%2 == 0 {
+						fq.neg(fq2.nonResidue, fq.one)
+					} else {
+						for {
+							k := fq.rand(rand.Reader)
+							if !fq.isZero(k) && fq.isNonResidue(k, 2) {
+								fq.copy(fq2.nonResidue, k)
+								break
+							}
+						}
+					}
+					fq2.calculateFrobeniusCoeffs()
+					u, v, negA := fq2.new(), fq2.new(), fq2.new()
+					if !fq2.sqrt(u, fq2.zero()) || !fq2.isZero(u) {
+						t.Fatalf("(0^(1/2)) == 0")
+					}
+					for _, a := range []*fe2{
+						fq2.rand(rand.Reader),
+						{fq.rand(rand.Reader), fq.new()},
+						{fq.new(), fq.rand(rand.Reader)},
+					} {
+						fq2.neg(negA, a)
+						fq2.square(u, a)
+						if !fq2.sqrt(v, u) {
+							t.Fatalf("a^2 has no square root")
+						}
+						if !fq2.equal(a, v) && !fq2.equal(negA, v) {
+							t.Fatalf("((a)^2)^(1/2) == +-a")
+						}
+					}
+					// n^((p^2-1)/2) = -1 for a non residue
+					e := new(big.Int).Mul(fq.pbig, fq.pbig)
+					e.Rsh(e.Sub(e, big.NewInt(1)), 1)
+					var n *fe2
+					for {
+						n = fq2.rand(rand.Reader)
+						fq2.exp(u, n, e)
+						if !fq2.isOne(u) && !fq2.isZero(n) {
+							break
+						}
+					}
+					fq2.square(u, fq2.rand(rand.Reader))
+					fq2.mul(u, u, n)
+					if !fq2.isZero(u) && fq2.sqrt(v, u) {
+						t.Fatalf("non residue has a square root")
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestFq3SquareRoot(t *testing.T) {
+	for _, r := range []int64{1, 3} {
+		for limbSize := from; limbSize < to+1; limbSize++ {
+			t.Run(fmt.Sprintf("%d_%d_mod_4", limbSize*64, r), func(t *testing.T) {
+				for i := 0; i < fuz; i++ {
+					// cubic non residue exists for p = 1 mod 3
+					fq := randFqMod4(limbSize, r)
+					for new(big.Int).Mod(fq.pbig, big.NewInt(3)).Int64() != 1 {
+						fq = randFqMod4(limbSize, r)
+					}
+					fq3, err := newFq3(fq, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for {
+						k := fq.rand(rand.Reader)
+						if !fq.isZero(k) && fq.isNonResidue(k, 3) {
+							fq.copy(fq3.nonResidue, k)
+							break
+						}
+					}
+					u, v, negA := fq3.new(), fq3.new(), fq3.new()
+					if !fq3.sqrt(u, fq3.zero()) || !fq3.isZero(u) {
+						t.Fatalf("(0^(1/2)) == 0")
+					}
+					a := fq3.rand(rand.Reader)
+					fq3.neg(negA, a)
+					fq3.square(u, a)
+					if !fq3.sqrt(v, u) {
+						t.Fatalf("a^2 has no square root")
+					}
+					if !fq3.equal(a, v) && !fq3.equal(negA, v) {
+						t.Fatalf("((a)^2)^(1/2) == +-a")
+					}
+					// non residue of base field is a non residue in odd degree extension
+					nonResidue := fq.new()
+					fq.neg(nonResidue, fq.one)
+					if _, rootOfUnity, _ := fq.sqrtParams(); rootOfUnity != nil {
+						fq.copy(nonResidue, rootOfUnity)
+					}
+					fq3.mulByFq(u, u, nonResidue)
+					if !fq3.isZero(u) && fq3.sqrt(v, u) {
+						t.Fatalf("non residue has a square root")
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestFqConstantTime(t *testing.T) {
+	for limbSize := from; limbSize < to+1; limbSize++ {
+		t.Run(fmt.Sprintf("%d", limbSize*64), func(t *testing.T) {
+			for i := 0; i < fuz; i++ {
+				field := randFq2(limbSize)
+				fq := field.fq()
+				a, b := fq.rand(rand.Reader), fq.rand(rand.Reader)
+				u, v := fq.new(), fq.new()
+				fq.inverse(u, a)
+				fq.inverseCT(v, a)
+				if !fq.equal(u, v) {
+					t.Fatalf("a^(p-2) == a^-1")
+				}
+				fq.inverseCT(v, fq.zero)
+				if !fq.isZero(v) {
+					t.Fatalf("0^(p-2) == 0")
+				}
+				fq.copy(u, a)
+				fq.cmov(u, b, false)
+				if !fq.equal(u, a) {
+					t.Fatalf("bad conditional move")
+				}
+				fq.cmov(u, b, true)
+				if !fq.equal(u, b) {
+					t.Fatalf("bad conditional move")
+				}
+				fq.copy(u, a)
+				fq.copy(v, b)
+				fq.cswap(u, v, false)
+				if !fq.equal(u, a) || !fq.equal(v, b) {
+					t.Fatalf("bad conditional swap")
+				}
+				fq.cswap(u, v, true)
+				if !fq.equal(u, b) || !fq.equal(v, a) {
+					t.Fatalf("bad conditional swap")
+				}
+				a2, u2, v2 := field.rand(rand.Reader), field.new(), field.new()
+				field.inverse(u2, a2)
+				field.inverseCT(v2, a2)
+				if !field.equal(u2, v2) {
+					t.Fatalf("bad constant time inversion")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package eip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// fixedBaseMaxWindow bounds a window of the table to 2^15 points
+const fixedBaseMaxWindow = 16
+
+// FixedBaseTable holds multiples of a base point so that multiplying it
+// takes no doublings. Scalars are recoded into signed digits of window bits
+// and for each digit position i the table has [j * 2^(window * i)]P for
+// j = 1 ... 2^(window - 1) in affine form, multiplication is a mixed addition
+// per non zero digit. Constant time variant scans whole rows for secret
+// scalars. A FixedBaseTable is immutable after it is built and is safe for
+// concurrent use. Building a table of a large window is costly, so a table
+// can be persisted with Bytes and reloaded with FromBytes.
+type FixedBaseTable struct {
+	window     uint
+	numWindows int
+	// []*pointG1, []*pointG22 or []*pointG23, points at infinity are zero
+	points interface{}
+	g      fixedBaseCurve
+}
+
+// fixedBaseCurve is the group that a table is built on, it is one of g1, g22
+// and g23
+type fixedBaseCurve interface {
+	fixedBaseTableToBytes(t *FixedBaseTable) []byte
+	fixedBaseTableFromBytes(in []byte) (*FixedBaseTable, error)
+}
+
+// Bytes encodes the window size in a byte and the number of windows in two
+// bytes followed by the points of the table in uncompressed form, points at
+// infinity are all zeros.
+func (t *FixedBaseTable) Bytes() []byte {
+	return t.g.fixedBaseTableToBytes(t)
+}
+
+// FromBytes decodes a table of the same group as t in the encoding of Bytes.
+// Points are checked to be on the curve but not to be multiples of the base
+// point, so in is expected to come from a trusted source.
+func (t *FixedBaseTable) FromBytes(in []byte) (*FixedBaseTable, error) {
+	return t.g.fixedBaseTableFromBytes(in)
+}
+
+// fixedBaseWindows returns the number of signed digits of window bits for
+// scalars up to bits, top digit takes the carry
+func fixedBaseWindows(bits int, window uint) (int, error) {
+	if window == 0 || window > fixedBaseMaxWindow {
+		return 0, fmt.Errorf("window size should be between 1 and %d", fixedBaseMaxWindow)
+	}
+	if bits <= 0 {
+		return 0, fmt.Errorf("scalar size should be positive")
+	}
+	n := bits/int(window) + 1
+	if n > 0xffff {
+		return 0, fmt.Errorf("scalar size is too large for window size %d", window)
+	}
+	return n, nil
+}
+
+// size is the number of multiples for a digit position
+func (t *FixedBaseTable) size() int {
+	return 1 << (t.window - 1)
+}
+
+// digits recodes e into digits in [-2^(window - 1), 2^(window - 1)], it
+// returns false if e does not fit into the table
+func (t *FixedBaseTable) digits(e *big.Int) ([]int64, bool) {
+	if e.Sign() < 0 || e.BitLen() > t.numWindows*int(t.window) {
+		return nil, false
+	}
+	k := new(big.Int).Set(e)
+	max, half := int64(1)<<t.window, int64(1)<<(t.window-1)
+	mask := uint64(max - 1)
+	out := make([]int64, t.numWindows)
+	var carry int64
+	for i := range out {
+		d := int64(k.Uint64()&mask) + carry
+		carry = 0
+		if d > half {
+			d -= max
+			carry = 1
+		}
+		out[i] = d
+		k.Rsh(k, t.window)
+	}
+	return out, carry == 0
+}
+
+// digitsCT recodes e as digits does but without branching on its bits, e is
+// expected to fit into the table
+func (t *FixedBaseTable) digitsCT(e *big.Int) []int64 {
+	half := int64(1) << (t.window - 1)
+	out := make([]int64, t.numWindows)
+	var carry int64
+	for i := range out {
+		d := carry
+		for j := 0; j < int(t.window); j++ {
+			d += int64(e.Bit(i*int(t.window)+j)) << uint(j)
+		}
+		// carry is one if d > 2^(window - 1)
+		carry = int64(uint64(half-d) >> 63)
+		out[i] = d - carry<<t.window
+	}
+	return out
+}
+
+// fixedBaseTableHeader encodes the window size in a byte followed by the
+// number of windows in two bytes
+func fixedBaseTableHeader(t *FixedBaseTable, pointSize int) []byte {
+	out := make([]byte, 3, 3+t.numWindows*t.size()*pointSize)
+	out[0] = byte(t.window)
+	binary.BigEndian.PutUint16(out[1:], uint16(t.numWindows))
+	return out
+}
+
+// parseFixedBaseTableHeader returns an empty table with the window size and
+// the number of windows of the header and the encoded points
+func parseFixedBaseTableHeader(in []byte, pointSize int) (*FixedBaseTable, []byte, error) {
+	if len(in) < 3 {
+		return nil, nil, fmt.Errorf("input is too short for fixed base table")
+	}
+	t := &FixedBaseTable{window: uint(in[0]), numWindows: int(binary.BigEndian.Uint16(in[1:]))}
+	if t.window == 0 || t.window > fixedBaseMaxWindow || t.numWindows == 0 {
+		return nil, nil, fmt.Errorf("bad fixed base table header")
+	}
+	if len(in)-3 != t.numWindows*t.size()*pointSize {
+		return nil, nil, fmt.Errorf("fixed base table should be %d bytes given %d", 3+t.numWindows*t.size()*pointSize, len(in))
+	}
+	return t, in[3:], nil
+}
+
+func isAllZeros(in []byte) bool {
+	for _, b := range in {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
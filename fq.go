@@ -3,10 +3,12 @@ package eip
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"reflect"
+	"sync"
 	"unsafe"
 
 	"golang.org/x/sys/cpu"
@@ -35,6 +37,7 @@ type fq struct {
 	cmp            func(a, b fe) int8
 	copy           func(dst, stc fe)
 	_mul           func(c, a, b, p fe, inp uint64)
+	_square        func(c, a, p fe, inp uint64)
 	_add           func(c, a, b, p fe)
 	_double        func(c, a, p fe)
 	_sub           func(c, a, b, p fe)
@@ -43,6 +46,13 @@ type fq struct {
 	subn           func(a, b fe) uint64
 	div_two        func(a fe)
 	mul_two        func(a fe)
+
+	// p - 1 = 2^s * q, for square root
+	s           int
+	sqrtOnce    sync.Once
+	sqrtPower   *big.Int // (q - 1) / 2
+	rootOfUnity fe       // z^q for a non residue z
+	sqrtErr     error
 }
 
 func newField(p []byte) (*fq, error) {
@@ -86,8 +96,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_1
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_1
+			f._square = square_no_adx_bmi2_1
 		} else {
 			f._mul = mul1
+			f._square = square1
 		}
 	case 2:
 		f.equal = eq2
@@ -103,8 +115,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_2
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_2
+			f._square = square_no_adx_bmi2_2
 		} else {
 			f._mul = mul2
+			f._square = square2
 		}
 	case 3:
 		f.equal = eq3
@@ -120,8 +134,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_3
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_3
+			f._square = square_no_adx_bmi2_3
 		} else {
 			f._mul = mul3
+			f._square = square3
 		}
 	case 4:
 		f.equal = eq4
@@ -137,8 +153,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_4
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_4
+			f._square = square_no_adx_bmi2_4
 		} else {
 			f._mul = mul4
+			f._square = square4
 		}
 	case 5:
 		f.equal = eq5
@@ -154,8 +172,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_5
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_5
+			f._square = square_no_adx_bmi2_5
 		} else {
 			f._mul = mul5
+			f._square = square5
 		}
 	case 6:
 		f.equal = eq6
@@ -172,8 +192,10 @@ func newField(p []byte) (*fq, error) {
 		f._mul = mul6
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_6
+			f._square = square_no_adx_bmi2_6
 		} else {
 			f._mul = mul6
+			f._square = square6
 		}
 	case 7:
 		f.equal = eq7
@@ -189,8 +211,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_7
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_7
+			f._square = square_no_adx_bmi2_7
 		} else {
 			f._mul = mul7
+			f._square = square7
 		}
 	case 8:
 		f.equal = eq8
@@ -206,8 +230,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_8
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_8
+			f._square = square_no_adx_bmi2_8
 		} else {
 			f._mul = mul8
+			f._square = square8
 		}
 	case 9:
 		f.equal = eq9
@@ -223,8 +249,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_9
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_9
+			f._square = square_no_adx_bmi2_9
 		} else {
 			f._mul = mul9
+			f._square = square9
 		}
 	case 10:
 		f.equal = eq10
@@ -240,8 +268,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_10
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_10
+			f._square = square_no_adx_bmi2_10
 		} else {
 			f._mul = mul10
+			f._square = square10
 		}
 	case 11:
 		f.equal = eq11
@@ -257,8 +287,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_11
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_11
+			f._square = square_no_adx_bmi2_11
 		} else {
 			f._mul = mul11
+			f._square = square11
 		}
 	case 12:
 		f.equal = eq12
@@ -274,8 +306,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_12
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_12
+			f._square = square_no_adx_bmi2_12
 		} else {
 			f._mul = mul12
+			f._square = square12
 		}
 	case 13:
 		f.equal = eq13
@@ -291,8 +325,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_13
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_13
+			f._square = square_no_adx_bmi2_13
 		} else {
 			f._mul = mul13
+			f._square = square13
 		}
 	case 14:
 		f.equal = eq14
@@ -308,8 +344,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_14
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_14
+			f._square = square_no_adx_bmi2_14
 		} else {
 			f._mul = mul14
+			f._square = square14
 		}
 	case 15:
 		f.equal = eq15
@@ -325,8 +363,10 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_15
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_15
+			f._square = square_no_adx_bmi2_15
 		} else {
 			f._mul = mul15
+			f._square = square15
 		}
 	case 16:
 		f.equal = eq16
@@ -342,15 +382,56 @@ func newField(p []byte) (*fq, error) {
 		f.mul_two = mul_two_16
 		if nonADXBMI2 {
 			f._mul = mul_no_adx_bmi2_16
+			f._square = square_no_adx_bmi2_16
 		} else {
 			f._mul = mul16
+			f._square = square16
 		}
 	default:
 		return nil, fmt.Errorf("limb size %d is not implemented", f.limbSize)
 	}
+	// 2-adicity of p - 1
+	pMinusOne := new(big.Int).Sub(f.pbig, big.NewInt(1))
+	for f.s < pMinusOne.BitLen() && pMinusOne.Bit(f.s) == 0 {
+		f.s++
+	}
 	return f, nil
 }
 
+// sqrtParams returns (q - 1) / 2 and z^q for p - 1 = 2^s * q. They are
+// calculated at the first square root rather than in newField, since the
+// search for a non residue is not covered by price of an operation. Root of
+// unity is nil if s = 1. Square roots are only defined for a prime modulus,
+// so it fails for a composite one.
+func (f *fq) sqrtParams() (*big.Int, fe, error) {
+	f.sqrtOnce.Do(f.calculateSqrtParams)
+	return f.sqrtPower, f.rootOfUnity, f.sqrtErr
+}
+
+func (f *fq) calculateSqrtParams() {
+	// modulus is not required to be prime, search below
+	// would not terminate for a square modulus
+	if !f.pbig.ProbablyPrime(0) {
+		f.sqrtErr = errors.New(ERR_SQRT_MODULUS_NOT_PRIME)
+		return
+	}
+	q := new(big.Int).Sub(f.pbig, big.NewInt(1))
+	q.Rsh(q, uint(f.s))
+	f.sqrtPower = new(big.Int).Rsh(q, 1)
+	if f.s == 1 {
+		return
+	}
+	// half of the elements are non residues for a prime modulus,
+	// least one can still be made large on purpose so search is not bounded
+	z := big.NewInt(2)
+	for big.Jacobi(z, f.pbig) != -1 {
+		z.Add(z, big.NewInt(1))
+	}
+	f.rootOfUnity = f.new()
+	f.toMont(f.rootOfUnity, newFieldElementFromBigUnchecked(f.limbSize, z))
+	f.exp(f.rootOfUnity, f.rootOfUnity, q)
+}
+
 func (f *fq) toMont(c, a fe) {
 	f._mul(c, a, f.r2, f.p, f.inp)
 }
@@ -384,14 +465,14 @@ func (f *fq) mul(c, a, b fe) {
 }
 
 func (f *fq) square(c, a fe) {
-	f._mul(c, a, a, f.p, f.inp)
+	f._square(c, a, f.p, f.inp)
 }
 
 func (f *fq) exp(c, a fe, e *big.Int) {
 	z := f.new()
 	f.copy(z, f.r)
 	for i := e.BitLen(); i >= 0; i-- {
-		f.mul(z, z, z)
+		f.square(z, z)
 		if e.Bit(i) == 1 {
 			f.mul(z, z, a)
 		}
@@ -399,26 +480,46 @@ func (f *fq) exp(c, a fe, e *big.Int) {
 	f.copy(c, z)
 }
 
+// sqrt is tonelli shanks, with s = 1 it is the same as a^((p+1)/4)
 func (f *fq) sqrt(c, a fe) bool {
-	negOne, tmp, b := f.new(), f.new(), f.new()
-	f.neg(negOne, f.one)
-
-	// power = (p-3)/4
-	power := new(big.Int).Rsh(f.pbig, 2)
-	// b = a^((p-3)/4)
-	f.exp(b, a, power)
-	// tmp = b^2 = a^(p-3/2) = a^-1 * a^(p-1/2)
-	// if a is a square then a^(p-1/2) is 1 else -1
-	// so tmp = a^-1 or -a^-1
-	f.square(tmp, b)
-	// check that a*a^-1 is -1
-	f.mul(tmp, tmp, a)
-	if f.equal(tmp, negOne) {
+	if f.isZero(a) {
 		f.copy(c, f.zero)
+		return true
+	}
+	sqrtPower, rootOfUnity, err := f.sqrtParams()
+	if err != nil {
 		return false
 	}
-	// c = a^(p-3/4) * a = a^(p+1/4)
-	f.mul(c, b, a)
+	w, x, b, z, t := f.new(), f.new(), f.new(), f.new(), f.new()
+	// w = a^((q-1)/2), x = a^((q+1)/2), b = a^q
+	f.exp(w, a, sqrtPower)
+	f.mul(x, a, w)
+	f.mul(b, x, w)
+	if f.s > 1 {
+		f.copy(z, rootOfUnity)
+	}
+	v := f.s
+	for !f.isOne(b) {
+		// find least k that b^(2^k) = 1
+		k := 0
+		f.copy(t, b)
+		for ; k < v && !f.isOne(t); k++ {
+			f.square(t, t)
+		}
+		if k == v {
+			f.copy(c, f.zero)
+			return false
+		}
+		f.copy(t, z)
+		for i := 0; i < v-k-1; i++ {
+			f.square(t, t)
+		}
+		f.square(z, t)
+		f.mul(x, x, t)
+		f.mul(b, b, z)
+		v = k
+	}
+	f.copy(c, x)
 	return true
 }
 
@@ -428,6 +529,13 @@ func (f *fq) sign(fe fe) int8 {
 	return f.cmp(fe, neg)
 }
 
+// sgn0 is the parity of canonical representation as in rfc 9380
+func (f *fq) sgn0(a fe) bool {
+	t := f.new()
+	f.fromMont(t, a)
+	return !is_even(t)
+}
+
 func (f *fq) isOne(fe fe) bool {
 	return f.equal(fe, f.one)
 }
@@ -846,3 +954,53 @@ func (f *fq) inverse(inv, e fe) bool {
 	f.toMont(inv, u)
 	return true
 }
+
+// inverseCT is inversion with fermat's little theorem, a^(p-2). exponent
+// is public so it does not branch on a. zero is mapped to zero
+func (f *fq) inverseCT(inv, a fe) {
+	f.exp(inv, a, new(big.Int).Sub(f.pbig, big.NewInt(2)))
+}
+
+// batchInverse sets inv[i] to the inverse of in[i] with a single inversion
+// using montgomery's trick. Zero elements are mapped to zero. inv is used
+// for prefix products so it should not overlap with in.
+func (f *fq) batchInverse(inv, in []fe) bool {
+	acc, t := f.new(), f.new()
+	f.copy(acc, f.one)
+	for i := range in {
+		if !f.isZero(in[i]) {
+			f.mul(acc, acc, in[i])
+		}
+		f.copy(inv[i], acc)
+	}
+	if ok := f.inverse(acc, acc); !ok {
+		for i := range in {
+			f.copy(inv[i], f.zero)
+		}
+		return false
+	}
+	for i := len(in) - 1; i >= 0; i-- {
+		if f.isZero(in[i]) {
+			f.copy(inv[i], f.zero)
+			continue
+		}
+		if i > 0 {
+			f.mul(t, acc, inv[i-1])
+		} else {
+			f.copy(t, acc)
+		}
+		f.mul(acc, acc, in[i])
+		f.copy(inv[i], t)
+	}
+	return true
+}
+
+// cmov sets c to a if cond is true, without branching
+func (f *fq) cmov(c, a fe, cond bool) {
+	cmovGeneric(limbs(c, f.limbSize), limbs(a, f.limbSize), ctMask(cond))
+}
+
+// cswap swaps a and b if cond is true, without branching
+func (f *fq) cswap(a, b fe, cond bool) {
+	cswapGeneric(limbs(a, f.limbSize), limbs(b, f.limbSize), ctMask(cond))
+}
@@ -37,6 +37,23 @@ func newFq12(fq6 *fq6C, nonResidueBuf []byte) (*fq12, error) {
 	return &fq12{fq6, nonResidue, t, t2, nil}, nil
 }
 
+// clone returns a tower that shares parameters with fq12 and has its own
+// scratch space, so that both can be used concurrently
+func (fq12 *fq12) clone() *fq12 {
+	fq6 := fq12.f.clone()
+	t := make([]*fe6C, len(fq12.t))
+	for i := range t {
+		t[i] = fq6.new()
+	}
+	t2 := make([]*fe2, len(fq12.t2))
+	for i := range t2 {
+		t2[i] = fq6.f.new()
+	}
+	c := *fq12
+	c.f, c.t, c.t2 = fq6, t, t2
+	return &c
+}
+
 func (fq12 *fq12) new() *fe12 {
 	return fq12.zero()
 }
@@ -31,6 +31,19 @@ func newFq2(fq *fq, nonResidueBuf []byte) (*fq2, error) {
 	return &fq2{fq, nonResidue, t, nil}, nil
 }
 
+// clone returns a tower that shares parameters with f and has its own
+// scratch space, so that both can be used concurrently
+func (f *fq2) clone() *fq2 {
+	fq := f.fq()
+	t := make([]fe, len(f.t))
+	for i := range t {
+		t[i] = fq.new()
+	}
+	c := *f
+	c.t = t
+	return &c
+}
+
 func (f *fq2) byteSize() int {
 	fq := f.fq()
 	return fq.byteSize() * 2
@@ -242,6 +255,63 @@ func (f *fq2) inverse(c, a *fe2) bool {
 	return true
 }
 
+// inverseCT is the inversion with constant time inversion of the norm
+func (f *fq2) inverseCT(c, a *fe2) {
+	fq, t := f.fq(), f.t
+	fq.square(t[0], a[0])
+	fq.square(t[1], a[1])
+	f.mulByNonResidue(t[1], t[1])
+	fq.sub(t[1], t[0], t[1])
+	fq.inverseCT(t[0], t[1])
+	fq.mul(c[0], a[0], t[0])
+	fq.mul(t[0], a[1], t[0])
+	fq.sub(c[1], fq.zero, t[0])
+}
+
+// batchInverse sets inv[i] to the inverse of in[i] with a single inversion,
+// zero elements are mapped to zero. inv should not overlap with in.
+func (f *fq2) batchInverse(inv, in []*fe2) bool {
+	acc, t := f.one(), f.new()
+	for i := range in {
+		if !f.isZero(in[i]) {
+			f.mul(acc, acc, in[i])
+		}
+		f.copy(inv[i], acc)
+	}
+	if ok := f.inverse(acc, acc); !ok {
+		for i := range in {
+			f.copy(inv[i], f.zero())
+		}
+		return false
+	}
+	for i := len(in) - 1; i >= 0; i-- {
+		if f.isZero(in[i]) {
+			f.copy(inv[i], f.zero())
+			continue
+		}
+		if i > 0 {
+			f.mul(t, acc, inv[i-1])
+		} else {
+			f.copy(t, acc)
+		}
+		f.mul(acc, acc, in[i])
+		f.copy(inv[i], t)
+	}
+	return true
+}
+
+func (f *fq2) cmov(c, a *fe2, cond bool) {
+	fq := f.fq()
+	fq.cmov(c[0], a[0], cond)
+	fq.cmov(c[1], a[1], cond)
+}
+
+func (f *fq2) cswap(a, b *fe2, cond bool) {
+	fq := f.fq()
+	fq.cswap(a[0], b[0], cond)
+	fq.cswap(a[1], b[1], cond)
+}
+
 func (f *fq2) exp(c, a *fe2, e *big.Int) {
 	z := f.one()
 	found := false
@@ -259,6 +329,12 @@ func (f *fq2) exp(c, a *fe2, e *big.Int) {
 }
 
 func (f *fq2) sqrt(c, a *fe2) bool {
+	fq := f.fq()
+	minusOne := fq.new()
+	fq.neg(minusOne, fq.one)
+	if fq.s != 1 || !fq.equal(f.nonResidue, minusOne) {
+		return f.sqrtGeneric(c, a)
+	}
 	// if p = 3 mod 4 then we can simply use righ-shift
 	// for division by multiples of two in order to get (p-3/4)
 
@@ -269,8 +345,9 @@ func (f *fq2) sqrt(c, a *fe2) bool {
 
 	f.square(tmp, b)
 	f.mul(tmp, tmp, a)
+	// frobenius map is conjugation for u^2 = -1
 	a0 := f.new()
-	f.frobeniusMap(a0, tmp, 1)
+	f.conjugate(a0, tmp)
 	f.mul(a0, a0, tmp)
 
 	f.neg(negOne, f.one())
@@ -294,6 +371,56 @@ func (f *fq2) sqrt(c, a *fe2) bool {
 	return true
 }
 
+// sqrtGeneric finds square root with norm and square roots in base field
+// for u^2 = nonResidue and any odd p
+func (f *fq2) sqrtGeneric(c, a *fe2) bool {
+	fq := f.fq()
+	t := f.t
+	if fq.isZero(a[1]) {
+		if fq.sqrt(t[0], a[0]) {
+			fq.copy(c[0], t[0])
+			fq.copy(c[1], fq.zero)
+			return true
+		}
+		// (c1 * u)^2 = c1^2 * nonResidue = a0
+		fq.inverse(t[0], f.nonResidue)
+		fq.mul(t[0], t[0], a[0])
+		if !fq.sqrt(t[0], t[0]) {
+			return false
+		}
+		fq.copy(c[0], fq.zero)
+		fq.copy(c[1], t[0])
+		return true
+	}
+	// a0^2 - nonResidue * a1^2 should be a square in base field
+	fq.square(t[0], a[0])
+	fq.square(t[1], a[1])
+	fq.mul(t[1], t[1], f.nonResidue)
+	fq.sub(t[0], t[0], t[1])
+	if !fq.sqrt(t[0], t[0]) {
+		return false
+	}
+	// c0 = sqrt((a0 +- sqrt(norm)) / 2)
+	twoInv := fq.new()
+	fq.double(twoInv, fq.one)
+	fq.inverse(twoInv, twoInv)
+	fq.add(t[1], a[0], t[0])
+	fq.mul(t[1], t[1], twoInv)
+	if !fq.sqrt(t[2], t[1]) {
+		fq.sub(t[1], a[0], t[0])
+		fq.mul(t[1], t[1], twoInv)
+		if !fq.sqrt(t[2], t[1]) {
+			return false
+		}
+	}
+	// c1 = a1 / 2c0
+	fq.double(t[3], t[2])
+	fq.inverse(t[3], t[3])
+	fq.mul(c[1], a[1], t[3])
+	fq.copy(c[0], t[2])
+	return true
+}
+
 func (f *fq2) sign(fe *fe2) int8 {
 	neg := f.new()
 	f.neg(neg, fe)
@@ -301,6 +428,11 @@ func (f *fq2) sign(fe *fe2) int8 {
 	return f.cmp(fe, neg)
 }
 
+func (f *fq2) sgn0(a *fe2) bool {
+	fq := f.fq()
+	return fq.sgn0(a[0]) || (fq.isZero(a[0]) && fq.sgn0(a[1]))
+}
+
 func (f *fq2) cmp(a, b *fe2) int8 {
 	cmp := f.f.cmp(a[1], b[1])
 	if cmp == 0 {
@@ -31,6 +31,19 @@ func newFq3(fq *fq, nonResidueBuf []byte) (*fq3, error) {
 	return &fq3{fq, nonResidue, t, nil}, nil
 }
 
+// clone returns a tower that shares parameters with fq3 and has its own
+// scratch space, so that both can be used concurrently
+func (fq3 *fq3) clone() *fq3 {
+	fq := fq3.fq()
+	t := make([]fe, len(fq3.t))
+	for i := range t {
+		t[i] = fq.new()
+	}
+	c := *fq3
+	c.t = t
+	return &c
+}
+
 func (fq3 *fq3) byteSize() int {
 	fq := fq3.fq()
 	return fq.byteSize() * 3
@@ -144,6 +157,20 @@ func (fq3 *fq3) copy(c, a *fe3) *fe3 {
 	return c
 }
 
+func (fq3 *fq3) cmov(c, a *fe3, cond bool) {
+	fq := fq3.fq()
+	fq.cmov(c[0], a[0], cond)
+	fq.cmov(c[1], a[1], cond)
+	fq.cmov(c[2], a[2], cond)
+}
+
+func (fq3 *fq3) cswap(a, b *fe3, cond bool) {
+	fq := fq3.fq()
+	fq.cswap(a[0], b[0], cond)
+	fq.cswap(a[1], b[1], cond)
+	fq.cswap(a[2], b[2], cond)
+}
+
 func (fq3 *fq3) isNonResidue(a *fe3, degree int) bool {
 	zero := big.NewInt(0)
 	result := fq3.new()
@@ -282,6 +309,38 @@ func (fq3 *fq3) inverse(c, a *fe3) bool {
 	return true
 }
 
+// batchInverse sets inv[i] to the inverse of in[i] with a single inversion,
+// zero elements are mapped to zero. inv should not overlap with in.
+func (fq3 *fq3) batchInverse(inv, in []*fe3) bool {
+	acc, t := fq3.one(), fq3.new()
+	for i := range in {
+		if !fq3.isZero(in[i]) {
+			fq3.mul(acc, acc, in[i])
+		}
+		fq3.copy(inv[i], acc)
+	}
+	if ok := fq3.inverse(acc, acc); !ok {
+		for i := range in {
+			fq3.copy(inv[i], fq3.zero())
+		}
+		return false
+	}
+	for i := len(in) - 1; i >= 0; i-- {
+		if fq3.isZero(in[i]) {
+			fq3.copy(inv[i], fq3.zero())
+			continue
+		}
+		if i > 0 {
+			fq3.mul(t, acc, inv[i-1])
+		} else {
+			fq3.copy(t, acc)
+		}
+		fq3.mul(acc, acc, in[i])
+		fq3.copy(inv[i], t)
+	}
+	return true
+}
+
 func (fq3 *fq3) exp(c, a *fe3, e *big.Int) {
 	z := fq3.one()
 	found := false
@@ -298,6 +357,69 @@ func (fq3 *fq3) exp(c, a *fe3, e *big.Int) {
 	fq3.copy(c, z)
 }
 
+// sqrt is tonelli shanks over p^3. p^3 - 1 = (p - 1)(p^2 + p + 1) and
+// the second factor is odd, so 2-adicity and the root of unity of the base
+// field can be used here
+func (fq3 *fq3) sqrt(c, a *fe3) bool {
+	fq := fq3.fq()
+	if fq3.isZero(a) {
+		fq3.copy(c, fq3.zero())
+		return true
+	}
+	_, rootOfUnity, err := fq.sqrtParams()
+	if err != nil {
+		return false
+	}
+	// (q - 1) / 2 for p^3 - 1 = 2^s * q
+	power := new(big.Int).Exp(fq.pbig, big.NewInt(3), nil)
+	power.Rsh(power, uint(fq.s+1))
+	w, x, b, z, t := fq3.new(), fq3.new(), fq3.new(), fq3.zero(), fq3.new()
+	fq3.exp(w, a, power)
+	fq3.mul(x, a, w)
+	fq3.mul(b, x, w)
+	if fq.s > 1 {
+		fq.copy(z[0], rootOfUnity)
+	}
+	v := fq.s
+	for !fq3.isOne(b) {
+		k := 0
+		fq3.copy(t, b)
+		for ; k < v && !fq3.isOne(t); k++ {
+			fq3.square(t, t)
+		}
+		if k == v {
+			fq3.copy(c, fq3.zero())
+			return false
+		}
+		fq3.copy(t, z)
+		for i := 0; i < v-k-1; i++ {
+			fq3.square(t, t)
+		}
+		fq3.square(z, t)
+		fq3.mul(x, x, t)
+		fq3.mul(b, b, z)
+		v = k
+	}
+	fq3.copy(c, x)
+	return true
+}
+
+func (fq3 *fq3) sign(fe *fe3) int8 {
+	neg := fq3.new()
+	fq3.neg(neg, fe)
+	return fq3.cmp(fe, neg)
+}
+
+func (fq3 *fq3) cmp(a, b *fe3) int8 {
+	fq := fq3.fq()
+	for i := 2; i >= 0; i-- {
+		if cmp := fq.cmp(a[i], b[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
 func (fq3 *fq3) mulByFq(c, a *fe3, b fe) {
 	fq := fq3.fq()
 	fq.mul(c[0], a[0], b)
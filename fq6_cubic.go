@@ -31,6 +31,19 @@ func newFq6Cubic(fq2 *fq2, nonResidueBuf []byte) (*fq6C, error) {
 	return &fq6C{fq2, nonResidue, t, nil}, nil
 }
 
+// clone returns a tower that shares parameters with fq6 and has its own
+// scratch space, so that both can be used concurrently
+func (fq6 *fq6C) clone() *fq6C {
+	fq2 := fq6.f.clone()
+	t := make([]*fe2, len(fq6.t))
+	for i := range t {
+		t[i] = fq2.new()
+	}
+	c := *fq6
+	c.f, c.t = fq2, t
+	return &c
+}
+
 func (fq6 *fq6C) byteSize() int {
 	fq2 := fq6.fq2()
 	return fq2.byteSize() * 3
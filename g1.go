@@ -3,12 +3,19 @@ package eip
 import (
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
 )
 
 type pointG1 [3]fe
 
+// flags of compressed point encodings, small and large stand for the
+// sign of y
+const (
+	compressedInfinity byte = 0x00
+	compressedSmall    byte = 0x02
+	compressedLarge    byte = 0x03
+)
+
 type g1 struct {
 	f   *fq
 	a   fe
@@ -16,6 +23,12 @@ type g1 struct {
 	q   *big.Int
 	t   [9]fe
 	inf *pointG1
+	// subgroup is set for groups of pairing families, nil falls back to
+	// multiplication by the order
+	subgroup *subgroupTest
+	// glv is set for curves with a = 0, endomorphism is found on first
+	// multiplication
+	glv *glv
 }
 
 func newG1(f *fq, a, b fe, q *big.Int) (*g1, error) {
@@ -42,6 +55,9 @@ func newG1(f *fq, a, b fe, q *big.Int) (*g1, error) {
 	g.f.copy(g.inf[0], f.zero)
 	g.f.copy(g.inf[1], f.one)
 	g.f.copy(g.inf[2], f.zero)
+	if g.f.isZero(a) {
+		g.glv = &glv{}
+	}
 	return g, nil
 }
 
@@ -95,6 +111,74 @@ func (g *g1) toBytesDense(p *pointG1) []byte {
 	return out
 }
 
+// toBytesCompressed encodes a flag byte followed by x, flag is the
+// infinity flag or the sign of y
+func (g *g1) toBytesCompressed(p *pointG1) []byte {
+	byteLen := g.f.limbSize * 8
+	out := make([]byte, 1+byteLen)
+	if g.isZero(p) {
+		out[0] = compressedInfinity
+		return out
+	}
+	a := g.newPoint()
+	g.affine(a, p)
+	out[0] = compressedSmall
+	if g.f.sign(a[1]) == 1 {
+		out[0] = compressedLarge
+	}
+	copy(out[1:], g.f.toBytes(a[0]))
+	return out
+}
+
+func (g *g1) fromBytesCompressed(in []byte) (*pointG1, error) {
+	byteLen := g.f.limbSize * 8
+	if len(in) != 1+byteLen {
+		return nil, fmt.Errorf("compressed input should be %d bytes given %d", 1+byteLen, len(in))
+	}
+	switch in[0] {
+	case compressedInfinity:
+		for _, b := range in[1:] {
+			if b != 0 {
+				return nil, fmt.Errorf("non canonical encoding of point at infinity")
+			}
+		}
+		return g.zero(), nil
+	case compressedSmall, compressedLarge:
+	default:
+		return nil, fmt.Errorf("unknown compression flag %#x", in[0])
+	}
+	x, err := g.f.fromBytes(in[1:])
+	if err != nil {
+		return nil, err
+	}
+	// composite modulus is reported rather than taken as x having no square root
+	if _, _, err := g.f.sqrtParams(); err != nil {
+		return nil, err
+	}
+	// y^2 = x^3 + ax + b
+	y := g.f.new()
+	g.f.square(y, x)
+	g.f.add(y, y, g.a)
+	g.f.mul(y, y, x)
+	g.f.add(y, y, g.b)
+	if !g.f.sqrt(y, y) {
+		return nil, fmt.Errorf("point is not on curve")
+	}
+	large := in[0] == compressedLarge
+	if (g.f.sign(y) == 1) != large {
+		g.f.neg(y, y)
+	}
+	// y = 0 can only be encoded with small flag
+	if (g.f.sign(y) == 1) != large {
+		return nil, fmt.Errorf("non canonical encoding of y")
+	}
+	p := g.newPoint()
+	g.f.copy(p[0], x)
+	g.f.copy(p[1], y)
+	g.f.copy(p[2], g.f.one)
+	return p, nil
+}
+
 func (g *g1) copy(q, p *pointG1) *pointG1 {
 	g.f.copy(q[0], p[0])
 	g.f.copy(q[1], p[1])
@@ -129,6 +213,35 @@ func (g *g1) affine(r, p *pointG1) *pointG1 {
 	return r
 }
 
+// batchAffine converts points into affine form in place sharing a single
+// inversion, points at infinity are set to zero as in affine
+func (g *g1) batchAffine(points []*pointG1) []*pointG1 {
+	zs, zInv := make([]fe, len(points)), make([]fe, len(points))
+	for i, p := range points {
+		zs[i], zInv[i] = p[2], g.f.new()
+	}
+	if ok := g.f.batchInverse(zInv, zs); !ok {
+		for _, p := range points {
+			g.affine(p, p)
+		}
+		return points
+	}
+	t := g.t
+	for i, p := range points {
+		if g.isZero(p) {
+			g.f.copy(p[0], g.f.zero)
+			g.f.copy(p[1], g.f.zero)
+			continue
+		}
+		g.f.square(t[0], zInv[i])
+		g.f.mul(p[0], p[0], t[0])
+		g.f.mul(t[0], t[0], zInv[i])
+		g.f.mul(p[1], p[1], t[0])
+		g.f.copy(p[2], g.f.one)
+	}
+	return points
+}
+
 func (g *g1) toString(p *pointG1) string {
 	return fmt.Sprintf("%s\n%s\n%s", g.f.toString(p[0]), g.f.toString(p[1]), g.f.toString(p[2]))
 }
@@ -147,6 +260,17 @@ func (g *g1) isZero(p *pointG1) bool {
 	return g.f.isZero(p[2])
 }
 
+// isAffine reports whether each point is either in affine form or the point
+// at infinity
+func (g *g1) isAffine(points []*pointG1) bool {
+	for _, p := range points {
+		if !g.isZero(p) && !g.f.isOne(p[2]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (g *g1) equal(p1, p2 *pointG1) bool {
 	if g.isZero(p1) {
 		return g.isZero(p2)
@@ -169,6 +293,24 @@ func (g *g1) equal(p1, p2 *pointG1) bool {
 	return g.f.equal(t[0], t[1]) && g.f.equal(t[2], t[3])
 }
 
+// isSingular reports whether the discriminant 4a^3 + 27b^2 is zero
+func (g *g1) isSingular() bool {
+	t0, t1, t2 := g.f.new(), g.f.new(), g.f.new()
+	g.f.square(t0, g.a)
+	g.f.mul(t0, t0, g.a)
+	g.f.double(t0, t0)
+	g.f.double(t0, t0) // 4a^3
+	g.f.square(t1, g.b)
+	g.f.double(t2, t1)
+	g.f.add(t1, t1, t2) // 3b^2
+	g.f.double(t2, t1)
+	g.f.double(t2, t2)
+	g.f.double(t2, t2)
+	g.f.add(t1, t1, t2) // 27b^2
+	g.f.add(t0, t0, t1)
+	return g.f.isZero(t0)
+}
+
 func (g *g1) isOnCurve(p *pointG1) bool {
 	if g.isZero(p) {
 		return true
@@ -241,6 +383,51 @@ func (g *g1) add(r, p1, p2 *pointG1) *pointG1 {
 	return r
 }
 
+// addMixed adds p2 in affine form to p1, p2 should either have z = 1 or be
+// the point at infinity
+func (g *g1) addMixed(r, p1, p2 *pointG1) *pointG1 {
+	// http://www.hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#addition-madd-2007-bl
+	if g.isZero(p2) {
+		return g.copy(r, p1)
+	}
+	if g.isZero(p1) {
+		return g.copy(r, p2)
+	}
+	t := g.t
+	g.f.square(t[7], p1[2])    // z1z1
+	g.f.mul(t[1], p2[0], t[7]) // u2 = x2 * z1z1
+	g.f.mul(t[2], p1[2], t[7]) // z1z1 * z1
+	g.f.mul(t[0], p2[1], t[2]) // s2 = y2 * z1z1 * z1
+	if g.f.equal(t[1], p1[0]) {
+		if g.f.equal(t[0], p1[1]) {
+			return g.double(r, p1)
+		}
+		return g.copy(r, g.inf)
+	}
+	g.f.sub(t[1], t[1], p1[0]) // h = u2 - x1
+	g.f.square(t[8], t[1])     // hh
+	g.f.double(t[4], t[8])     // 2hh
+	g.f.double(t[4], t[4])     // i = 4hh
+	g.f.mul(t[5], t[1], t[4])  // j = h*i
+	g.f.sub(t[0], t[0], p1[1]) // s2 - y1
+	g.f.double(t[0], t[0])     // r = 2*(s2 - y1)
+	g.f.mul(t[3], p1[0], t[4]) // v = x1 * i
+	g.f.mul(t[6], p1[1], t[5]) // y1 * j
+	g.f.double(t[6], t[6])     // 2 * y1 * j
+	g.f.add(t[2], p1[2], t[1]) // z1 + h
+	g.f.square(t[2], t[2])     // (z1 + h)^2
+	g.f.sub(t[2], t[2], t[7])  // (z1 + h)^2 - z1z1
+	g.f.sub(r[2], t[2], t[8])  // z3 = (z1 + h)^2 - z1z1 - hh
+	g.f.square(t[2], t[0])     // r^2
+	g.f.sub(t[2], t[2], t[5])  // r^2 - j
+	g.f.double(t[4], t[3])     // 2*v
+	g.f.sub(r[0], t[2], t[4])  // x3 = r^2 - j - 2*v
+	g.f.sub(t[4], t[3], r[0])  // v - x3
+	g.f.mul(t[0], t[0], t[4])  // r * (v - x3)
+	g.f.sub(r[1], t[0], t[6])  // y3 = r * (v - x3) - 2 * y1 * j
+	return r
+}
+
 func (g *g1) double(r, p *pointG1) *pointG1 {
 	if g.f.equal(g.a, g.f.zero) {
 		return g.doubleZeroA(r, p)
@@ -337,6 +524,11 @@ func (g *g1) sub(c, a, b *pointG1) *pointG1 {
 }
 
 func (g *g1) mulScalar(c, p *pointG1, e *big.Int) *pointG1 {
+	if e.BitLen() >= glvMinBits {
+		if params := g.endomorphism(); params != nil {
+			return g.glvMul(c, p, e, params)
+		}
+	}
 	q, n := g.zero(), g.newPoint()
 	g.copy(n, p)
 	l := e.BitLen()
@@ -355,7 +547,113 @@ func (g *g1) mulScalar(c, p *pointG1, e *big.Int) *pointG1 {
 	return c
 }
 
+// mulScalarCT is montgomery ladder with complete addition in projective
+// coordinates. scalar is reduced modulo group order so that number of steps
+// is always bit length of the order, points are expected to be in the
+// subgroup of odd order q
+func (g *g1) mulScalarCT(c, p *pointG1, e *big.Int) *pointG1 {
+	r0, r1 := g.newPoint(), g.newPoint()
+	g.copy(r0, g.inf)
+	g.toProjective(r1, p)
+	b3 := g.f.new()
+	g.f.double(b3, g.b)
+	g.f.add(b3, b3, g.b)
+	k := new(big.Int).Mod(e, g.q)
+	for i := g.q.BitLen() - 1; i >= 0; i-- {
+		bit := k.Bit(i) == 1
+		g.cswap(r0, r1, bit)
+		g.addComplete(r1, r0, r1, b3)
+		g.addComplete(r0, r0, r0, b3)
+		g.cswap(r0, r1, bit)
+	}
+	return g.fromProjective(c, r0)
+}
+
+func (g *g1) cswap(p1, p2 *pointG1, cond bool) {
+	g.f.cswap(p1[0], p2[0], cond)
+	g.f.cswap(p1[1], p2[1], cond)
+	g.f.cswap(p1[2], p2[2], cond)
+}
+
+// toProjective maps jacobian (X, Y, Z) to projective (XZ, Y, Z^3)
+func (g *g1) toProjective(r, p *pointG1) *pointG1 {
+	inf := g.isZero(p)
+	t := g.t
+	g.f.square(t[0], p[2])
+	g.f.mul(r[0], p[0], p[2])
+	g.f.mul(r[2], t[0], p[2])
+	g.f.copy(r[1], p[1])
+	g.f.cmov(r[0], g.inf[0], inf)
+	g.f.cmov(r[1], g.inf[1], inf)
+	g.f.cmov(r[2], g.inf[2], inf)
+	return r
+}
+
+// fromProjective maps projective (X, Y, Z) to jacobian (XZ, YZ^2, Z)
+func (g *g1) fromProjective(r, p *pointG1) *pointG1 {
+	inf := g.isZero(p)
+	t := g.t
+	g.f.square(t[0], p[2])
+	g.f.mul(r[0], p[0], p[2])
+	g.f.mul(r[1], p[1], t[0])
+	g.f.copy(r[2], p[2])
+	g.f.cmov(r[0], g.inf[0], inf)
+	g.f.cmov(r[1], g.inf[1], inf)
+	g.f.cmov(r[2], g.inf[2], inf)
+	return r
+}
+
+func (g *g1) addComplete(r, p1, p2 *pointG1, b3 fe) *pointG1 {
+	// https://eprint.iacr.org/2015/1060 algorithm 1
+	t := g.t
+	g.f.mul(t[0], p1[0], p2[0]) // t0 = x1 * x2
+	g.f.mul(t[1], p1[1], p2[1]) // t1 = y1 * y2
+	g.f.mul(t[2], p1[2], p2[2]) // t2 = z1 * z2
+	g.f.add(t[3], p1[0], p1[1]) // x1 + y1
+	g.f.add(t[4], p2[0], p2[1]) // x2 + y2
+	g.f.mul(t[3], t[3], t[4])   // t3 = (x1 + y1) * (x2 + y2)
+	g.f.add(t[4], t[0], t[1])   // t0 + t1
+	g.f.sub(t[3], t[3], t[4])   // t3 = t3 - t0 - t1
+	g.f.add(t[4], p1[0], p1[2]) // x1 + z1
+	g.f.add(t[5], p2[0], p2[2]) // x2 + z2
+	g.f.mul(t[4], t[4], t[5])   // t4 = (x1 + z1) * (x2 + z2)
+	g.f.add(t[5], t[0], t[2])   // t0 + t2
+	g.f.sub(t[4], t[4], t[5])   // t4 = t4 - t0 - t2
+	g.f.add(t[5], p1[1], p1[2]) // y1 + z1
+	g.f.add(t[6], p2[1], p2[2]) // y2 + z2
+	g.f.mul(t[5], t[5], t[6])   // t5 = (y1 + z1) * (y2 + z2)
+	g.f.add(t[6], t[1], t[2])   // t1 + t2
+	g.f.sub(t[5], t[5], t[6])   // t5 = t5 - t1 - t2
+	g.f.mul(t[8], g.a, t[4])    // z3 = a * t4
+	g.f.mul(t[6], b3, t[2])     // x3 = 3b * t2
+	g.f.add(t[8], t[6], t[8])   // z3 = x3 + z3
+	g.f.sub(t[6], t[1], t[8])   // x3 = t1 - z3
+	g.f.add(t[8], t[1], t[8])   // z3 = t1 + z3
+	g.f.mul(t[7], t[6], t[8])   // y3 = x3 * z3
+	g.f.double(t[1], t[0])      //
+	g.f.add(t[1], t[1], t[0])   // t1 = 3 * t0
+	g.f.mul(t[2], g.a, t[2])    // t2 = a * t2
+	g.f.mul(t[4], b3, t[4])     // t4 = 3b * t4
+	g.f.add(t[1], t[1], t[2])   // t1 = t1 + t2
+	g.f.sub(t[2], t[0], t[2])   // t0 - t2
+	g.f.mul(t[2], g.a, t[2])    // t2 = a * (t0 - t2)
+	g.f.add(t[4], t[4], t[2])   // t4 = t4 + t2
+	g.f.mul(t[0], t[1], t[4])   // t1 * t4
+	g.f.add(t[7], t[7], t[0])   // y3 = y3 + t1 * t4
+	g.f.mul(t[0], t[5], t[4])   // t5 * t4
+	g.f.mul(t[6], t[3], t[6])   // t3 * x3
+	g.f.sub(r[0], t[6], t[0])   // x3 = t3 * x3 - t5 * t4
+	g.f.mul(t[0], t[3], t[1])   // t3 * t1
+	g.f.mul(t[8], t[5], t[8])   // t5 * z3
+	g.f.add(r[2], t[8], t[0])   // z3 = t5 * z3 + t3 * t1
+	g.f.copy(r[1], t[7])
+	return r
+}
+
 func (g *g1) checkCorrectSubgroup(p *pointG1) bool {
+	if s := g.subgroup; s != nil && s.init(g.f, g.q, 1) {
+		return g.checkSubgroupWithEndomorphism(p, s)
+	}
 	c := g.newPoint()
 	g.wnafMul(c, p, g.q)
 	if g.equal(c, g.inf) {
@@ -364,6 +662,27 @@ func (g *g1) checkCorrectSubgroup(p *pointG1) bool {
 	return false
 }
 
+// checkSubgroupWithEndomorphism reports whether [a]P + [b]phi(P) = 0 or
+// [a]P + [b]phi^2(P) = 0
+func (g *g1) checkSubgroupWithEndomorphism(p *pointG1, s *subgroupTest) bool {
+	ap, bp := g.newPoint(), g.newPoint()
+	g.wnafMul(ap, p, new(big.Int).Abs(s.a))
+	if s.a.Sign() > 0 {
+		g.neg(ap, ap)
+	}
+	g.wnafMul(bp, p, new(big.Int).Abs(s.b))
+	if s.b.Sign() < 0 {
+		g.neg(bp, bp)
+	}
+	// -[a]P = phi([b]P)
+	g.f.mul(bp[0], bp[0], s.beta)
+	if g.equal(ap, bp) {
+		return true
+	}
+	g.f.mul(bp[0], bp[0], s.beta)
+	return g.equal(ap, bp)
+}
+
 func (g *g1) wnafMul(c, p *pointG1, e *big.Int) *pointG1 {
 	windowSize := uint(3)
 	precompTable := make([]*pointG1, (1 << (windowSize - 1)))
@@ -406,56 +725,425 @@ func (g *g1) wnafMul(c, p *pointG1, e *big.Int) *pointG1 {
 	return c
 }
 
-func (g *g1) multiExp(r *pointG1, points []*pointG1, powers []*big.Int) (*pointG1, error) {
-	if len(points) != len(powers) {
-		return nil, fmt.Errorf("point and scalar vectors should be in same length")
+// endomorphism returns nil if the curve has no endomorphism of glv
+func (g *g1) endomorphism() *glvParams {
+	if g.glv == nil {
+		return nil
 	}
-	var c uint32 = 3
-	if len(powers) >= 32 {
-		c = uint32(math.Ceil(math.Log10(float64(len(powers)))))
-	}
-	bucket_size, numBits := (1<<c)-1, uint32(g.q.BitLen())
-	windows := make([]*pointG1, numBits/c+1)
-	bucket := make([]*pointG1, bucket_size)
-	acc, sum := g.newPoint(), g.newPoint()
-	for i := 0; i < bucket_size; i++ {
-		bucket[i] = g.newPoint()
-	}
-	mask := (uint64(1) << c) - 1
-	j := 0
-	var cur uint32
-	for cur <= numBits {
-		g.copy(acc, g.inf)
-		bucket = make([]*pointG1, (1<<c)-1)
-		for i := 0; i < len(bucket); i++ {
-			bucket[i] = g.newPoint()
+	g.glv.once.Do(func() {
+		g.glv.params = newGLVParams(g.f, g.b, g.q)
+	})
+	return g.glv.params
+}
+
+// phi is (beta * x, y) that is the same in jacobian coordinates
+func (g *g1) phi(r, p *pointG1, params *glvParams) *pointG1 {
+	g.copy(r, p)
+	g.f.mul(r[0], p[0], params.beta)
+	return r
+}
+
+// glvMul is glvMultiExp of a single point
+func (g *g1) glvMul(c, p *pointG1, e *big.Int, params *glvParams) *pointG1 {
+	return g.glvMultiExp(c, []*pointG1{p}, []*big.Int{e}, params)
+}
+
+// glvMultiExp is joint wnaf multiplication with halves of the scalars for
+// points and their images under phi, doublings are shared by all points
+func (g *g1) glvMultiExp(c *pointG1, points []*pointG1, powers []*big.Int, params *glvParams) *pointG1 {
+	windowSize := uint(4)
+	// odd multiples of the point and its image with signs of the halves
+	tables := make([][]*pointG1, 2*len(points))
+	naf := make([][]int64, 2*len(points))
+	negate := make([]bool, len(points))
+	l := 0
+	doubled := g.newPoint()
+	for i, p := range points {
+		k1, k2 := params.decompose(powers[i])
+		t1 := make([]*pointG1, 1<<(windowSize-2))
+		t1[0] = g.copy(g.newPoint(), p)
+		if k1.Sign() < 0 {
+			g.neg(t1[0], p)
+		}
+		g.double(doubled, t1[0])
+		for j := 1; j < len(t1); j++ {
+			t1[j] = g.add(g.newPoint(), t1[j-1], doubled)
 		}
-		for i := 0; i < len(powers); i++ {
-			s := newRepr(powers[i])
-			index := uint(s[0] & mask)
-			if index != 0 {
-				g.add(bucket[index-1], bucket[index-1], points[i])
+		negate[i] = (k1.Sign() < 0) != (k2.Sign() < 0)
+		tables[2*i], tables[2*i+1] = t1, make([]*pointG1, len(t1))
+		naf[2*i] = wnaf(new(big.Int).Abs(k1), windowSize)
+		naf[2*i+1] = wnaf(new(big.Int).Abs(k2), windowSize)
+		for _, n := range naf[2*i : 2*i+2] {
+			if len(n) > l {
+				l = len(n)
 			}
-			powers[i] = new(big.Int).Rsh(powers[i], uint(c))
 		}
+	}
+	// tables are brought to affine form with a single inversion for mixed
+	// additions, images under phi stay affine
+	all := make([]*pointG1, 0, len(points)<<(windowSize-2))
+	for i := 0; i < len(tables); i += 2 {
+		all = append(all, tables[i]...)
+	}
+	g.batchAffine(all)
+	for i := range points {
+		for j, p := range tables[2*i] {
+			tables[2*i+1][j] = g.phi(g.newPoint(), p, params)
+			if negate[i] {
+				g.neg(tables[2*i+1][j], tables[2*i+1][j])
+			}
+		}
+	}
+	q, t := g.zero(), g.newPoint()
+	for i := l - 1; i >= 0; i-- {
+		g.double(q, q)
+		for j := range naf {
+			if i >= len(naf[j]) || naf[j][i] == 0 {
+				continue
+			}
+			if d := naf[j][i]; d > 0 {
+				g.addMixed(q, q, tables[j][d>>1])
+			} else {
+				g.addMixed(q, q, g.neg(t, tables[j][(-d)>>1]))
+			}
+		}
+	}
+	return g.copy(c, q)
+}
 
-		g.copy(sum, g.inf)
-		for i := len(bucket) - 1; i >= 0; i-- {
-			g.add(sum, sum, bucket[i])
-			g.add(acc, acc, sum)
+// newFixedBaseTable precomputes multiples of p for scalars up to bits with
+// digits of window bits
+func (g *g1) newFixedBaseTable(p *pointG1, bits int, window uint) (*FixedBaseTable, error) {
+	numWindows, err := fixedBaseWindows(bits, window)
+	if err != nil {
+		return nil, err
+	}
+	t := &FixedBaseTable{window: window, numWindows: numWindows, g: g}
+	size := t.size()
+	points := make([]*pointG1, numWindows*size)
+	base := g.copy(g.newPoint(), p)
+	for i := 0; i < numWindows; i++ {
+		row := points[i*size : (i+1)*size]
+		row[0] = g.copy(g.newPoint(), base)
+		for j := 1; j < size; j++ {
+			row[j] = g.add(g.newPoint(), row[j-1], base)
+		}
+		// base of the next row is [2^window] of the base of this row
+		g.double(base, row[size-1])
+	}
+	t.points = g.batchAffine(points)
+	return t, nil
+}
+
+// mulFixedBase multiplies the base point of the table, scalars that do not
+// fit into the table fall back to mulScalar
+func (g *g1) mulFixedBase(c *pointG1, t *FixedBaseTable, e *big.Int) *pointG1 {
+	points := t.points.([]*pointG1)
+	digits, ok := t.digits(e)
+	if !ok {
+		return g.mulScalar(c, points[0], e)
+	}
+	q, n, size := g.zero(), g.newPoint(), t.size()
+	for i, d := range digits {
+		if d > 0 {
+			g.addMixed(q, q, points[i*size+int(d)-1])
+		} else if d < 0 {
+			g.addMixed(q, q, g.neg(n, points[i*size+int(-d)-1]))
+		}
+	}
+	return g.copy(c, q)
+}
+
+// mulFixedBaseCT multiplies the base point of the table in constant time.
+// scalar is reduced modulo group order, for each digit position whole row is
+// scanned with conditional moves and added with complete addition. base
+// point is expected to be in the subgroup of odd order q, tables that do not
+// cover bit length of the order fall back to mulScalarCT
+func (g *g1) mulFixedBaseCT(c *pointG1, t *FixedBaseTable, e *big.Int) *pointG1 {
+	points := t.points.([]*pointG1)
+	if t.numWindows*int(t.window) < g.q.BitLen() {
+		return g.mulScalarCT(c, points[0], e)
+	}
+	b3 := g.f.new()
+	g.f.double(b3, g.b)
+	g.f.add(b3, b3, g.b)
+	q, s, n, size := g.newPoint(), g.newPoint(), g.f.new(), t.size()
+	g.copy(q, g.inf)
+	for i, d := range t.digitsCT(new(big.Int).Mod(e, g.q)) {
+		// |d| without branching
+		mask := d >> 63
+		abs := int((d ^ mask) - mask)
+		g.copy(s, g.inf)
+		for j := 1; j <= size; j++ {
+			p := points[i*size+j-1]
+			g.f.cmov(s[0], p[0], j == abs)
+			g.f.cmov(s[1], p[1], j == abs)
+			g.f.cmov(s[2], p[2], j == abs)
 		}
-		windows[j] = g.newPoint()
-		g.copy(windows[j], acc)
-		j++
-		cur += c
-	}
-	g.copy(acc, g.inf)
-	for i := len(windows) - 1; i >= 0; i-- {
-		for j := uint32(0); j < c; j++ {
+		g.toProjective(s, s)
+		g.f.neg(n, s[1])
+		g.f.cmov(s[1], n, mask != 0)
+		g.addComplete(q, q, s, b3)
+	}
+	return g.fromProjective(c, q)
+}
+
+// fixedBaseTableToBytes encodes the header of the table followed by its
+// points as in toBytes
+func (g *g1) fixedBaseTableToBytes(t *FixedBaseTable) []byte {
+	out := fixedBaseTableHeader(t, 2*g.f.byteSize())
+	for _, p := range t.points.([]*pointG1) {
+		out = append(out, g.toBytes(p)...)
+	}
+	return out
+}
+
+// fixedBaseTableFromBytes decodes a table, points are checked to be on the
+// curve but not to be multiples of the base point
+func (g *g1) fixedBaseTableFromBytes(in []byte) (*FixedBaseTable, error) {
+	pointSize := 2 * g.f.byteSize()
+	t, in, err := parseFixedBaseTableHeader(in, pointSize)
+	if err != nil {
+		return nil, err
+	}
+	t.g = g
+	points := make([]*pointG1, t.numWindows*t.size())
+	for i := range points {
+		buf := in[i*pointSize : (i+1)*pointSize]
+		if isAllZeros(buf) {
+			points[i] = g.zero()
+			continue
+		}
+		if points[i], err = g.fromBytes(buf); err != nil {
+			return nil, err
+		}
+		if !g.isOnCurve(points[i]) {
+			return nil, fmt.Errorf("point of fixed base table is not on curve")
+		}
+	}
+	t.points = points
+	return t, nil
+}
+
+func (g *g1) multiExp(r *pointG1, points []*pointG1, powers []*big.Int) (*pointG1, error) {
+	if len(points) != len(powers) {
+		return nil, fmt.Errorf("point and scalar vectors should be in same length")
+	}
+	if len(points) <= g1GLVMultiExpThreshold {
+		if params := g.endomorphism(); params != nil {
+			return g.glvMultiExp(r, points, powers, params), nil
+		}
+	}
+	reprs, c, numWindows := multiExpWindows(powers)
+	m := &g1MultiExp{g, points, make([]*pointG1, numWindows), g.isAffine(points)}
+	bucketMultiExp(m, reprs, c, numWindows)
+	acc := g.zero()
+	for i := numWindows - 1; i >= 0; i-- {
+		for j := uint(0); j < c; j++ {
 			g.double(acc, acc)
 		}
-		g.add(acc, acc, windows[i])
+		g.add(acc, acc, m.windows[i])
 	}
 	g.copy(r, acc)
 	return r, nil
 }
+
+// g1GLVMultiExpThreshold is the number of points up to which joint wnaf
+// with the endomorphism is faster than the bucket method
+const g1GLVMultiExpThreshold = 16
+
+type g1MultiExp struct {
+	g       *g1
+	points  []*pointG1
+	windows []*pointG1
+	// points are added with mixed addition if all are in affine form
+	affine bool
+}
+
+type g1MultiExpWorker struct {
+	*g1MultiExp
+	g        *g1
+	buckets  []*pointG1
+	acc, sum *pointG1
+}
+
+func (m *g1MultiExp) newWorker(numBuckets int) multiExpWorker {
+	if m.affine && len(m.points) >= g1BatchAffineThreshold {
+		return newG1BatchAffineWorker(m, numBuckets)
+	}
+	g := m.g.clone()
+	buckets := make([]*pointG1, numBuckets)
+	for i := range buckets {
+		buckets[i] = g.zero()
+	}
+	return &g1MultiExpWorker{m, g, buckets, g.zero(), g.zero()}
+}
+
+func (w *g1MultiExpWorker) clearBuckets() {
+	for _, bucket := range w.buckets {
+		w.g.copy(bucket, w.g.inf)
+	}
+}
+
+func (w *g1MultiExpWorker) addToBucket(bucket, i int) {
+	if w.affine {
+		w.g.addMixed(w.buckets[bucket], w.buckets[bucket], w.points[i])
+		return
+	}
+	w.g.add(w.buckets[bucket], w.buckets[bucket], w.points[i])
+}
+
+func (w *g1MultiExpWorker) reduceBuckets(window int) {
+	g := w.g
+	g.copy(w.acc, g.inf)
+	g.copy(w.sum, g.inf)
+	for i := len(w.buckets) - 1; i >= 0; i-- {
+		g.add(w.sum, w.sum, w.buckets[i])
+		g.add(w.acc, w.acc, w.sum)
+	}
+	w.windows[window] = g.copy(g.newPoint(), w.acc)
+}
+
+// g1BatchAffineThreshold is the number of points from which buckets are
+// accumulated in affine form
+const g1BatchAffineThreshold = 1 << 12
+
+// g1BatchAffineWorker keeps buckets in affine form and adds points in batches
+// so that a single inversion is shared by all additions of a batch. A point
+// that goes to a bucket which is already in the batch is added to the
+// overflow of the bucket in jacobian form instead.
+type g1BatchAffineWorker struct {
+	*g1MultiExp
+	g        *g1
+	buckets  []*pointG1
+	overflow []*pointG1
+	inBatch  []bool
+	// bucket and point indexes of pending additions
+	batch    [][2]int
+	denoms   []fe
+	inverses []fe
+	acc, sum *pointG1
+}
+
+func newG1BatchAffineWorker(m *g1MultiExp, numBuckets int) *g1BatchAffineWorker {
+	g := m.g.clone()
+	batchSize := numBuckets / 2
+	if batchSize < 16 {
+		batchSize = 16
+	} else if batchSize > 1024 {
+		batchSize = 1024
+	}
+	w := &g1BatchAffineWorker{
+		g1MultiExp: m,
+		g:          g,
+		buckets:    make([]*pointG1, numBuckets),
+		overflow:   make([]*pointG1, numBuckets),
+		inBatch:    make([]bool, numBuckets),
+		batch:      make([][2]int, 0, batchSize),
+		denoms:     make([]fe, batchSize),
+		inverses:   make([]fe, batchSize),
+		acc:        g.zero(),
+		sum:        g.zero(),
+	}
+	for i := 0; i < numBuckets; i++ {
+		w.buckets[i], w.overflow[i] = g.zero(), g.zero()
+	}
+	for i := 0; i < batchSize; i++ {
+		w.denoms[i], w.inverses[i] = g.f.new(), g.f.new()
+	}
+	return w
+}
+
+func (w *g1BatchAffineWorker) clearBuckets() {
+	for i := range w.buckets {
+		w.g.copy(w.buckets[i], w.g.inf)
+		w.g.copy(w.overflow[i], w.g.inf)
+	}
+}
+
+func (w *g1BatchAffineWorker) addToBucket(bucket, i int) {
+	g, f := w.g, w.g.f
+	b, p := w.buckets[bucket], w.points[i]
+	if g.isZero(p) {
+		return
+	}
+	if w.inBatch[bucket] {
+		g.addMixed(w.overflow[bucket], w.overflow[bucket], p)
+		return
+	}
+	if g.isZero(b) {
+		g.copy(b, p)
+		return
+	}
+	d := w.denoms[len(w.batch)]
+	if f.equal(b[0], p[0]) {
+		if !f.equal(b[1], p[1]) || f.isZero(b[1]) {
+			// p = -b
+			g.copy(b, g.inf)
+			return
+		}
+		f.double(d, b[1]) // 2y
+	} else {
+		f.sub(d, p[0], b[0]) // x2 - x1
+	}
+	w.batch = append(w.batch, [2]int{bucket, i})
+	w.inBatch[bucket] = true
+	if len(w.batch) == cap(w.batch) {
+		w.flush()
+	}
+}
+
+// flush applies pending additions with affine formulas, denominators are
+// inverted together
+func (w *g1BatchAffineWorker) flush() {
+	n := len(w.batch)
+	if n == 0 {
+		return
+	}
+	g, f, t := w.g, w.g.f, w.g.t
+	f.batchInverse(w.inverses[:n], w.denoms[:n])
+	for k := 0; k < n; k++ {
+		b, p := w.buckets[w.batch[k][0]], w.points[w.batch[k][1]]
+		if f.equal(b[0], p[0]) {
+			f.square(t[2], b[0])
+			f.double(t[3], t[2])
+			f.add(t[2], t[3], t[2])
+			f.add(t[2], t[2], g.a) // 3x^2 + a
+		} else {
+			f.sub(t[2], p[1], b[1]) // y2 - y1
+		}
+		f.mul(t[2], t[2], w.inverses[k]) // lambda
+		f.square(t[3], t[2])             // lambda^2
+		f.sub(t[3], t[3], b[0])          // lambda^2 - x1
+		f.sub(t[3], t[3], p[0])          // x3 = lambda^2 - x1 - x2
+		f.sub(t[4], b[0], t[3])          // x1 - x3
+		f.mul(t[4], t[4], t[2])          // lambda * (x1 - x3)
+		f.sub(b[1], t[4], b[1])          // y3 = lambda * (x1 - x3) - y1
+		f.copy(b[0], t[3])
+		w.inBatch[w.batch[k][0]] = false
+	}
+	w.batch = w.batch[:0]
+}
+
+func (w *g1BatchAffineWorker) reduceBuckets(window int) {
+	w.flush()
+	g := w.g
+	g.copy(w.acc, g.inf)
+	g.copy(w.sum, g.inf)
+	for i := len(w.buckets) - 1; i >= 0; i-- {
+		g.addMixed(w.sum, w.sum, w.buckets[i])
+		g.add(w.sum, w.sum, w.overflow[i])
+		g.add(w.acc, w.acc, w.sum)
+	}
+	w.windows[window] = g.copy(g.newPoint(), w.acc)
+}
+
+// clone returns a group that shares curve parameters with g and has its own
+// scratch space
+func (g *g1) clone() *g1 {
+	c := *g
+	for i := range c.t {
+		c.t[i] = g.f.new()
+	}
+	return &c
+}
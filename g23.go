@@ -3,7 +3,6 @@ package eip
 import (
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
 )
 
@@ -88,6 +87,74 @@ func (g *g23) toBytesDense(p *pointG23) []byte {
 	return out
 }
 
+// toBytesCompressed encodes a flag byte followed by x, flag is the
+// infinity flag or the sign of y
+func (g *g23) toBytesCompressed(p *pointG23) []byte {
+	byteLen := g.f.byteSize()
+	out := make([]byte, 1+byteLen)
+	if g.isZero(p) {
+		out[0] = compressedInfinity
+		return out
+	}
+	a := g.newPoint()
+	g.affine(a, p)
+	out[0] = compressedSmall
+	if g.f.sign(a[1]) == 1 {
+		out[0] = compressedLarge
+	}
+	copy(out[1:], g.f.toBytes(a[0]))
+	return out
+}
+
+func (g *g23) fromBytesCompressed(in []byte) (*pointG23, error) {
+	byteLen := g.f.byteSize()
+	if len(in) != 1+byteLen {
+		return nil, fmt.Errorf("compressed input should be %d bytes given %d", 1+byteLen, len(in))
+	}
+	switch in[0] {
+	case compressedInfinity:
+		for _, b := range in[1:] {
+			if b != 0 {
+				return nil, fmt.Errorf("non canonical encoding of point at infinity")
+			}
+		}
+		return g.zero(), nil
+	case compressedSmall, compressedLarge:
+	default:
+		return nil, fmt.Errorf("unknown compression flag %#x", in[0])
+	}
+	x, err := g.f.fromBytes(in[1:])
+	if err != nil {
+		return nil, err
+	}
+	// composite modulus is reported rather than taken as x having no square root
+	if _, _, err := g.f.fq().sqrtParams(); err != nil {
+		return nil, err
+	}
+	// y^2 = x^3 + ax + b
+	y := g.f.new()
+	g.f.square(y, x)
+	g.f.add(y, y, g.a)
+	g.f.mul(y, y, x)
+	g.f.add(y, y, g.b)
+	if !g.f.sqrt(y, y) {
+		return nil, fmt.Errorf("point is not on curve")
+	}
+	large := in[0] == compressedLarge
+	if (g.f.sign(y) == 1) != large {
+		g.f.neg(y, y)
+	}
+	// y = 0 can only be encoded with small flag
+	if (g.f.sign(y) == 1) != large {
+		return nil, fmt.Errorf("non canonical encoding of y")
+	}
+	p := g.newPoint()
+	g.f.copy(p[0], x)
+	g.f.copy(p[1], y)
+	g.f.copy(p[2], g.f.one())
+	return p, nil
+}
+
 func (g *g23) copy(q, p *pointG23) *pointG23 {
 	g.f.copy(q[0], p[0])
 	g.f.copy(q[1], p[1])
@@ -123,6 +190,35 @@ func (g *g23) affine(r, p *pointG23) *pointG23 {
 	return q
 }
 
+// batchAffine converts points into affine form in place sharing a single
+// inversion, points at infinity are set to zero as in affine
+func (g *g23) batchAffine(points []*pointG23) []*pointG23 {
+	zs, zInv := make([]*fe3, len(points)), make([]*fe3, len(points))
+	for i, p := range points {
+		zs[i], zInv[i] = p[2], g.f.new()
+	}
+	if ok := g.f.batchInverse(zInv, zs); !ok {
+		for _, p := range points {
+			g.affine(p, p)
+		}
+		return points
+	}
+	t, one := g.t, g.f.one()
+	for i, p := range points {
+		if g.isZero(p) {
+			g.f.copy(p[0], g.f.zero())
+			g.f.copy(p[1], g.f.zero())
+			continue
+		}
+		g.f.square(t[0], zInv[i])
+		g.f.mul(p[0], p[0], t[0])
+		g.f.mul(t[0], t[0], zInv[i])
+		g.f.mul(p[1], p[1], t[0])
+		g.f.copy(p[2], one)
+	}
+	return points
+}
+
 func (g *g23) toString(p *pointG23) string {
 	return fmt.Sprintf("%s\n%s\n%s", g.f.toString(p[0]), g.f.toString(p[1]), g.f.toString(p[2]))
 }
@@ -141,6 +237,17 @@ func (g *g23) isZero(p *pointG23) bool {
 	return g.f.isZero(p[2])
 }
 
+// isAffine reports whether each point is either in affine form or the point
+// at infinity
+func (g *g23) isAffine(points []*pointG23) bool {
+	for _, p := range points {
+		if !g.isZero(p) && !g.f.isOne(p[2]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (g *g23) equal(p1, p2 *pointG23) bool {
 	if g.isZero(p1) {
 		return g.isZero(p2)
@@ -163,6 +270,24 @@ func (g *g23) equal(p1, p2 *pointG23) bool {
 	return g.f.equal(t[0], t[1]) && g.f.equal(t[2], t[3])
 }
 
+// isSingular reports whether the discriminant 4a^3 + 27b^2 is zero
+func (g *g23) isSingular() bool {
+	t0, t1, t2 := g.f.new(), g.f.new(), g.f.new()
+	g.f.square(t0, g.a)
+	g.f.mul(t0, t0, g.a)
+	g.f.double(t0, t0)
+	g.f.double(t0, t0) // 4a^3
+	g.f.square(t1, g.b)
+	g.f.double(t2, t1)
+	g.f.add(t1, t1, t2) // 3b^2
+	g.f.double(t2, t1)
+	g.f.double(t2, t2)
+	g.f.double(t2, t2)
+	g.f.add(t1, t1, t2) // 27b^2
+	g.f.add(t0, t0, t1)
+	return g.f.isZero(t0)
+}
+
 func (g *g23) isOnCurve(p *pointG23) bool {
 	if g.isZero(p) {
 		return true
@@ -235,6 +360,51 @@ func (g *g23) add(r, p1, p2 *pointG23) *pointG23 {
 	return r
 }
 
+// addMixed adds p2 in affine form to p1, p2 should either have z = 1 or be
+// the point at infinity
+func (g *g23) addMixed(r, p1, p2 *pointG23) *pointG23 {
+	// http://www.hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#addition-madd-2007-bl
+	if g.isZero(p2) {
+		return g.copy(r, p1)
+	}
+	if g.isZero(p1) {
+		return g.copy(r, p2)
+	}
+	t := g.t
+	g.f.square(t[7], p1[2])    // z1z1
+	g.f.mul(t[1], p2[0], t[7]) // u2 = x2 * z1z1
+	g.f.mul(t[2], p1[2], t[7]) // z1z1 * z1
+	g.f.mul(t[0], p2[1], t[2]) // s2 = y2 * z1z1 * z1
+	if g.f.equal(t[1], p1[0]) {
+		if g.f.equal(t[0], p1[1]) {
+			return g.double(r, p1)
+		}
+		return g.copy(r, g.inf)
+	}
+	g.f.sub(t[1], t[1], p1[0]) // h = u2 - x1
+	g.f.square(t[8], t[1])     // hh
+	g.f.double(t[4], t[8])     // 2hh
+	g.f.double(t[4], t[4])     // i = 4hh
+	g.f.mul(t[5], t[1], t[4])  // j = h*i
+	g.f.sub(t[0], t[0], p1[1]) // s2 - y1
+	g.f.double(t[0], t[0])     // r = 2*(s2 - y1)
+	g.f.mul(t[3], p1[0], t[4]) // v = x1 * i
+	g.f.mul(t[6], p1[1], t[5]) // y1 * j
+	g.f.double(t[6], t[6])     // 2 * y1 * j
+	g.f.add(t[2], p1[2], t[1]) // z1 + h
+	g.f.square(t[2], t[2])     // (z1 + h)^2
+	g.f.sub(t[2], t[2], t[7])  // (z1 + h)^2 - z1z1
+	g.f.sub(r[2], t[2], t[8])  // z3 = (z1 + h)^2 - z1z1 - hh
+	g.f.square(t[2], t[0])     // r^2
+	g.f.sub(t[2], t[2], t[5])  // r^2 - j
+	g.f.double(t[4], t[3])     // 2*v
+	g.f.sub(r[0], t[2], t[4])  // x3 = r^2 - j - 2*v
+	g.f.sub(t[4], t[3], r[0])  // v - x3
+	g.f.mul(t[0], t[0], t[4])  // r * (v - x3)
+	g.f.sub(r[1], t[0], t[6])  // y3 = r * (v - x3) - 2 * y1 * j
+	return r
+}
+
 func (g *g23) double(r, p *pointG23) *pointG23 {
 	if g.f.equal(g.a, g.f.zero()) {
 		return g.doubleZeroA(r, p)
@@ -350,6 +520,109 @@ func (g *g23) mulScalar(c, p *pointG23, e *big.Int) *pointG23 {
 	return c
 }
 
+// mulScalarCT is montgomery ladder with complete addition in projective
+// coordinates. scalar is reduced modulo group order so that number of steps
+// is always bit length of the order, points are expected to be in the
+// subgroup of odd order q
+func (g *g23) mulScalarCT(c, p *pointG23, e *big.Int) *pointG23 {
+	r0, r1 := g.newPoint(), g.newPoint()
+	g.copy(r0, g.inf)
+	g.toProjective(r1, p)
+	b3 := g.f.new()
+	g.f.double(b3, g.b)
+	g.f.add(b3, b3, g.b)
+	k := new(big.Int).Mod(e, g.q)
+	for i := g.q.BitLen() - 1; i >= 0; i-- {
+		bit := k.Bit(i) == 1
+		g.cswap(r0, r1, bit)
+		g.addComplete(r1, r0, r1, b3)
+		g.addComplete(r0, r0, r0, b3)
+		g.cswap(r0, r1, bit)
+	}
+	return g.fromProjective(c, r0)
+}
+
+func (g *g23) cswap(p1, p2 *pointG23, cond bool) {
+	g.f.cswap(p1[0], p2[0], cond)
+	g.f.cswap(p1[1], p2[1], cond)
+	g.f.cswap(p1[2], p2[2], cond)
+}
+
+// toProjective maps jacobian (X, Y, Z) to projective (XZ, Y, Z^3)
+func (g *g23) toProjective(r, p *pointG23) *pointG23 {
+	inf := g.isZero(p)
+	t := g.t
+	g.f.square(t[0], p[2])
+	g.f.mul(r[0], p[0], p[2])
+	g.f.mul(r[2], t[0], p[2])
+	g.f.copy(r[1], p[1])
+	g.f.cmov(r[0], g.inf[0], inf)
+	g.f.cmov(r[1], g.inf[1], inf)
+	g.f.cmov(r[2], g.inf[2], inf)
+	return r
+}
+
+// fromProjective maps projective (X, Y, Z) to jacobian (XZ, YZ^2, Z)
+func (g *g23) fromProjective(r, p *pointG23) *pointG23 {
+	inf := g.isZero(p)
+	t := g.t
+	g.f.square(t[0], p[2])
+	g.f.mul(r[0], p[0], p[2])
+	g.f.mul(r[1], p[1], t[0])
+	g.f.copy(r[2], p[2])
+	g.f.cmov(r[0], g.inf[0], inf)
+	g.f.cmov(r[1], g.inf[1], inf)
+	g.f.cmov(r[2], g.inf[2], inf)
+	return r
+}
+
+func (g *g23) addComplete(r, p1, p2 *pointG23, b3 *fe3) *pointG23 {
+	// https://eprint.iacr.org/2015/1060 algorithm 1
+	t := g.t
+	g.f.mul(t[0], p1[0], p2[0]) // t0 = x1 * x2
+	g.f.mul(t[1], p1[1], p2[1]) // t1 = y1 * y2
+	g.f.mul(t[2], p1[2], p2[2]) // t2 = z1 * z2
+	g.f.add(t[3], p1[0], p1[1]) // x1 + y1
+	g.f.add(t[4], p2[0], p2[1]) // x2 + y2
+	g.f.mul(t[3], t[3], t[4])   // t3 = (x1 + y1) * (x2 + y2)
+	g.f.add(t[4], t[0], t[1])   // t0 + t1
+	g.f.sub(t[3], t[3], t[4])   // t3 = t3 - t0 - t1
+	g.f.add(t[4], p1[0], p1[2]) // x1 + z1
+	g.f.add(t[5], p2[0], p2[2]) // x2 + z2
+	g.f.mul(t[4], t[4], t[5])   // t4 = (x1 + z1) * (x2 + z2)
+	g.f.add(t[5], t[0], t[2])   // t0 + t2
+	g.f.sub(t[4], t[4], t[5])   // t4 = t4 - t0 - t2
+	g.f.add(t[5], p1[1], p1[2]) // y1 + z1
+	g.f.add(t[6], p2[1], p2[2]) // y2 + z2
+	g.f.mul(t[5], t[5], t[6])   // t5 = (y1 + z1) * (y2 + z2)
+	g.f.add(t[6], t[1], t[2])   // t1 + t2
+	g.f.sub(t[5], t[5], t[6])   // t5 = t5 - t1 - t2
+	g.f.mul(t[8], g.a, t[4])    // z3 = a * t4
+	g.f.mul(t[6], b3, t[2])     // x3 = 3b * t2
+	g.f.add(t[8], t[6], t[8])   // z3 = x3 + z3
+	g.f.sub(t[6], t[1], t[8])   // x3 = t1 - z3
+	g.f.add(t[8], t[1], t[8])   // z3 = t1 + z3
+	g.f.mul(t[7], t[6], t[8])   // y3 = x3 * z3
+	g.f.double(t[1], t[0])      //
+	g.f.add(t[1], t[1], t[0])   // t1 = 3 * t0
+	g.f.mul(t[2], g.a, t[2])    // t2 = a * t2
+	g.f.mul(t[4], b3, t[4])     // t4 = 3b * t4
+	g.f.add(t[1], t[1], t[2])   // t1 = t1 + t2
+	g.f.sub(t[2], t[0], t[2])   // t0 - t2
+	g.f.mul(t[2], g.a, t[2])    // t2 = a * (t0 - t2)
+	g.f.add(t[4], t[4], t[2])   // t4 = t4 + t2
+	g.f.mul(t[0], t[1], t[4])   // t1 * t4
+	g.f.add(t[7], t[7], t[0])   // y3 = y3 + t1 * t4
+	g.f.mul(t[0], t[5], t[4])   // t5 * t4
+	g.f.mul(t[6], t[3], t[6])   // t3 * x3
+	g.f.sub(r[0], t[6], t[0])   // x3 = t3 * x3 - t5 * t4
+	g.f.mul(t[0], t[3], t[1])   // t3 * t1
+	g.f.mul(t[8], t[5], t[8])   // t5 * z3
+	g.f.add(r[2], t[8], t[0])   // z3 = t5 * z3 + t3 * t1
+	g.f.copy(r[1], t[7])
+	return r
+}
+
 func (g *g23) checkCorrectSubgroup(p *pointG23) bool {
 	c := g.newPoint()
 	g.wnafMul(c, p, g.q)
@@ -401,56 +674,194 @@ func (g *g23) wnafMul(c, p *pointG23, e *big.Int) *pointG23 {
 	return c
 }
 
-func (g *g23) multiExp(r *pointG23, points []*pointG23, powers []*big.Int) (*pointG23, error) {
-	if len(points) != len(powers) {
-		return nil, fmt.Errorf("point and scalar vectors should be in same length")
+// newFixedBaseTable precomputes multiples of p for scalars up to bits with
+// digits of window bits
+func (g *g23) newFixedBaseTable(p *pointG23, bits int, window uint) (*FixedBaseTable, error) {
+	numWindows, err := fixedBaseWindows(bits, window)
+	if err != nil {
+		return nil, err
 	}
-	var c uint32 = 3
-	if len(powers) >= 32 {
-		c = uint32(math.Ceil(math.Log10(float64(len(powers)))))
-	}
-	bucket_size, numBits := (1<<c)-1, uint32(g.q.BitLen())
-	windows := make([]*pointG23, numBits/c+1)
-	bucket := make([]*pointG23, bucket_size)
-	acc, sum, zero := g.zero(), g.zero(), g.zero()
-	for i := 0; i < bucket_size; i++ {
-		bucket[i] = g.newPoint()
-	}
-	mask := (uint64(1) << c) - 1
-	j := 0
-	var cur uint32
-	for cur <= numBits {
-		g.copy(acc, zero)
-		bucket = make([]*pointG23, (1<<c)-1)
-		for i := 0; i < len(bucket); i++ {
-			bucket[i] = g.zero()
+	t := &FixedBaseTable{window: window, numWindows: numWindows, g: g}
+	size := t.size()
+	points := make([]*pointG23, numWindows*size)
+	base := g.copy(g.newPoint(), p)
+	for i := 0; i < numWindows; i++ {
+		row := points[i*size : (i+1)*size]
+		row[0] = g.copy(g.newPoint(), base)
+		for j := 1; j < size; j++ {
+			row[j] = g.add(g.newPoint(), row[j-1], base)
 		}
-		for i := 0; i < len(powers); i++ {
-			s := newRepr(powers[i])
-			index := uint(s[0] & mask)
-			if index != 0 {
-				g.add(bucket[index-1], bucket[index-1], points[i])
-			}
-			powers[i] = new(big.Int).Rsh(powers[i], uint(c))
+		// base of the next row is [2^window] of the base of this row
+		g.double(base, row[size-1])
+	}
+	t.points = g.batchAffine(points)
+	return t, nil
+}
+
+// mulFixedBase multiplies the base point of the table, scalars that do not
+// fit into the table fall back to mulScalar
+func (g *g23) mulFixedBase(c *pointG23, t *FixedBaseTable, e *big.Int) *pointG23 {
+	points := t.points.([]*pointG23)
+	digits, ok := t.digits(e)
+	if !ok {
+		return g.mulScalar(c, points[0], e)
+	}
+	q, n, size := g.zero(), g.newPoint(), t.size()
+	for i, d := range digits {
+		if d > 0 {
+			g.addMixed(q, q, points[i*size+int(d)-1])
+		} else if d < 0 {
+			g.addMixed(q, q, g.neg(n, points[i*size+int(-d)-1]))
 		}
+	}
+	return g.copy(c, q)
+}
 
-		g.copy(sum, zero)
-		for i := len(bucket) - 1; i >= 0; i-- {
-			g.add(sum, sum, bucket[i])
-			g.add(acc, acc, sum)
+// mulFixedBaseCT multiplies the base point of the table in constant time.
+// scalar is reduced modulo group order, for each digit position whole row is
+// scanned with conditional moves and added with complete addition. base
+// point is expected to be in the subgroup of odd order q, tables that do not
+// cover bit length of the order fall back to mulScalarCT
+func (g *g23) mulFixedBaseCT(c *pointG23, t *FixedBaseTable, e *big.Int) *pointG23 {
+	points := t.points.([]*pointG23)
+	if t.numWindows*int(t.window) < g.q.BitLen() {
+		return g.mulScalarCT(c, points[0], e)
+	}
+	b3 := g.f.new()
+	g.f.double(b3, g.b)
+	g.f.add(b3, b3, g.b)
+	q, s, n, size := g.newPoint(), g.newPoint(), g.f.new(), t.size()
+	g.copy(q, g.inf)
+	for i, d := range t.digitsCT(new(big.Int).Mod(e, g.q)) {
+		// |d| without branching
+		mask := d >> 63
+		abs := int((d ^ mask) - mask)
+		g.copy(s, g.inf)
+		for j := 1; j <= size; j++ {
+			p := points[i*size+j-1]
+			g.f.cmov(s[0], p[0], j == abs)
+			g.f.cmov(s[1], p[1], j == abs)
+			g.f.cmov(s[2], p[2], j == abs)
 		}
-		windows[j] = g.newPoint()
-		g.copy(windows[j], acc)
-		j++
-		cur += c
-	}
-	g.copy(acc, g.zero())
-	for i := len(windows) - 1; i >= 0; i-- {
-		for j := uint32(0); j < c; j++ {
+		g.toProjective(s, s)
+		g.f.neg(n, s[1])
+		g.f.cmov(s[1], n, mask != 0)
+		g.addComplete(q, q, s, b3)
+	}
+	return g.fromProjective(c, q)
+}
+
+// fixedBaseTableToBytes encodes the header of the table followed by its
+// points as in toBytes
+func (g *g23) fixedBaseTableToBytes(t *FixedBaseTable) []byte {
+	out := fixedBaseTableHeader(t, 2*g.f.byteSize())
+	for _, p := range t.points.([]*pointG23) {
+		out = append(out, g.toBytes(p)...)
+	}
+	return out
+}
+
+// fixedBaseTableFromBytes decodes a table, points are checked to be on the
+// curve but not to be multiples of the base point
+func (g *g23) fixedBaseTableFromBytes(in []byte) (*FixedBaseTable, error) {
+	pointSize := 2 * g.f.byteSize()
+	t, in, err := parseFixedBaseTableHeader(in, pointSize)
+	if err != nil {
+		return nil, err
+	}
+	t.g = g
+	points := make([]*pointG23, t.numWindows*t.size())
+	for i := range points {
+		buf := in[i*pointSize : (i+1)*pointSize]
+		if isAllZeros(buf) {
+			points[i] = g.zero()
+			continue
+		}
+		if points[i], err = g.fromBytes(buf); err != nil {
+			return nil, err
+		}
+		if !g.isOnCurve(points[i]) {
+			return nil, fmt.Errorf("point of fixed base table is not on curve")
+		}
+	}
+	t.points = points
+	return t, nil
+}
+
+func (g *g23) multiExp(r *pointG23, points []*pointG23, powers []*big.Int) (*pointG23, error) {
+	if len(points) != len(powers) {
+		return nil, fmt.Errorf("point and scalar vectors should be in same length")
+	}
+	reprs, c, numWindows := multiExpWindows(powers)
+	m := &g23MultiExp{g, points, make([]*pointG23, numWindows), g.isAffine(points)}
+	bucketMultiExp(m, reprs, c, numWindows)
+	acc := g.zero()
+	for i := numWindows - 1; i >= 0; i-- {
+		for j := uint(0); j < c; j++ {
 			g.double(acc, acc)
 		}
-		g.add(acc, acc, windows[i])
+		g.add(acc, acc, m.windows[i])
 	}
 	g.copy(r, acc)
 	return r, nil
 }
+
+type g23MultiExp struct {
+	g       *g23
+	points  []*pointG23
+	windows []*pointG23
+	// points are added with mixed addition if all are in affine form
+	affine bool
+}
+
+type g23MultiExpWorker struct {
+	*g23MultiExp
+	g        *g23
+	buckets  []*pointG23
+	acc, sum *pointG23
+}
+
+func (m *g23MultiExp) newWorker(numBuckets int) multiExpWorker {
+	g := m.g.clone(m.g.f.clone())
+	buckets := make([]*pointG23, numBuckets)
+	for i := range buckets {
+		buckets[i] = g.zero()
+	}
+	return &g23MultiExpWorker{m, g, buckets, g.zero(), g.zero()}
+}
+
+func (w *g23MultiExpWorker) clearBuckets() {
+	for _, bucket := range w.buckets {
+		w.g.copy(bucket, w.g.inf)
+	}
+}
+
+func (w *g23MultiExpWorker) addToBucket(bucket, i int) {
+	if w.affine {
+		w.g.addMixed(w.buckets[bucket], w.buckets[bucket], w.points[i])
+		return
+	}
+	w.g.add(w.buckets[bucket], w.buckets[bucket], w.points[i])
+}
+
+func (w *g23MultiExpWorker) reduceBuckets(window int) {
+	g := w.g
+	g.copy(w.acc, g.inf)
+	g.copy(w.sum, g.inf)
+	for i := len(w.buckets) - 1; i >= 0; i-- {
+		g.add(w.sum, w.sum, w.buckets[i])
+		g.add(w.acc, w.acc, w.sum)
+	}
+	w.windows[window] = g.copy(g.newPoint(), w.acc)
+}
+
+// clone returns a group over f that shares curve parameters with g and has
+// its own scratch space
+func (g *g23) clone(f *fq3) *g23 {
+	c := *g
+	c.f = f
+	for i := range c.t {
+		c.t[i] = f.zero()
+	}
+	return &c
+}
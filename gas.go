@@ -0,0 +1,520 @@
+package eip
+
+import (
+	"errors"
+	"math/big"
+)
+
+const (
+	// multiexp discount is scaled by this value
+	MULTIEXP_DISCOUNT_MULTIPLIER = 1000
+	// number of pairs after which the discount stays the same
+	MAX_MULTIEXP_DISCOUNT_PAIRS = 128
+)
+
+// Prices follow the structure of EIP-1962 schedule, addition is a lookup,
+// multiplication is linear in group order limbs, multiexp is discounted
+// multiplication and pairings are linear in loop and exponent parameters.
+// Every table is indexed by the number of 64 bit limbs of the base field
+// modulus, entries for less than four limbs are unused. Values are
+// benchmarked on single core at 20ns per gas.
+
+// addition prices for g1 and g2 over quadratic and cubic extensions
+var (
+	g1AdditionGas = [17]uint64{0, 0, 0, 0,
+		1133, 1312, 1530, 1788, 2086, 2423, 2800, 3217, 3673, 4169, 4705, 5280, 5895,
+	}
+	g2Ext2AdditionGas = [17]uint64{0, 0, 0, 0,
+		1724, 2694, 3879, 5280, 6896, 8727, 10774, 13037, 15515, 18208, 21117, 24241, 27581,
+	}
+	g2Ext3AdditionGas = [17]uint64{0, 0, 0, 0,
+		2221, 3471, 4997, 6802, 8884, 11243, 13881, 16795, 19988, 23458, 27205, 31231, 35533,
+	}
+)
+
+// multiplicationPrice keeps a multiplication price that is linear
+// in the number of limbs of the group order
+type multiplicationPrice struct {
+	base         uint64
+	perOrderLimb uint64
+}
+
+var (
+	g1MultiplicationGas = [17]multiplicationPrice{
+		{}, {}, {}, {},
+		{987, 1986},   // 4
+		{1163, 2716},  // 5
+		{1379, 3608},  // 6
+		{1635, 4662},  // 7
+		{1929, 5878},  // 8
+		{2263, 7256},  // 9
+		{2636, 8796},  // 10
+		{3048, 10499}, // 11
+		{3500, 12364}, // 12
+		{3991, 14390}, // 13
+		{4521, 16579}, // 14
+		{5091, 18930}, // 15
+		{5699, 21444}, // 16
+	}
+	g2Ext2MultiplicationGas = [17]multiplicationPrice{
+		{}, {}, {}, {},
+		{1502, 8047},    // 4
+		{1849, 11714},   // 5
+		{2274, 16169},   // 6
+		{2776, 21412},   // 7
+		{3355, 27443},   // 8
+		{4011, 34262},   // 9
+		{4745, 41869},   // 10
+		{5556, 50263},   // 11
+		{6444, 59446},   // 12
+		{7409, 69416},   // 13
+		{8451, 80174},   // 14
+		{9571, 91720},   // 15
+		{10768, 104054}, // 16
+	}
+	g2Ext3MultiplicationGas = [17]multiplicationPrice{
+		{}, {}, {}, {},
+		{1205, 14650},   // 4
+		{1883, 21510},   // 5
+		{2711, 29648},   // 6
+		{3690, 39065},   // 7
+		{4819, 49761},   // 8
+		{6099, 61736},   // 9
+		{7529, 74990},   // 10
+		{9110, 89523},   // 11
+		{10842, 105335}, // 12
+		{12724, 122425}, // 13
+		{14757, 140794}, // 14
+		{16940, 160442}, // 15
+		{19274, 181369}, // 16
+	}
+)
+
+// multiexp discounts for number of pairs, index 0 is unused
+var multiExpDiscount = [MAX_MULTIEXP_DISCOUNT_PAIRS + 1]uint64{0,
+	1615, 1224, 1041, 928, 849, 790, 743, 704, 672, 644, 620, 599, 580, 564, 548, 534,
+	522, 510, 499, 489, 480, 471, 462, 455, 447, 440, 434, 428, 422, 416, 411, 406,
+	401, 396, 391, 387, 383, 379, 375, 371, 368, 364, 361, 357, 354, 351, 348, 345,
+	342, 340, 337, 334, 332, 329, 327, 325, 322, 320, 318, 316, 314, 312, 310, 308,
+	306, 304, 302, 301, 299, 297, 296, 294, 292, 291, 289, 288, 286, 285, 283, 282,
+	281, 279, 278, 277, 275, 274, 273, 271, 270, 269, 268, 267, 266, 264, 263, 262,
+	261, 260, 259, 258, 257, 256, 255, 254, 253, 252, 251, 250, 249, 249, 248, 247,
+	246, 245, 244, 243, 243, 242, 241, 240, 239, 239, 238, 237, 236, 236, 235, 234,
+}
+
+// pairingPrice keeps coefficients of a pairing price. Miller loop terms are
+// paid per pair and per bit and per non zero bit of the loop parameter,
+// final exponentiation terms are paid once per bit and per non zero bit of
+// the exponent.
+type pairingPrice struct {
+	oneOff         uint64
+	perPair        uint64
+	perPairBit     uint64
+	perPairHamming uint64
+	perExpBit      uint64
+	perExpHamming  uint64
+}
+
+var (
+	blsPairingGas = [17]pairingPrice{
+		{}, {}, {}, {},
+		{19242, 2746, 197, 259, 837, 931},        // 4
+		{30065, 4290, 260, 405, 1200, 1249},      // 5
+		{43294, 6177, 337, 583, 1643, 1638},      // 6
+		{58927, 8407, 428, 793, 2167, 2097},      // 7
+		{76966, 10981, 532, 1036, 2772, 2627},    // 8
+		{97410, 13898, 651, 1311, 3458, 3228},    // 9
+		{120260, 17157, 784, 1618, 4224, 3900},   // 10
+		{145514, 20760, 930, 1958, 5070, 4642},   // 11
+		{173174, 24706, 1091, 2330, 5998, 5455},  // 12
+		{203238, 28996, 1265, 2734, 7006, 6339},  // 13
+		{235708, 33628, 1454, 3171, 8094, 7293},  // 14
+		{270584, 38603, 1656, 3640, 9263, 8318},  // 15
+		{307864, 43922, 1873, 4141, 10513, 9414}, // 16
+	}
+	bnPairingGas = [17]pairingPrice{
+		{}, {}, {}, {},
+		{43521, 980, 296, 259, 435, 686},        // 4
+		{68001, 1531, 400, 405, 679, 909},       // 5
+		{97921, 2204, 526, 583, 978, 1182},      // 6
+		{133281, 3000, 676, 793, 1331, 1505},    // 7
+		{174081, 3918, 849, 1036, 1738, 1877},   // 8
+		{220322, 4959, 1044, 1311, 2200, 2299},  // 9
+		{272002, 6122, 1263, 1618, 2716, 2770},  // 10
+		{329122, 7408, 1504, 1958, 3286, 3291},  // 11
+		{391683, 8815, 1769, 2330, 3911, 3862},  // 12
+		{459683, 10346, 2056, 2734, 4590, 4483}, // 13
+		{533123, 11999, 2367, 3171, 5323, 5152}, // 14
+		{612004, 13774, 2701, 3640, 6110, 5872}, // 15
+		{696324, 15672, 3057, 4141, 6952, 6641}, // 16
+	}
+	mnt4PairingGas = [17]pairingPrice{
+		{}, {}, {}, {},
+		{4223, 641, 209, 162, 15, 32},       // 4
+		{6599, 801, 277, 209, 23, 38},       // 5
+		{9502, 961, 361, 268, 32, 45},       // 6
+		{12933, 1121, 459, 337, 44, 54},     // 7
+		{16892, 1281, 573, 417, 57, 64},     // 8
+		{21379, 1441, 702, 507, 72, 75},     // 9
+		{26393, 1601, 847, 608, 89, 88},     // 10
+		{31936, 1761, 1006, 720, 107, 102},  // 11
+		{38006, 1921, 1181, 842, 128, 117},  // 12
+		{44604, 2081, 1371, 975, 150, 134},  // 13
+		{51730, 2241, 1576, 1119, 173, 152}, // 14
+		{59384, 2401, 1796, 1273, 199, 172}, // 15
+		{67566, 2561, 2031, 1437, 226, 192}, // 16
+	}
+	mnt6PairingGas = [17]pairingPrice{
+		{}, {}, {}, {},
+		{4253, 0, 365, 334, 42, 89},      // 4
+		{6646, 0, 501, 460, 59, 106},     // 5
+		{9569, 0, 661, 602, 80, 127},     // 6
+		{13025, 0, 845, 761, 105, 152},   // 7
+		{17012, 0, 1053, 936, 133, 181},  // 8
+		{21531, 0, 1285, 1129, 166, 213}, // 9
+		{26581, 0, 1541, 1338, 202, 250}, // 10
+		{32163, 0, 1821, 1564, 241, 290}, // 11
+		{38276, 0, 2125, 1807, 285, 334}, // 12
+		{44922, 0, 2453, 2067, 333, 382}, // 13
+		{52098, 0, 2805, 2344, 384, 433}, // 14
+		{59807, 0, 3181, 2637, 439, 489}, // 15
+		{68047, 0, 3581, 2947, 498, 548}, // 16
+	}
+)
+
+// mapping is only supported for BLS12-381
+const (
+	G1_MAP_GAS = 16500
+	G2_MAP_GAS = 187500
+)
+
+// Gas returns the price of the operation given by opType. Only the header
+// of the input is parsed, no field or curve arithmetic is performed,
+// so the price can be charged before the operation is run.
+func (api *API) Gas(opType int, in []byte) (uint64, error) {
+	decoder := newDecoder(in)
+	switch opType {
+	case OPERATION_G1_ADD:
+		return decoder.g1AddGas()
+	case OPERATION_G1_MUL:
+		return decoder.g1MulGas()
+	case OPERATION_G1_MULTIEXP:
+		return decoder.g1MultiExpGas()
+	case OPERATION_G2_ADD:
+		return decoder.g2AddGas()
+	case OPERATION_G2_MUL:
+		return decoder.g2MulGas()
+	case OPERATION_G2_MULTIEXP:
+		return decoder.g2MultiExpGas()
+	case OPERATION_BLS12PAIR, OPERATION_BLS12PAIR_GT:
+		return decoder.blsGas()
+	case OPERATION_BNPAIR, OPERATION_BNPAIR_GT:
+		return decoder.bnGas()
+	case OPERATION_MNT4PAIR, OPERATION_MNT4PAIR_GT:
+		return decoder.mnt4Gas()
+	case OPERATION_MNT6PAIR, OPERATION_MNT6PAIR_GT:
+		return decoder.mnt6Gas()
+	case OPERATION_G1_MAP:
+		return decoder.g1MapGas()
+	case OPERATION_G2_MAP:
+		return decoder.g2MapGas()
+	default:
+		return 0, errors.New(ERR_UNKNOWN_OPERATION)
+	}
+}
+
+// gasHeader keeps parameters that are decoded for pricing
+type gasHeader struct {
+	limbs      int
+	orderLimbs int
+	degree     int
+}
+
+func (h *gasHeader) addition() uint64 {
+	switch h.degree {
+	case EXTENSION_TWO_DEGREE:
+		return g2Ext2AdditionGas[h.limbs]
+	case EXTENSION_THREE_DEGREE:
+		return g2Ext3AdditionGas[h.limbs]
+	default:
+		return g1AdditionGas[h.limbs]
+	}
+}
+
+func (h *gasHeader) multiplication() uint64 {
+	price := g1MultiplicationGas[h.limbs]
+	switch h.degree {
+	case EXTENSION_TWO_DEGREE:
+		price = g2Ext2MultiplicationGas[h.limbs]
+	case EXTENSION_THREE_DEGREE:
+		price = g2Ext3MultiplicationGas[h.limbs]
+	}
+	return price.base + price.perOrderLimb*uint64(h.orderLimbs)
+}
+
+func (h *gasHeader) multiExp(numPairs int) uint64 {
+	discount := multiExpDiscount[MAX_MULTIEXP_DISCOUNT_PAIRS]
+	if numPairs <= MAX_MULTIEXP_DISCOUNT_PAIRS {
+		discount = multiExpDiscount[numPairs]
+	}
+	return uint64(numPairs) * h.multiplication() * discount / MULTIEXP_DISCOUNT_MULTIPLIER
+}
+
+func (decoder *decoder) skip(n int) error {
+	if _, err := decoder.read(n); err != nil {
+		return errors.New(ERR_INPUT_NOT_ENOUGH_FOR_FIELD_ELEMS)
+	}
+	return nil
+}
+
+// readModulusLimbs decodes modulus and resolves the limb size
+// in the same way with field construction
+func (decoder *decoder) readModulusLimbs(h *gasHeader) error {
+	modulusBuf, err := decoder.readModulus()
+	if err != nil {
+		return err
+	}
+	h.limbs = 4
+	if len(modulusBuf) >= 25 {
+		h.limbs = (new(big.Int).SetBytes(modulusBuf).BitLen() / 64) + 1
+	}
+	if h.limbs > 16 {
+		return errors.New(ERR_BASE_FIELD_CONSTRUCTION)
+	}
+	return nil
+}
+
+func (decoder *decoder) readOrderLimbs(h *gasHeader) error {
+	order, err := decoder.readGroupOrder()
+	if err != nil {
+		return err
+	}
+	h.orderLimbs = (order.BitLen() + 63) / 64
+	return nil
+}
+
+// readG1Header skips curve coefficients and reads group order
+func (decoder *decoder) readG1Header(h *gasHeader) error {
+	if err := decoder.readModulusLimbs(h); err != nil {
+		return err
+	}
+	h.degree = 1
+	if err := decoder.skip(2 * decoder.modulusLen()); err != nil {
+		return err
+	}
+	return decoder.readOrderLimbs(h)
+}
+
+// readG2Header skips non residue and curve coefficients and reads group order
+func (decoder *decoder) readG2Header(h *gasHeader) error {
+	if err := decoder.readModulusLimbs(h); err != nil {
+		return err
+	}
+	degreeBuf, err := decoder.read(EXTENSION_DEGREE_LENGTH_ENCODING)
+	if err != nil {
+		return errors.New(ERR_G2_CANT_DECODE_EXT_DEGREE_LENGTH)
+	}
+	h.degree = int(degreeBuf[0])
+	if h.degree != EXTENSION_TWO_DEGREE && h.degree != EXTENSION_THREE_DEGREE {
+		return errors.New(ERR_G2_UNEXPECTED_EXT_DEGREE)
+	}
+	if err := decoder.skip((1 + 2*h.degree) * decoder.modulusLen()); err != nil {
+		return err
+	}
+	return decoder.readOrderLimbs(h)
+}
+
+func (decoder *decoder) readNumPairs() (int, error) {
+	numPairs, err := decoder.readLength()
+	if err != nil {
+		return 0, errors.New(ERR_MULTIEXP_NUM_PAIRS_NOT_ENOUGH_BYTE)
+	}
+	if numPairs == 0 {
+		return 0, errors.New(ERR_MULTIEXP_NUM_PAIR_LENGTH)
+	}
+	return numPairs, nil
+}
+
+func (decoder *decoder) g1AddGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG1Header(h); err != nil {
+		return 0, err
+	}
+	return h.addition(), nil
+}
+
+func (decoder *decoder) g1MulGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG1Header(h); err != nil {
+		return 0, err
+	}
+	return h.multiplication(), nil
+}
+
+func (decoder *decoder) g1MultiExpGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG1Header(h); err != nil {
+		return 0, err
+	}
+	numPairs, err := decoder.readNumPairs()
+	if err != nil {
+		return 0, err
+	}
+	return h.multiExp(numPairs), nil
+}
+
+func (decoder *decoder) g2AddGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG2Header(h); err != nil {
+		return 0, err
+	}
+	return h.addition(), nil
+}
+
+func (decoder *decoder) g2MulGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG2Header(h); err != nil {
+		return 0, err
+	}
+	return h.multiplication(), nil
+}
+
+func (decoder *decoder) g2MultiExpGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG2Header(h); err != nil {
+		return 0, err
+	}
+	numPairs, err := decoder.readNumPairs()
+	if err != nil {
+		return 0, err
+	}
+	return h.multiExp(numPairs), nil
+}
+
+func (decoder *decoder) g1MapGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG1Header(h); err != nil {
+		return 0, err
+	}
+	return G1_MAP_GAS, nil
+}
+
+func (decoder *decoder) g2MapGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG2Header(h); err != nil {
+		return 0, err
+	}
+	if h.degree != EXTENSION_TWO_DEGREE {
+		return 0, errors.New(ERR_MAP_UNSUPPORTED_CURVE)
+	}
+	return G2_MAP_GAS, nil
+}
+
+func (decoder *decoder) readPairingNumPairs() (int, error) {
+	numPairs, err := decoder.readLength()
+	if err != nil {
+		return 0, err
+	}
+	if numPairs == 0 {
+		return 0, errors.New(ERR_PAIRING_NUM_PAIRS_ZERO)
+	}
+	return numPairs, nil
+}
+
+// paramGas returns bit length and hamming weight of a pairing parameter
+func paramGas(param *big.Int) (uint64, uint64) {
+	return uint64(param.BitLen()), uint64(calculateHammingWeight(param))
+}
+
+// pairingGas prices a pairing of numPairs pairs. Points of each pair are
+// charged for a subgroup check since flags are in the body of the input.
+func pairingGas(price *pairingPrice, h *gasHeader, degree int, numPairs int, loopBits, loopHamming, expBits, expHamming uint64) uint64 {
+	h2 := &gasHeader{h.limbs, h.orderLimbs, degree}
+	perPair := price.perPair + loopBits*price.perPairBit + loopHamming*price.perPairHamming +
+		h.multiplication() + h2.multiplication()
+	return price.oneOff + uint64(numPairs)*perPair + expBits*price.perExpBit + expHamming*price.perExpHamming
+}
+
+func (decoder *decoder) readSexticTwistHeader(h *gasHeader) error {
+	// g1 curve
+	if err := decoder.readG1Header(h); err != nil {
+		return err
+	}
+	// fp2 and fp6 non residues
+	if err := decoder.skip(3 * decoder.modulusLen()); err != nil {
+		return err
+	}
+	if _, err := decoder.readTwistType(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (decoder *decoder) blsGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readSexticTwistHeader(h); err != nil {
+		return 0, err
+	}
+	z, _, err := decoder.readBLSLoopParam()
+	if err != nil {
+		return 0, err
+	}
+	numPairs, err := decoder.readPairingNumPairs()
+	if err != nil {
+		return 0, err
+	}
+	// x is both the miller loop parameter and the final exponentiation parameter
+	zBits, zHamming := paramGas(z)
+	return pairingGas(&blsPairingGas[h.limbs], h, EXTENSION_TWO_DEGREE, numPairs, zBits, zHamming, zBits, zHamming), nil
+}
+
+func (decoder *decoder) bnGas() (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readSexticTwistHeader(h); err != nil {
+		return 0, err
+	}
+	u, _, sixUPlus2, err := decoder.readBNLoopParam()
+	if err != nil {
+		return 0, err
+	}
+	numPairs, err := decoder.readPairingNumPairs()
+	if err != nil {
+		return 0, err
+	}
+	// miller loop runs over 6u + 2 and final exponentiation over u
+	loopBits, loopHamming := paramGas(sixUPlus2)
+	uBits, uHamming := paramGas(u)
+	return pairingGas(&bnPairingGas[h.limbs], h, EXTENSION_TWO_DEGREE, numPairs, loopBits, loopHamming, uBits, uHamming), nil
+}
+
+func (decoder *decoder) mntGas(price *[17]pairingPrice, degree int) (uint64, error) {
+	h := new(gasHeader)
+	if err := decoder.readG1Header(h); err != nil {
+		return 0, err
+	}
+	// non residue
+	if err := decoder.skip(decoder.modulusLen()); err != nil {
+		return 0, err
+	}
+	p, err := decoder.readMNTLoopParams()
+	if err != nil {
+		return 0, err
+	}
+	numPairs, err := decoder.readPairingNumPairs()
+	if err != nil {
+		return 0, err
+	}
+	// miller loop runs over x and hard part of final exponentiation over w0 and w1
+	xBits, xHamming := paramGas(p.x)
+	w0Bits, w0Hamming := paramGas(p.expW0)
+	w1Bits, w1Hamming := paramGas(p.expW1)
+	return pairingGas(&price[h.limbs], h, degree, numPairs, xBits, xHamming, w0Bits+w1Bits, w0Hamming+w1Hamming), nil
+}
+
+func (decoder *decoder) mnt4Gas() (uint64, error) {
+	return decoder.mntGas(&mnt4PairingGas, EXTENSION_TWO_DEGREE)
+}
+
+func (decoder *decoder) mnt6Gas() (uint64, error) {
+	return decoder.mntGas(&mnt6PairingGas, EXTENSION_THREE_DEGREE)
+}
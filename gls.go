@@ -0,0 +1,238 @@
+package eip
+
+import (
+	"math/big"
+	"sync"
+)
+
+// glsParams is the endomorphism psi = untwist-frobenius-twist of a sextic
+// twist y^2 = x^3 + b' over fq2 of a curve y^2 = x^3 + b over fq. With w^6 =
+// xi untwisting is (x * w^2, y * w^3) for D type, b' = b / xi, and
+// (x / w^2, y / w^3) for M type, b' = b * xi. psi satisfies
+// psi^2 - t * psi + p = 0 on every point and acts as [p] = [t - 1] on the
+// subgroup.
+type glsParams struct {
+	// psi(x, y) = (cx * conj(x), cy * conj(y))
+	cx, cy *fe2
+	// c is t - 1 where t is the trace of the untwisted curve
+	c *big.Int
+	// n is the number of points of the twist over fq2
+	n *big.Int
+	// subgroupTest reports whether psi(P) = [c]P is the same as membership
+	// to the subgroup. Kernel of psi - c has c^2 - t * c + p = p + 1 - t
+	// points, so it is if gcd(p + 1 - t, n) is the order.
+	subgroupTest bool
+	// basis is a reduced basis of the lattice of (k0, k1, k2, k3) with
+	// k0 + k1 * c + k2 * c^2 + k3 * c^3 = 0 mod order and inverse is the first
+	// row of its inverse, found on first decomposition
+	lattice sync.Once
+	basis   [4][4]*big.Int
+	inverse [4]*big.Rat
+	q       *big.Int
+}
+
+// gls finds endomorphism parameters on first use
+type gls struct {
+	once      sync.Once
+	xi        *fe2
+	twistType int
+	params    *glsParams
+}
+
+func newGLS(xi *fe2, twistType int) *gls {
+	return &gls{xi: xi, twistType: twistType}
+}
+
+// newGLSParams returns nil if psi is not defined for the twist
+func newGLSParams(f *fq2, b *fe2, q *big.Int, xi *fe2, twistType int) *glsParams {
+	fq := f.fq()
+	p, one, three := fq.modulus(), big.NewInt(1), big.NewInt(3)
+	pMinus1 := new(big.Int).Sub(p, one)
+	if new(big.Int).Mod(pMinus1, three).Sign() != 0 || q.Cmp(three) <= 0 || !q.ProbablyPrime(0) {
+		return nil
+	}
+	params := &glsParams{cx: f.new(), cy: f.new(), q: new(big.Int).Set(q)}
+	// cx = xi^((p - 1) / 3) and cy = xi^((p - 1) / 2)
+	f.exp(params.cy, xi, new(big.Int).Div(pMinus1, big.NewInt(6)))
+	f.square(params.cx, params.cy)
+	f.mul(params.cy, params.cy, params.cx)
+	untwisted := f.new()
+	switch twistType {
+	case TWIST_D:
+		f.mul(untwisted, b, xi)
+	case TWIST_M:
+		if !f.inverse(params.cx, params.cx) || !f.inverse(params.cy, params.cy) || !f.inverse(untwisted, xi) {
+			return nil
+		}
+		f.mul(untwisted, b, untwisted)
+	default:
+		return nil
+	}
+	// untwisted curve is defined over fq for psi to be an endomorphism
+	if !fq.isZero(untwisted[1]) {
+		return nil
+	}
+	glv := newGLVParams(fq, untwisted[0], q)
+	if glv == nil || new(big.Int).Mod(glv.n, q).Sign() != 0 {
+		return nil
+	}
+	// c = p - n
+	params.c = new(big.Int).Sub(p, glv.n)
+	// frobenius of the twist over fq2 is u * frobenius^2 where u is the
+	// unit of xi^((p^2 - 1) / 6) = norm(xi)^((p - 1) / 6), conjugate for M
+	// type
+	zeta, conj := f.new(), f.new()
+	f.conjugate(conj, xi)
+	f.mul(zeta, xi, conj)
+	fq.exp(zeta[0], zeta[0], new(big.Int).Div(pMinus1, big.NewInt(6)))
+	w, residue := fq.toBig(glv.beta), fq.toBig(zeta[0])
+	var u eisenstein
+	unit := newEisenstein(1, 0)
+	for i := 0; i < 6; i++ {
+		if unit.mod(w, p).Cmp(residue) == 0 {
+			u = unit
+			break
+		}
+		unit = unit.mul(newEisenstein(0, -1))
+	}
+	if u[0] == nil {
+		return nil
+	}
+	if twistType == TWIST_M {
+		u = u.conjugate()
+	}
+	frobenius := u.mul(glv.frobenius.mul(glv.frobenius))
+	frobenius[0].Sub(frobenius[0], one)
+	params.n = frobenius.norm()
+	params.subgroupTest = new(big.Int).GCD(nil, nil, glv.n, params.n).Cmp(q) == 0
+	return params
+}
+
+func (params *glsParams) reduceLattice() {
+	// (-c^i, e_i) and (q, 0, 0, 0) generate the lattice
+	q := params.q
+	c := new(big.Int).Mod(params.c, q)
+	power := new(big.Int).Set(c)
+	params.basis[0] = [4]*big.Int{new(big.Int).Set(q), big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+	for i := 1; i < 4; i++ {
+		params.basis[i] = [4]*big.Int{new(big.Int).Neg(power), big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+		params.basis[i][i].SetInt64(1)
+		power = new(big.Int).Mod(power.Mul(power, c), q)
+	}
+	reduceLattice(params.basis[:])
+	// basis of a full rank lattice is not singular
+	params.inverse = firstRowOfInverse(params.basis)
+}
+
+// decompose returns k_i such that sum of [k_i]psi^i(P) = [k]P for points of
+// the subgroup
+func (params *glsParams) decompose(k *big.Int) [4]*big.Int {
+	params.lattice.Do(params.reduceLattice)
+	k = new(big.Int).Mod(k, params.q)
+	out := [4]*big.Int{new(big.Int).Set(k), big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+	for i, v := range params.inverse {
+		t := new(big.Rat).Mul(new(big.Rat).SetInt(k), v)
+		a := roundDiv(t.Num(), t.Denom())
+		for j := range out {
+			out[j].Sub(out[j], new(big.Int).Mul(a, params.basis[i][j]))
+		}
+	}
+	return out
+}
+
+// reduceLattice is lll reduction with delta = 3/4, gram schmidt vectors are
+// recomputed after each step as lattices are small
+func reduceLattice(basis [][4]*big.Int) {
+	n := len(basis)
+	delta := big.NewRat(3, 4)
+	for k := 1; k < n; {
+		for j := k - 1; j >= 0; j-- {
+			_, mu := gramSchmidt(basis)
+			m := roundDiv(mu[k][j].Num(), mu[k][j].Denom())
+			for i := range basis[k] {
+				basis[k][i] = new(big.Int).Sub(basis[k][i], new(big.Int).Mul(m, basis[j][i]))
+			}
+		}
+		norms, mu := gramSchmidt(basis)
+		// lovasz condition
+		bound := new(big.Rat).Mul(mu[k][k-1], mu[k][k-1])
+		bound.Sub(delta, bound)
+		bound.Mul(bound, norms[k-1])
+		if norms[k].Cmp(bound) >= 0 {
+			k++
+			continue
+		}
+		basis[k], basis[k-1] = basis[k-1], basis[k]
+		if k > 1 {
+			k--
+		}
+	}
+}
+
+// gramSchmidt returns squared norms of orthogonalized vectors and their
+// coefficients
+func gramSchmidt(basis [][4]*big.Int) ([]*big.Rat, [][]*big.Rat) {
+	n := len(basis)
+	orthogonal := make([][4]*big.Rat, n)
+	norms, mu := make([]*big.Rat, n), make([][]*big.Rat, n)
+	dot := func(a, b [4]*big.Rat) *big.Rat {
+		s := new(big.Rat)
+		for i := range a {
+			s.Add(s, new(big.Rat).Mul(a[i], b[i]))
+		}
+		return s
+	}
+	for i := 0; i < n; i++ {
+		mu[i] = make([]*big.Rat, n)
+		for l := range orthogonal[i] {
+			orthogonal[i][l] = new(big.Rat).SetInt(basis[i][l])
+		}
+		v := orthogonal[i]
+		for j := 0; j < i; j++ {
+			mu[i][j] = new(big.Rat).Quo(dot(v, orthogonal[j]), norms[j])
+			for l := range orthogonal[i] {
+				orthogonal[i][l].Sub(orthogonal[i][l], new(big.Rat).Mul(mu[i][j], orthogonal[j][l]))
+			}
+		}
+		norms[i] = dot(orthogonal[i], orthogonal[i])
+	}
+	return norms, mu
+}
+
+// firstRowOfInverse solves x * basis = (1, 0, 0, 0) with gauss jordan
+// elimination, basis is expected to be non singular
+func firstRowOfInverse(basis [4][4]*big.Int) [4]*big.Rat {
+	// rows of the augmented transpose
+	var m [4][5]*big.Rat
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			m[i][j] = new(big.Rat).SetInt(basis[j][i])
+		}
+		m[i][4] = new(big.Rat)
+	}
+	m[0][4].SetInt64(1)
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for i := col; i < 4; i++ {
+			if m[i][col].Sign() != 0 {
+				pivot = i
+				break
+			}
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for i := 0; i < 4; i++ {
+			if i == col || m[i][col].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Quo(m[i][col], m[col][col])
+			for j := col; j < 5; j++ {
+				m[i][j] = new(big.Rat).Sub(m[i][j], new(big.Rat).Mul(factor, m[col][j]))
+			}
+		}
+	}
+	var out [4]*big.Rat
+	for i := 0; i < 4; i++ {
+		out[i] = new(big.Rat).Quo(m[i][4], m[i][i])
+	}
+	return out
+}
@@ -0,0 +1,233 @@
+package eip
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func glsTestGroups(t *testing.T) []*builder {
+	return []*builder{
+		// m type twist
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS")),
+		// d type twist
+		testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN")),
+	}
+}
+
+func pairingG2(v *builder) *g22 {
+	if v.family == "BN" {
+		return v.bn().(bnTest).bn.g2
+	}
+	return v.bls().(blsTest).bls.g2
+}
+
+func TestGLSParams(t *testing.T) {
+	for _, v := range glsTestGroups(t) {
+		t.Run(v.tag, func(t *testing.T) {
+			g := pairingG2(v)
+			params := g.endomorphism()
+			if params == nil {
+				t.Fatal("psi is expected to be available")
+			}
+			// number of points of the twist
+			c0, c1 := g.newPoint(), g.newPoint()
+			for i := 0; i < 4; i++ {
+				p := randCurvePointG22(g)
+				if !g.isZero(g.wnafMul(c0, p, params.n)) {
+					t.Fatal("point is not killed by the number of points of the twist")
+				}
+				// psi^2 - t * psi + p = 0 on every point
+				t1 := new(big.Int).Add(params.c, big.NewInt(1))
+				g.psi(c0, p, params)
+				g.psi(c0, c0, params)
+				g.wnafMul(c1, g.psi(c1, p, params), new(big.Int).Abs(t1))
+				if t1.Sign() < 0 {
+					g.neg(c1, c1)
+				}
+				g.sub(c0, c0, c1)
+				g.add(c0, c0, g.wnafMul(c1, p, g.f.fq().modulus()))
+				if !g.isZero(c0) {
+					t.Fatal("psi does not satisfy characteristic polynomial of frobenius")
+				}
+			}
+			G2 := v.G22().(*pointG22)
+			g.psi(c0, G2, params)
+			g.wnafMul(c1, G2, new(big.Int).Mod(params.c, g.q))
+			if !g.equal(c0, c1) {
+				t.Fatal("psi does not act as t - 1 on the subgroup")
+			}
+			if !params.subgroupTest {
+				t.Fatal("psi subgroup test is expected to be exact")
+			}
+		})
+	}
+}
+
+func TestGLSMul(t *testing.T) {
+	for _, v := range glsTestGroups(t) {
+		t.Run(v.tag, func(t *testing.T) {
+			g := pairingG2(v)
+			params := g.endomorphism()
+			G2 := v.G22().(*pointG22)
+			c0, c1, p := g.newPoint(), g.newPoint(), g.newPoint()
+			for i := 0; i < 8; i++ {
+				g.wnafMul(p, G2, randScalar(g.q))
+				e := randScalar(new(big.Int).Lsh(g.q, 1))
+				for _, k := range params.decompose(e) {
+					if k.BitLen() > g.q.BitLen()/4+8 {
+						t.Fatal("quarters of the scalar are not short")
+					}
+				}
+				g.wnafMul(c0, p, e)
+				if !g.equal(c0, g.glsMul(c1, p, e, params)) {
+					t.Fatal("gls multiplication does not match")
+				}
+				if !g.equal(c0, g.mulScalar(c1, p, e)) {
+					t.Fatal("multiplication of point of the subgroup does not match")
+				}
+				// points out of the subgroup fall back
+				r := randCurvePointG22(g)
+				expected := g.isZero(g.wnafMul(c0, r, g.q))
+				if g.checkCorrectSubgroup(r) != expected {
+					t.Fatal("psi subgroup test does not match multiplication by order")
+				}
+				g.wnafMul(c0, r, e)
+				if !g.equal(c0, g.mulScalar(c1, r, e)) {
+					t.Fatal("multiplication of point out of the subgroup does not match")
+				}
+			}
+			if !g.isZero(g.glsMul(c0, g.zero(), randScalar(g.q), params)) {
+				t.Fatal("multiplication of infinity is expected to be zero")
+			}
+			if !g.checkCorrectSubgroup(g.zero()) {
+				t.Fatal("point at infinity is rejected")
+			}
+		})
+	}
+}
+
+func TestGLSMultiExp(t *testing.T) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := pairingG2(v)
+	G2 := v.G22().(*pointG22)
+	for _, n := range []int{0, 1, 2, g22GLSMultiExpThreshold, g22GLSMultiExpThreshold + 1} {
+		points, scalars := make([]*pointG22, n), make([]*big.Int, n)
+		expected, c := g.zero(), g.newPoint()
+		for i := 0; i < n; i++ {
+			points[i], scalars[i] = g.wnafMul(g.newPoint(), G2, randScalar(g.q)), randScalar(g.q)
+			g.add(expected, expected, g.wnafMul(c, points[i], scalars[i]))
+		}
+		if _, err := g.multiExp(c, points, scalars); err != nil {
+			t.Fatal(err)
+		}
+		if !g.equal(c, expected) {
+			t.Fatal("multiexp with psi does not match", n)
+		}
+		// a point out of the subgroup falls back
+		if n > 0 {
+			points[0] = randCurvePointG22(g)
+			expected := g.zero()
+			for i := 0; i < n; i++ {
+				g.add(expected, expected, g.wnafMul(c, points[i], scalars[i]))
+			}
+			if _, err := g.multiExp(c, points, scalars); err != nil {
+				t.Fatal(err)
+			}
+			if !g.equal(c, expected) {
+				t.Fatal("multiexp with point out of the subgroup does not match", n)
+			}
+		}
+	}
+}
+
+func TestGLSClearCofactor(t *testing.T) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := pairingG2(v)
+	params := g.endomorphism()
+	hEff, _ := new(big.Int).SetString(bls12381G2HEff[2:], 16)
+	z, _ := new(big.Int).SetString(bls12381Z, 0)
+	c0, c1 := g.newPoint(), g.newPoint()
+	for i := 0; i < 8; i++ {
+		p := randCurvePointG22(g)
+		g.wnafMul(c0, p, hEff)
+		g.clearCofactorBLS12(c1, p, z, params)
+		if !g.equal(c0, c1) {
+			t.Fatal("psi cofactor clearing does not match multiplication by hEff")
+		}
+		if !g.checkCorrectSubgroup(c1) {
+			t.Fatal("cleared point is not in the subgroup")
+		}
+	}
+}
+
+func TestGLSUnavailable(t *testing.T) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := pairingG2(v)
+	// twist type does not match the curve
+	if newGLSParams(g.f, g.b, g.q, g.gls.xi, TWIST_D) != nil {
+		t.Fatal("psi is not expected to be defined for wrong twist type")
+	}
+	// orders of test curves of the family are not prime
+	for _, file := range []string{"bls12/256.json", "bls12/320.json"} {
+		if pairingG2(testBuilderFromFile(t, file, newBuilderOptPairing("BLS"))).endomorphism() != nil {
+			t.Fatal("psi is not expected to be available for composite order", file)
+		}
+	}
+	// group without the pairing engine
+	g2 := g.clone(g.f)
+	g2.gls = nil
+	if g2.endomorphism() != nil {
+		t.Fatal("psi is not expected to be available")
+	}
+}
+
+func BenchmarkGLS(t *testing.B) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := pairingG2(v)
+	p, c, e := v.G22().(*pointG22), g.newPoint(), randScalar(g.q)
+	params := g.endomorphism()
+	t.Run("params", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			newGLSParams(g.f, g.b, g.q, g.gls.xi, g.gls.twistType)
+		}
+	})
+	t.Run("gls", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.glsMul(c, p, e, params)
+		}
+	})
+	t.Run("wnaf", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.wnafMul(c, p, e)
+		}
+	})
+	for _, n := range []int{2, 16, 32} {
+		points, scalars := make([]*pointG22, n), make([]*big.Int, n)
+		for i := range points {
+			points[i], scalars[i] = g.wnafMul(g.newPoint(), p, randScalar(g.q)), randScalar(g.q)
+		}
+		t.Run(fmt.Sprintf("multiexp_%d", n), func(t *testing.B) {
+			for i := 0; i < t.N; i++ {
+				g.multiExp(c, points, scalars)
+			}
+		})
+	}
+	t.Run("psi_subgroup_check", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.checkSubgroupWithPsi(p, params)
+		}
+	})
+	hEff, _ := new(big.Int).SetString(bls12381G2HEff[2:], 16)
+	z, _ := new(big.Int).SetString(bls12381Z, 0)
+	t.Run("clear_cofactor_psi", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.clearCofactorBLS12(c, p, z, params)
+		}
+	})
+	t.Run("clear_cofactor_hEff", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.wnafMul(c, p, hEff)
+		}
+	})
+}
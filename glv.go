@@ -0,0 +1,231 @@
+package eip
+
+import (
+	"math/big"
+	"sync"
+)
+
+// glvMinBits is the scalar size from which multiplication is split with the
+// endomorphism
+const glvMinBits = 64
+
+// glvParams is the endomorphism phi(x, y) = (beta * x, y) of a curve with
+// a = 0 over a prime field with p = 1 mod 3, where beta is a cube root of
+// unity. Endomorphism ring is Z[w] with w^2 + w + 1 = 0 and w acting as phi.
+// Frobenius is an element of norm p and scalars are decomposed modulo the
+// lattice of multiples of frobenius - 1 that is zero on every rational point,
+// so that the result is the same as plain multiplication for points out of
+// the subgroup too. Frobenius is found with the sextic residue of 4b,
+// Ireland & Rosen, ch. 18, th. 4.
+type glvParams struct {
+	beta fe
+	// lambda is the eigenvalue of phi on the subgroup, nil if the order of
+	// the subgroup does not divide the number of points
+	lambda *big.Int
+	// n is the number of rational points
+	n *big.Int
+	// frobenius is the element of norm p that acts as frobenius
+	frobenius eisenstein
+	// v1, v2 is the reduced basis of the lattice and det is its determinant
+	v1, v2 eisenstein
+	det    *big.Int
+}
+
+// glv finds endomorphism parameters on first use
+type glv struct {
+	once   sync.Once
+	params *glvParams
+}
+
+// eisenstein is x + y * w
+type eisenstein [2]*big.Int
+
+func newEisenstein(x, y int64) eisenstein {
+	return eisenstein{big.NewInt(x), big.NewInt(y)}
+}
+
+func (a eisenstein) mul(b eisenstein) eisenstein {
+	// (a0 + a1 * w)(b0 + b1 * w) = a0 * b0 - a1 * b1 + (a0 * b1 + a1 * b0 - a1 * b1) * w
+	t := new(big.Int).Mul(a[1], b[1])
+	x := new(big.Int).Mul(a[0], b[0])
+	x.Sub(x, t)
+	y := new(big.Int).Mul(a[0], b[1])
+	y.Add(y, new(big.Int).Mul(a[1], b[0]))
+	y.Sub(y, t)
+	return eisenstein{x, y}
+}
+
+func (a eisenstein) conjugate() eisenstein {
+	return eisenstein{new(big.Int).Sub(a[0], a[1]), new(big.Int).Neg(a[1])}
+}
+
+// dot is twice the bilinear form of the norm
+func (a eisenstein) dot(b eisenstein) *big.Int {
+	t := new(big.Int).Mul(a[0], b[0])
+	t.Add(t, new(big.Int).Mul(a[1], b[1]))
+	t.Lsh(t, 1)
+	t.Sub(t, new(big.Int).Mul(a[0], b[1]))
+	t.Sub(t, new(big.Int).Mul(a[1], b[0]))
+	return t
+}
+
+func (a eisenstein) norm() *big.Int {
+	return new(big.Int).Rsh(a.dot(a), 1)
+}
+
+// mod returns the image of x + y * w under w -> root
+func (a eisenstein) mod(root, p *big.Int) *big.Int {
+	t := new(big.Int).Mul(a[1], root)
+	t.Add(t, a[0])
+	return t.Mod(t, p)
+}
+
+// roundDiv returns a / b rounded to the nearest integer
+func roundDiv(a, b *big.Int) *big.Int {
+	if b.Sign() < 0 {
+		a, b = new(big.Int).Neg(a), new(big.Int).Neg(b)
+	}
+	t := new(big.Int).Lsh(a, 1)
+	t.Add(t, b)
+	return t.Div(t, new(big.Int).Lsh(b, 1))
+}
+
+// reduceBasis is gauss reduction of a two dimensional lattice under the norm
+func reduceBasis(u, v eisenstein) (eisenstein, eisenstein) {
+	if u.dot(u).Cmp(v.dot(v)) > 0 {
+		u, v = v, u
+	}
+	for {
+		m := roundDiv(u.dot(v), u.dot(u))
+		v = eisenstein{
+			new(big.Int).Sub(v[0], new(big.Int).Mul(m, u[0])),
+			new(big.Int).Sub(v[1], new(big.Int).Mul(m, u[1])),
+		}
+		if v.dot(v).Cmp(u.dot(u)) >= 0 {
+			return u, v
+		}
+		u, v = v, u
+	}
+}
+
+// newGLVParams returns nil if the curve y^2 = x^3 + b has no such
+// endomorphism
+func newGLVParams(f *fq, b fe, q *big.Int) *glvParams {
+	p, one, three := f.modulus(), big.NewInt(1), big.NewInt(3)
+	pMinus1 := new(big.Int).Sub(p, one)
+	if p.Cmp(three) <= 0 || new(big.Int).Mod(pMinus1, three).Sign() != 0 {
+		return nil
+	}
+	// sextic residue of 4b is a sixth root of unity, cube root of unity w is
+	// taken from it if it is not 1 or -1, (-1 + sqrt(-3)) / 2 otherwise
+	t, u := f.new(), f.new()
+	f.double(t, b)
+	f.double(t, t)
+	f.exp(t, t, new(big.Int).Div(pMinus1, big.NewInt(6)))
+	residue := f.toBig(t)
+	w := new(big.Int)
+	if f.isOne(t) || residue.Cmp(pMinus1) == 0 {
+		f.double(u, f.one)
+		f.add(u, u, f.one)
+		f.neg(u, u)
+		if !f.sqrt(u, u) {
+			return nil
+		}
+		w.Sub(f.toBig(u), one)
+		if w.Bit(0) == 1 {
+			w.Add(w, p)
+		}
+		w.Rsh(w, 1)
+	} else {
+		f.square(u, t)
+		f.mul(u, u, t)
+		if w.Set(residue); !f.isOne(u) {
+			w.Sub(p, residue)
+		}
+	}
+	// shortest vector of the lattice of x + y * w = 0 mod p is a prime of
+	// norm p above p
+	pi, _ := reduceBasis(eisenstein{p, big.NewInt(0)}, eisenstein{new(big.Int).Neg(w), big.NewInt(1)})
+	if pi.norm().Cmp(p) != 0 {
+		return nil
+	}
+	// associate of pi that is primary, x = 2 and y = 0 mod 3
+	omega := newEisenstein(0, 1)
+	for i := 0; ; i++ {
+		if i == 6 {
+			return nil
+		}
+		x, y := new(big.Int).Mod(pi[0], three), new(big.Int).Mod(pi[1], three)
+		if x.Int64() == 2 && y.Sign() == 0 {
+			break
+		}
+		if i == 2 {
+			pi = eisenstein{new(big.Int).Neg(pi[0]), new(big.Int).Neg(pi[1])}
+		} else {
+			pi = pi.mul(omega)
+		}
+	}
+	// sextic residue symbol of 4b is the unit that is congruent to the
+	// residue modulo pi
+	var chi eisenstein
+	unit := newEisenstein(1, 0)
+	for i := 0; i < 6; i++ {
+		if unit.mod(w, p).Cmp(residue) == 0 {
+			chi = unit
+			break
+		}
+		// -w is a sixth root of unity
+		unit = unit.mul(newEisenstein(0, -1))
+	}
+	if chi[0] == nil {
+		return nil
+	}
+	// frobenius is -conj(chi) * pi, it is zero modulo pi as phi^* (dx / y) =
+	// beta * dx / y and frobenius is inseparable
+	frobenius := chi.conjugate().mul(pi)
+	frobenius[0].Neg(frobenius[0])
+	frobenius[1].Neg(frobenius[1])
+	m := eisenstein{new(big.Int).Sub(frobenius[0], one), frobenius[1]}
+	// lattice is generated by m and m * w
+	v1, v2 := reduceBasis(m, m.mul(omega))
+	params := &glvParams{
+		n:         m.norm(),
+		frobenius: frobenius,
+		v1:        v1,
+		v2:        v2,
+		det: new(big.Int).Sub(
+			new(big.Int).Mul(v1[0], v2[1]),
+			new(big.Int).Mul(v1[1], v2[0]),
+		),
+	}
+	if params.det.Sign() == 0 {
+		return nil
+	}
+	var err error
+	if params.beta, err = f.fromBig(w); err != nil {
+		return nil
+	}
+	// frobenius is one on the subgroup, so (c - 1) + d * lambda = 0
+	if q.Sign() > 0 && new(big.Int).Mod(params.n, q).Sign() == 0 {
+		d := new(big.Int).Mod(frobenius[1], q)
+		if d.ModInverse(d, q) != nil {
+			lambda := new(big.Int).Neg(m[0])
+			lambda.Mul(lambda, d)
+			params.lambda = lambda.Mod(lambda, q)
+		}
+	}
+	return params
+}
+
+// decompose returns k1, k2 such that [k1]P + [k2]phi(P) = [k]P for every
+// rational point
+func (params *glvParams) decompose(k *big.Int) (*big.Int, *big.Int) {
+	k = new(big.Int).Mod(k, params.n)
+	c1 := roundDiv(new(big.Int).Mul(k, params.v2[1]), params.det)
+	c2 := roundDiv(new(big.Int).Neg(new(big.Int).Mul(k, params.v1[1])), params.det)
+	k1 := new(big.Int).Sub(k, new(big.Int).Mul(c1, params.v1[0]))
+	k1.Sub(k1, new(big.Int).Mul(c2, params.v2[0]))
+	k2 := new(big.Int).Mul(c1, params.v1[1])
+	k2.Add(k2, new(big.Int).Mul(c2, params.v2[1]))
+	return k1, k2.Neg(k2)
+}
@@ -0,0 +1,173 @@
+package eip
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// plainMul is double and add multiplication that does not use the
+// endomorphism
+func plainMul(g *g1, c, p *pointG1, e *big.Int) *pointG1 {
+	q := g.zero()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		g.double(q, q)
+		if e.Bit(i) == 1 {
+			g.add(q, q, p)
+		}
+	}
+	return g.copy(c, q)
+}
+
+func testGLVMul(t *testing.T, g *g1, params *glvParams) {
+	c0, c1, c2 := g.newPoint(), g.newPoint(), g.newPoint()
+	for i := 0; i < 8; i++ {
+		p := randCurvePointG1(g)
+		// points out of the subgroup are killed by the number of points
+		if !g.isZero(plainMul(g, c0, p, params.n)) {
+			t.Fatal("point is not killed by the number of points")
+		}
+		k1, k2 := params.decompose(params.n)
+		if k1.Sign() != 0 || k2.Sign() != 0 {
+			t.Fatal("number of points is not decomposed into zero")
+		}
+		bound := new(big.Int).Lsh(params.n, 1)
+		for _, e := range []*big.Int{randScalar(bound), randScalar(g.q), big.NewInt(1), new(big.Int).Sub(params.n, big.NewInt(1))} {
+			k1, k2 := params.decompose(e)
+			if k1.BitLen() > params.n.BitLen()/2+2 || k2.BitLen() > params.n.BitLen()/2+2 {
+				t.Fatal("halves of the scalar are not short")
+			}
+			plainMul(g, c0, p, e)
+			g.glvMul(c1, p, e, params)
+			if !g.equal(c0, c1) {
+				t.Fatal("glv multiplication does not match")
+			}
+			g.affine(c2, p)
+			g.glvMul(c2, c2, e, params)
+			if !g.equal(c0, c2) {
+				t.Fatal("glv multiplication of affine point does not match")
+			}
+		}
+	}
+	if !g.isZero(g.glvMul(c0, g.zero(), randScalar(g.q), params)) {
+		t.Fatal("multiplication of infinity is expected to be zero")
+	}
+}
+
+func TestGLVParams(t *testing.T) {
+	vectors := []*builder{
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS")),
+		testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN")),
+	}
+	for _, v := range vectors {
+		t.Run(v.tag, func(t *testing.T) {
+			g := v.g1()
+			params := g.endomorphism()
+			if params == nil {
+				t.Fatal("endomorphism is expected to be found")
+			}
+			if new(big.Int).Mod(params.n, g.q).Sign() != 0 {
+				t.Fatal("order of the subgroup does not divide the number of points")
+			}
+			if params.lambda == nil {
+				t.Fatal("eigenvalue is expected to be found")
+			}
+			// phi(P) = [lambda]P on the subgroup
+			G1 := v.G1().(*pointG1)
+			c0, c1 := g.newPoint(), g.newPoint()
+			g.phi(c0, G1, params)
+			plainMul(g, c1, G1, params.lambda)
+			if !g.equal(c0, c1) {
+				t.Fatal("phi does not act as lambda on the subgroup")
+			}
+			testGLVMul(t, g, params)
+		})
+	}
+}
+
+func TestGLVRandomCurves(t *testing.T) {
+	// number of points and frobenius depend on the sextic twist that is
+	// picked by b
+	for _, v := range []*builder{
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS")),
+		testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN")),
+	} {
+		f, q := v.fq(), big.NewInt(1)
+		for i := 0; i < 6; i++ {
+			g, err := newG1(f, f.zero, f.rand(rand.Reader), q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			params := g.endomorphism()
+			if params == nil {
+				t.Fatal("endomorphism is expected to be found")
+			}
+			testGLVMul(t, g, params)
+		}
+	}
+	// curves with a != 0 or p != 1 mod 3 have no such endomorphism
+	f, err := newField(padBytes(big.NewInt(29).Bytes(), 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := newG1(f, f.zero, f.one, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.endomorphism() != nil {
+		t.Fatal("p = 2 mod 3 is not expected to have endomorphism")
+	}
+	g, err = newG1(f, f.one, f.one, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.endomorphism() != nil {
+		t.Fatal("a != 0 is not expected to have endomorphism")
+	}
+}
+
+func TestGLVMultiExp(t *testing.T) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := v.g1()
+	for _, n := range []int{0, 1, 2, g1GLVMultiExpThreshold, g1GLVMultiExpThreshold + 1} {
+		points, scalars := make([]*pointG1, n), make([]*big.Int, n)
+		expected, c := g.zero(), g.newPoint()
+		for i := 0; i < n; i++ {
+			points[i], scalars[i] = randCurvePointG1(g), randScalar(g.q)
+			g.add(expected, expected, plainMul(g, c, points[i], scalars[i]))
+		}
+		if _, err := g.multiExp(c, points, scalars); err != nil {
+			t.Fatal(err)
+		}
+		if !g.equal(c, expected) {
+			t.Fatal("multiexp with endomorphism does not match", n)
+		}
+	}
+}
+
+func BenchmarkGLV(t *testing.B) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := v.g1()
+	p, c, e := v.G1().(*pointG1), g.newPoint(), randScalar(g.q)
+	params := g.endomorphism()
+	t.Run("detection", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			newGLVParams(g.f, g.b, g.q)
+		}
+	})
+	t.Run("glv", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.glvMul(c, p, e, params)
+		}
+	})
+	t.Run("wnaf", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g.wnafMul(c, p, e)
+		}
+	})
+	t.Run("plain", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			plainMul(g, c, p, e)
+		}
+	})
+}
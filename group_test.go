@@ -1,12 +1,17 @@
 package eip
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"math/big"
+	"runtime"
+	"sort"
 	"testing"
+	"time"
 )
 
 // group interface abstacts g1, g22, g23 groups from testing suite
@@ -17,8 +22,12 @@ type group interface {
 	fromBytesDense(in []byte) (point, error)
 	toBytes(p1 point) []byte
 	toBytesDense(p1 point) []byte
+	fromBytesCompressed(in []byte) (point, error)
+	toBytesCompressed(p1 point) []byte
 	equal(p1, p2 point) bool
 	affine(p1, p2 point)
+	batchAffine(p []point)
+	isAffine(p []point) bool
 	zero() point
 	Q() *big.Int
 	fieldModulus() *big.Int
@@ -26,12 +35,19 @@ type group interface {
 	isOnCurve(c point) bool
 	checkCorrectSubgroup(c point) bool
 	mulScalar(c, a point, e *big.Int) point
+	mulScalarCT(c, a point, e *big.Int) point
 	wnafMul(c, a point, e *big.Int) point
 	add(c, a, b point) point
+	addMixed(c, a, b point) point
 	sub(c, a, b point) point
 	neg(c, a point) point
 	double(c, a point) point
 	multiExp(c point, p []point, s []*big.Int) (point, error)
+	newFixedBaseTable(p point, bits int, window uint) (*FixedBaseTable, error)
+	mulFixedBase(c point, t *FixedBaseTable, e *big.Int) point
+	mulFixedBaseCT(c point, t *FixedBaseTable, e *big.Int) point
+	fixedBaseTableToBytes(t *FixedBaseTable) []byte
+	fixedBaseTableFromBytes(in []byte) (*FixedBaseTable, error)
 }
 
 // group interface abstacts points at g1, g22, g23 groups from testing suite
@@ -86,6 +102,22 @@ func (g g1Test) affine(r, p point) {
 	g.g1.affine(r.(*pointG1), p.(*pointG1))
 }
 
+func (g g1Test) batchAffine(p []point) {
+	P := make([]*pointG1, len(p))
+	for i := range p {
+		P[i] = p[i].(*pointG1)
+	}
+	g.g1.batchAffine(P)
+}
+
+func (g g1Test) isAffine(p []point) bool {
+	P := make([]*pointG1, len(p))
+	for i := range p {
+		P[i] = p[i].(*pointG1)
+	}
+	return g.g1.isAffine(P)
+}
+
 func (g g1Test) new() point {
 	return g.g1.newPoint()
 }
@@ -106,6 +138,10 @@ func (g g1Test) add(c, a, b point) point {
 	return g.g1.add(c.(*pointG1), a.(*pointG1), b.(*pointG1))
 }
 
+func (g g1Test) addMixed(c, a, b point) point {
+	return g.g1.addMixed(c.(*pointG1), a.(*pointG1), b.(*pointG1))
+}
+
 func (g g1Test) sub(c, a, b point) point {
 	return g.g1.sub(c.(*pointG1), a.(*pointG1), b.(*pointG1))
 }
@@ -122,6 +158,18 @@ func (g g1Test) mulScalar(c point, p point, s *big.Int) point {
 	return g.g1.mulScalar(c.(*pointG1), p.(*pointG1), s)
 }
 
+func (g g1Test) fromBytesCompressed(in []byte) (point, error) {
+	return g.g1.fromBytesCompressed(in)
+}
+
+func (g g1Test) toBytesCompressed(p point) []byte {
+	return g.g1.toBytesCompressed(p.(*pointG1))
+}
+
+func (g g1Test) mulScalarCT(c point, p point, s *big.Int) point {
+	return g.g1.mulScalarCT(c.(*pointG1), p.(*pointG1), s)
+}
+
 func (g g1Test) wnafMul(c point, p point, s *big.Int) point {
 	return g.g1.wnafMul(c.(*pointG1), p.(*pointG1), s)
 }
@@ -134,6 +182,18 @@ func (g g1Test) multiExp(c point, p []point, s []*big.Int) (point, error) {
 	return g.g1.multiExp(c.(*pointG1), p_, s)
 }
 
+func (g g1Test) newFixedBaseTable(p point, bits int, window uint) (*FixedBaseTable, error) {
+	return g.g1.newFixedBaseTable(p.(*pointG1), bits, window)
+}
+
+func (g g1Test) mulFixedBase(c point, t *FixedBaseTable, e *big.Int) point {
+	return g.g1.mulFixedBase(c.(*pointG1), t, e)
+}
+
+func (g g1Test) mulFixedBaseCT(c point, t *FixedBaseTable, e *big.Int) point {
+	return g.g1.mulFixedBaseCT(c.(*pointG1), t, e)
+}
+
 // g22Test wraps g22 to match group interface
 type g22Test struct {
 	*g22
@@ -190,6 +250,22 @@ func (g g22Test) affine(r, p point) {
 	g.g22.affine(r.(*pointG22), p.(*pointG22))
 }
 
+func (g g22Test) batchAffine(p []point) {
+	P := make([]*pointG22, len(p))
+	for i := range p {
+		P[i] = p[i].(*pointG22)
+	}
+	g.g22.batchAffine(P)
+}
+
+func (g g22Test) isAffine(p []point) bool {
+	P := make([]*pointG22, len(p))
+	for i := range p {
+		P[i] = p[i].(*pointG22)
+	}
+	return g.g22.isAffine(P)
+}
+
 func (g g22Test) new() point {
 	return g.g22.newPoint()
 }
@@ -210,6 +286,10 @@ func (g g22Test) add(c, a, b point) point {
 	return g.g22.add(c.(*pointG22), a.(*pointG22), b.(*pointG22))
 }
 
+func (g g22Test) addMixed(c, a, b point) point {
+	return g.g22.addMixed(c.(*pointG22), a.(*pointG22), b.(*pointG22))
+}
+
 func (g g22Test) sub(c, a, b point) point {
 	return g.g22.sub(c.(*pointG22), a.(*pointG22), b.(*pointG22))
 }
@@ -226,6 +306,18 @@ func (g g22Test) mulScalar(c point, p point, s *big.Int) point {
 	return g.g22.mulScalar(c.(*pointG22), p.(*pointG22), s)
 }
 
+func (g g22Test) fromBytesCompressed(in []byte) (point, error) {
+	return g.g22.fromBytesCompressed(in)
+}
+
+func (g g22Test) toBytesCompressed(p point) []byte {
+	return g.g22.toBytesCompressed(p.(*pointG22))
+}
+
+func (g g22Test) mulScalarCT(c point, p point, s *big.Int) point {
+	return g.g22.mulScalarCT(c.(*pointG22), p.(*pointG22), s)
+}
+
 func (g g22Test) wnafMul(c point, p point, s *big.Int) point {
 	return g.g22.wnafMul(c.(*pointG22), p.(*pointG22), s)
 }
@@ -238,6 +330,18 @@ func (g g22Test) multiExp(c point, p []point, s []*big.Int) (point, error) {
 	return g.g22.multiExp(c.(*pointG22), p_, s)
 }
 
+func (g g22Test) newFixedBaseTable(p point, bits int, window uint) (*FixedBaseTable, error) {
+	return g.g22.newFixedBaseTable(p.(*pointG22), bits, window)
+}
+
+func (g g22Test) mulFixedBase(c point, t *FixedBaseTable, e *big.Int) point {
+	return g.g22.mulFixedBase(c.(*pointG22), t, e)
+}
+
+func (g g22Test) mulFixedBaseCT(c point, t *FixedBaseTable, e *big.Int) point {
+	return g.g22.mulFixedBaseCT(c.(*pointG22), t, e)
+}
+
 // g23Test wraps g23 to match group interface
 type g23Test struct {
 	*g23
@@ -298,6 +402,22 @@ func (g g23Test) affine(r, p point) {
 	g.g23.affine(r.(*pointG23), p.(*pointG23))
 }
 
+func (g g23Test) batchAffine(p []point) {
+	P := make([]*pointG23, len(p))
+	for i := range p {
+		P[i] = p[i].(*pointG23)
+	}
+	g.g23.batchAffine(P)
+}
+
+func (g g23Test) isAffine(p []point) bool {
+	P := make([]*pointG23, len(p))
+	for i := range p {
+		P[i] = p[i].(*pointG23)
+	}
+	return g.g23.isAffine(P)
+}
+
 func (g g23Test) new() point {
 	return g.g23.newPoint()
 }
@@ -318,6 +438,10 @@ func (g g23Test) add(c, a, b point) point {
 	return g.g23.add(c.(*pointG23), a.(*pointG23), b.(*pointG23))
 }
 
+func (g g23Test) addMixed(c, a, b point) point {
+	return g.g23.addMixed(c.(*pointG23), a.(*pointG23), b.(*pointG23))
+}
+
 func (g g23Test) sub(c, a, b point) point {
 	return g.g23.sub(c.(*pointG23), a.(*pointG23), b.(*pointG23))
 }
@@ -334,6 +458,18 @@ func (g g23Test) mulScalar(c point, p point, s *big.Int) point {
 	return g.g23.mulScalar(c.(*pointG23), p.(*pointG23), s)
 }
 
+func (g g23Test) fromBytesCompressed(in []byte) (point, error) {
+	return g.g23.fromBytesCompressed(in)
+}
+
+func (g g23Test) toBytesCompressed(p point) []byte {
+	return g.g23.toBytesCompressed(p.(*pointG23))
+}
+
+func (g g23Test) mulScalarCT(c point, p point, s *big.Int) point {
+	return g.g23.mulScalarCT(c.(*pointG23), p.(*pointG23), s)
+}
+
 func (g g23Test) wnafMul(c point, p point, s *big.Int) point {
 	return g.g23.wnafMul(c.(*pointG23), p.(*pointG23), s)
 }
@@ -346,6 +482,18 @@ func (g g23Test) multiExp(c point, p []point, s []*big.Int) (point, error) {
 	return g.g23.multiExp(c.(*pointG23), p_, s)
 }
 
+func (g g23Test) newFixedBaseTable(p point, bits int, window uint) (*FixedBaseTable, error) {
+	return g.g23.newFixedBaseTable(p.(*pointG23), bits, window)
+}
+
+func (g g23Test) mulFixedBase(c point, t *FixedBaseTable, e *big.Int) point {
+	return g.g23.mulFixedBase(c.(*pointG23), t, e)
+}
+
+func (g g23Test) mulFixedBaseCT(c point, t *FixedBaseTable, e *big.Int) point {
+	return g.g23.mulFixedBaseCT(c.(*pointG23), t, e)
+}
+
 func ceilBitLen(b []byte) int {
 	// return (((len(b) - 1) / 8) + 1) * 8
 	return (((len(b)) / 64) + 1) * 64
@@ -410,7 +558,7 @@ func (v *vectorJSON) resolveExtentionDegree() int {
 // builder constructs fields, groups and as well as pairing engines
 // with given input values namely vectorJSON
 type builder struct {
-	t             *testing.T
+	t             testing.TB
 	limbSize      int
 	testInput     input
 	cache         builderCache
@@ -484,7 +632,7 @@ func newBuilderOpt(family string) *builderOpts {
 }
 
 // testBuilderFromVector parses json vector and outputs a test builder
-func testBuilderFromVector(t *testing.T, tag string, vectorJSON *vectorJSON, opts *builderOpts) *builder {
+func testBuilderFromVector(t testing.TB, tag string, vectorJSON *vectorJSON, opts *builderOpts) *builder {
 	// to parse negative hex values
 	maybeNegativeHex := func(hex string) (bool, []byte) {
 		if hex[:1] == "-" {
@@ -582,12 +730,12 @@ func testBuilderFromVector(t *testing.T, tag string, vectorJSON *vectorJSON, opt
 	return &builder
 }
 
-func testBuilderFromFile(t *testing.T, file string, opts *builderOpts) *builder {
+func testBuilderFromFile(t testing.TB, file string, opts *builderOpts) *builder {
 	vectorJSON, _ := readVectorFile(t, file)
 	return testBuilderFromVector(t, file, vectorJSON, opts)
 }
 
-func readVectorFile(t *testing.T, file string) (*vectorJSON, error) {
+func readVectorFile(t testing.TB, file string) (*vectorJSON, error) {
 	data, err := ioutil.ReadFile("test_vectors/" + file)
 	if err != nil {
 		t.Fatal(err)
@@ -1051,6 +1199,32 @@ func TestG2(t *testing.T) {
 	}
 }
 
+func TestCurveDiscriminant(t *testing.T) {
+	bls := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	mnt6 := testBuilderFromVector(t, "mnt6_320", mnt6320Vector, newBuilderOptPairing("MNT6"))
+	if bls.g1().isSingular() || bls.g22().isSingular() || mnt6.g23().isSingular() {
+		t.Fatal("curve is expected to be non singular")
+	}
+	// y^2 = x^3 - 3x + 2 = (x - 1)^2 (x + 2) over each field
+	f, q := bls.fq(), big.NewInt(1)
+	a, _ := f.fromBig(new(big.Int).Sub(f.modulus(), big.NewInt(3)))
+	b, _ := f.fromBig(big.NewInt(2))
+	g1, _ := newG1(f, a, b, q)
+	fq2 := bls.fq2()
+	a2, b2 := fq2.zero(), fq2.zero()
+	f.copy(a2[0], a)
+	f.copy(b2[0], b)
+	g22, _ := newG22(fq2, a2, b2, q)
+	fq3 := mnt6.fq3()
+	a3, b3 := fq3.zero(), fq3.zero()
+	a3[0], _ = fq3.f.fromBig(new(big.Int).Sub(fq3.f.modulus(), big.NewInt(3)))
+	b3[0], _ = fq3.f.fromBig(big.NewInt(2))
+	g23, _ := newG23(fq3, a3, b3, q)
+	if !g1.isSingular() || !g22.isSingular() || !g23.isSingular() {
+		t.Fatal("curve is expected to be singular")
+	}
+}
+
 func testG(t *testing.T, g group, one point, tag string) {
 	zero := g.zero()
 	randPoint := func() point {
@@ -1155,6 +1329,66 @@ func testG(t *testing.T, g group, one point, tag string) {
 			}
 		}
 	})
+	testName = tag + "_" + "mixed_addition"
+	t.Run(testName, func(t *testing.T) {
+		for i := 0; i < fuz; i++ {
+			a, b := randPoint(), randPoint()
+			g.affine(b, b)
+			g.add(t0, a, b)
+			g.addMixed(t1, a, b)
+			if !g.equal(t0, t1) || !g.isOnCurve(t1) {
+				t.Fatalf("a + b == mixed(a, b)")
+			}
+			g.addMixed(t0, a, zero)
+			if !g.equal(t0, a) {
+				t.Fatalf("a + 0 == a")
+			}
+			g.addMixed(t0, zero, b)
+			if !g.equal(t0, b) {
+				t.Fatalf("0 + b == b")
+			}
+			// a is not in affine form, a + a goes to doubling
+			g.add(a, a, b)
+			g.double(t0, b)
+			g.addMixed(t1, b, b)
+			if !g.equal(t0, t1) {
+				t.Fatalf("b + b == 2 * b")
+			}
+			g.double(t0, a)
+			g.affine(t1, a)
+			g.addMixed(t1, a, t1)
+			if !g.equal(t0, t1) {
+				t.Fatalf("a + a == 2 * a")
+			}
+			g.neg(t1, b)
+			g.addMixed(t1, b, t1)
+			if !g.equal(t1, zero) {
+				t.Fatalf("b - b == 0")
+			}
+		}
+	})
+	testName = tag + "_" + "batch_affine"
+	t.Run(testName, func(t *testing.T) {
+		points, expected := make([]point, 6), make([]point, 6)
+		for i := range points {
+			points[i], expected[i] = randPoint(), g.new()
+		}
+		points[2] = g.zero()
+		g.affine(points[4], points[4])
+		for i := range points {
+			g.affine(expected[i], points[i])
+		}
+		g.batchAffine(points)
+		if !g.isAffine(points) {
+			t.Fatalf("points are not in affine form")
+		}
+		for i := range points {
+			if !g.equal(points[i], expected[i]) {
+				t.Fatalf("batch affine does not match affine")
+			}
+		}
+		g.batchAffine(nil)
+	})
 	testName = tag + "_" + "multiplication_properties"
 	t.Run(testName, func(t *testing.T) {
 		for i := 0; i < fuz; i++ {
@@ -1223,6 +1457,18 @@ func testG(t *testing.T, g group, one point, tag string) {
 			t.Errorf(" (a ^ s1) + (a ^ s2) == a ^ (s1 + s2)")
 		}
 	})
+	testName = tag + "_" + "compressed_serialize"
+	t.Run(testName, func(t *testing.T) {
+		testCompressedEncoding(t, g, randPoint())
+	})
+	testName = tag + "_" + "mul_ct"
+	t.Run(testName, func(t *testing.T) {
+		testMulScalarCT(t, g, randPoint())
+	})
+	testName = tag + "_" + "fixed_base"
+	t.Run(testName, func(t *testing.T) {
+		testFixedBase(t, g, randPoint())
+	})
 	testName = tag + "_" + "multi_exp"
 	t.Run(testName, func(t *testing.T) {
 		count := 1000
@@ -1249,6 +1495,47 @@ func testG(t *testing.T, g group, one point, tag string) {
 			t.Fatalf("bad multi exponentiation")
 		}
 	})
+	testName = tag + "_" + "multi_exp_windows"
+	t.Run(testName, func(t *testing.T) {
+		// run windows on goroutines even with a single cpu
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+		wide := new(big.Int).Lsh(g.Q(), 3)
+		for _, count := range []int{1, 2, 9, 20, 40} {
+			bases := make([]point, count)
+			scalars := make([]*big.Int, count)
+			for i := 0; i < count; i++ {
+				bases[i] = randPoint()
+				scalars[i] = randScalar(g.Q())
+			}
+			// zero and scalars wider than the group order
+			scalars[0] = new(big.Int)
+			scalars[count-1] = new(big.Int).Add(wide, scalars[count-1])
+			copies := make([]*big.Int, count)
+			for i := range scalars {
+				copies[i] = new(big.Int).Set(scalars[i])
+			}
+			expected, tmp := g.zero(), g.zero()
+			for i := 0; i < count; i++ {
+				g.mulScalar(tmp, bases[i], scalars[i])
+				g.add(expected, expected, tmp)
+			}
+			result := g.zero()
+			if _, err := g.multiExp(result, bases, scalars); err != nil {
+				t.Fatal(err)
+			}
+			if !g.equal(expected, result) {
+				t.Fatalf("bad multi exponentiation with %d points", count)
+			}
+			for i := range scalars {
+				if scalars[i].Cmp(copies[i]) != 0 {
+					t.Fatalf("scalars are modified")
+				}
+			}
+		}
+		if _, err := g.multiExp(g.zero(), []point{randPoint()}, nil); err == nil {
+			t.Fatalf("vectors in different length are accepted")
+		}
+	})
 }
 
 type pairingEngine interface {
@@ -1446,6 +1733,12 @@ func TestMNT6Pairing(t *testing.T) {
 			g1, g2 := v.g1TestInstance(), v.g23TestInstance()
 			G1, G2 := v.G1(), v.G23()
 			// run tests
+			testMulScalarCT(t, g1, G1)
+			testMulScalarCT(t, g2, G2)
+			testCompressedEncoding(t, g1, G1)
+			testCompressedEncoding(t, g2, G2)
+			testFixedBase(t, g1, G1)
+			testFixedBase(t, g2, G2)
 			testNonDegeneracy(t, mnt6, g1, g2, G1, G2)
 			testBilinearity(t, mnt6, g1, g2, G1, G2)
 			testMultiPair(t, mnt6, g1, g2, G1, G2)
@@ -1453,6 +1746,83 @@ func TestMNT6Pairing(t *testing.T) {
 	}
 }
 
+var bls12381Vector = &vectorJSON{
+	FieldOrder:   "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab",
+	GroupOrder:   "0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001",
+	A:            "0x00",
+	B:            "0x04",
+	G1x:          "0x17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb",
+	G1y:          "0x08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
+	G2x0:         "0x024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8",
+	G2x1:         "0x13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e",
+	G2y0:         "0x0ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801",
+	G2y1:         "0x0606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be",
+	NonResidue:   "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaaa",
+	NonResidue20: "0x01",
+	NonResidue21: "0x01",
+	IsDType:      "False",
+	Z:            "-0xd201000000010000",
+}
+
+var bn254Vector = &vectorJSON{
+	FieldOrder:   "0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47",
+	GroupOrder:   "0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001",
+	A:            "0x00",
+	B:            "0x03",
+	G1x:          "0x01",
+	G1y:          "0x02",
+	G2x0:         "0x1800deef121f1e76426a00665e5c4479674322d4f75edadd46debd5cd992f6ed",
+	G2x1:         "0x198e9393920d483a7260bfb731fb5d25f1aa493335a9e71297e485b7aef312c2",
+	G2y0:         "0x12c85ea5db8c6deb4aab71808dcb408fe3d1e7690c43d37b4ce6cc0166fa7daa",
+	G2y1:         "0x90689d0585ff075ec9e99ad690c3395bc4b313370b38ef355acdadcd122975b",
+	NonResidue:   "-0x01",
+	NonResidue20: "0x09",
+	NonResidue21: "0x01",
+	IsDType:      "True",
+	Z:            "0x44e992b44a6909f1",
+}
+
+var mnt4320Vector = &vectorJSON{
+	FieldOrder:   "0x3bcf7bcd473a266249da7b0548ecaeec9635d1330ea41a9e35e51200e12c90cd65a71660001",
+	GroupOrder:   "0x3bcf7bcd473a266249da7b0548ecaeec9635cf44194fb494c07925d6ad3bb4334a400000001",
+	A:            "0x02",
+	B:            "0x03545a27639415585ea4d523234fc3edd2a2070a085c7b980f4e9cd21a515d4b0ef528ec0fd5",
+	G1x:          "0x7a2caf82a1ba85213fe6ca3875aee86aba8f73d69060c4079492b948dea216b5b9c8d2af46",
+	G1y:          "0x2db619461cc82672f7f159fec2e89d0148dcc9862d36778c1afd96a71e29cba48e710a48ab2",
+	G2x0:         "0x371780491c5660571ff542f2ef89001f205151e12a72cb14f01a931e72dba7903df6c09a9a4",
+	G2x1:         "0x4ba59a3f72da165def838081af697c851f002f576303302bb6c02c712c968be32c0ae0a989",
+	G2y0:         "0x4b471f33ffaad868a1c47d6605d31e5c4b3b2e0b60ec98f0f610a5aafd0d9522bca4e79f22",
+	G2y1:         "0x355d05a1c69a5031f3f81a5c100cb7d982f78ec9cfc3b5168ed8d75c7c484fb61a3cbf0e0f1",
+	NonResidue:   "0x11",
+	NonResidue20: "0x11",
+	NonResidue21: "0x00",
+	Z:            "0x1eef5546609756bec2a33f0dc9a1b671660000",
+	ExpW0:        "0x1eef5546609756bec2a33f0dc9a1b671660001",
+	ExpW1:        "0x01",
+}
+
+var mnt6320Vector = &vectorJSON{
+	FieldOrder:   "0x3bcf7bcd473a266249da7b0548ecaeec9635cf44194fb494c07925d6ad3bb4334a400000001",
+	GroupOrder:   "0x3bcf7bcd473a266249da7b0548ecaeec9635d1330ea41a9e35e51200e12c90cd65a71660001",
+	A:            "0x0b",
+	B:            "0xd68c7b1dc5dd042e957b71c44d3d6c24e683fc09b420b1a2d263fde47ddba59463d0c65282",
+	G1x:          "0x2a4feee24fd2c69d1d90471b2ba61ed56f9bad79b57e0b4c671392584bdadebc01abbc0447d",
+	G1y:          "0x32986c245f6db2f82f4e037bf7afd69cbfcbff07fc25d71e9c75e1b97208a333d73d91d3028",
+	G2x0:         "0x34f7320a12b56ce532bccb3b44902cbaa723cd60035ada7404b743ad2e644ad76257e4c6813",
+	G2x1:         "0xcf41620baa52eec50e61a70ab5b45f681952e0109340fec84f1b2890aba9b15cac5a0c80fa",
+	G2x2:         "0x11f99170e10e326433cccb8032fb48007ca3c4e105cf31b056ac767e2cb01258391bd4917ce",
+	G2y0:         "0x3a65968f03cc64d62ad05c79c415e07ebd38b363ec48309487c0b83e1717a582c1b60fecc91",
+	G2y1:         "0xca5e8427e5db1506c1a24cefc2451ab3accaea5db82dcb0c7117cc74402faa5b2c37685c6e",
+	G2y2:         "0xf75d2dd88302c9a4ef941307629a1b3e197277d83abb715f647c2e55a27baf782f5c60e7f7",
+	NonResidue:   "0x05",
+	NonResidue20: "0x05",
+	NonResidue21: "0x00",
+	NonResidue22: "0x00",
+	Z:            "-0x1eef5546609756bec2a33f0dc9a1b671660000",
+	ExpW0:        "-0x1eef5546609756bec2a33f0dc9a1b671660000",
+	ExpW1:        "0x01",
+}
+
 func TestBLSPairing(t *testing.T) {
 	opts := newBuilderOptPairing("BLS")
 	vectors := []*builder{
@@ -1474,24 +1844,7 @@ func TestBLSPairing(t *testing.T) {
 				IsDType:      "True",
 				Z:            "0x8508c00000000001",
 			}, opts),
-		testBuilderFromVector(t, "bls12_381",
-			&vectorJSON{
-				FieldOrder:   "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab",
-				GroupOrder:   "0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001",
-				A:            "0x00",
-				B:            "0x04",
-				G1x:          "0x17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb",
-				G1y:          "0x08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
-				G2x0:         "0x024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8",
-				G2x1:         "0x13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e",
-				G2y0:         "0x0ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801",
-				G2y1:         "0x0606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be",
-				NonResidue:   "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaaa",
-				NonResidue20: "0x01",
-				NonResidue21: "0x01",
-				IsDType:      "False",
-				Z:            "-0xd201000000010000",
-			}, opts),
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, opts),
 		testBuilderFromFile(t, "bls12/256.json", opts),
 		testBuilderFromFile(t, "bls12/320.json", opts),
 		testBuilderFromFile(t, "bls12/384.json", opts),
@@ -1524,24 +1877,7 @@ func TestBLSPairing(t *testing.T) {
 func TestBNPairing(t *testing.T) {
 	opts := newBuilderOptPairing("BN")
 	vectors := []*builder{
-		testBuilderFromVector(t, "bn254",
-			&vectorJSON{
-				FieldOrder:   "0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47",
-				GroupOrder:   "0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001",
-				A:            "0x00",
-				B:            "0x03",
-				G1x:          "0x01",
-				G1y:          "0x02",
-				G2x0:         "0x1800deef121f1e76426a00665e5c4479674322d4f75edadd46debd5cd992f6ed",
-				G2x1:         "0x198e9393920d483a7260bfb731fb5d25f1aa493335a9e71297e485b7aef312c2",
-				G2y0:         "0x12c85ea5db8c6deb4aab71808dcb408fe3d1e7690c43d37b4ce6cc0166fa7daa",
-				G2y1:         "0x90689d0585ff075ec9e99ad690c3395bc4b313370b38ef355acdadcd122975b",
-				NonResidue:   "-0x01",
-				NonResidue20: "0x09",
-				NonResidue21: "0x01",
-				IsDType:      "True",
-				Z:            "0x44e992b44a6909f1",
-			}, opts),
+		testBuilderFromVector(t, "bn254", bn254Vector, opts),
 	}
 	for _, v := range vectors {
 		testName := v.tag
@@ -1558,6 +1894,461 @@ func TestBNPairing(t *testing.T) {
 	}
 }
 
+// parallelPairs returns n random affine pairs of the builder
+func parallelPairs(v *builder, n int) ([]*pointG1, []*pointG22) {
+	g1, g2 := v.g1(), v.g22()
+	G1, G2 := v.G1().(*pointG1), v.G22().(*pointG22)
+	A1, A2 := make([]*pointG1, n), make([]*pointG22, n)
+	for i := 0; i < n; i++ {
+		A1[i], A2[i] = g1.newPoint(), g2.newPoint()
+		g1.mulScalar(A1[i], G1, randScalar(g1.q))
+		g2.mulScalar(A2[i], G2, randScalar(g2.q))
+		g1.affine(A1[i], A1[i])
+		g2.affine(A2[i], A2[i])
+	}
+	return A1, A2
+}
+
+func TestParallelMillerLoop(t *testing.T) {
+	vectors := []*builder{
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS")),
+		testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN")),
+	}
+	for _, v := range vectors {
+		t.Run(v.tag, func(t *testing.T) {
+			var multiPair func(A1 []*pointG1, A2 []*pointG22) (*fe12, bool)
+			var multiPairPrepared func(A1 []*pointG1, coeffs [][]fe6C) (*fe12, bool)
+			var prepareG2 func(Q *pointG22) ([]fe6C, bool)
+			var setWorkers func(n int)
+			var fq12 *fq12
+			if v.family == "BN" {
+				e := v.bn().(bnTest).bn
+				multiPair, multiPairPrepared, prepareG2, setWorkers, fq12 = e.multiPair, e.multiPairPrepared, e.prepareG2, e.setWorkers, e.fq12
+			} else {
+				e := v.bls().(blsTest).bls
+				multiPair, multiPairPrepared, prepareG2, setWorkers, fq12 = e.multiPair, e.multiPairPrepared, e.prepareG2, e.setWorkers, e.fq12
+			}
+			// number of pairs is not a multiple of worker counts
+			A1, A2 := parallelPairs(v, 13)
+			coeffs := make([][]fe6C, len(A2))
+			for i := range A2 {
+				coeffs[i], _ = prepareG2(A2[i])
+			}
+			expected, ok := multiPair(A1, A2)
+			if !ok {
+				t.Fatal("pairing engine returned no value")
+			}
+			for _, workers := range []int{2, 3, 5, 13, 16} {
+				setWorkers(workers)
+				f0, ok := multiPair(A1, A2)
+				if !ok {
+					t.Fatal("pairing engine returned no value")
+				}
+				f1, ok := multiPairPrepared(A1, coeffs)
+				if !ok {
+					t.Fatal("pairing engine returned no value")
+				}
+				if !fq12.equal(expected, f0) || !fq12.equal(expected, f1) {
+					t.Fatalf("bad parallel multi pairing with %d workers", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestG1MultiExpBatchAffine(t *testing.T) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g := v.g1()
+	n := g1BatchAffineThreshold + 3
+	bases, scalars := make([]*pointG1, n), make([]*big.Int, n)
+	p := g.mulScalar(g.newPoint(), v.G1().(*pointG1), randScalar(g.q))
+	for i := 0; i < n; i++ {
+		bases[i] = g.affine(g.newPoint(), g.add(p, p, v.G1().(*pointG1)))
+		scalars[i] = randScalar(g.q)
+	}
+	// same bucket additions go to doubling, cancellation and infinity
+	for i := 0; i < 16; i += 4 {
+		g.copy(bases[i+1], bases[i])
+		g.neg(bases[i+3], bases[i+2])
+		scalars[i+1].Set(scalars[i])
+		scalars[i+3].Set(scalars[i+2])
+	}
+	bases[n-1] = g.zero()
+	expected, tmp := g.zero(), g.newPoint()
+	for i := 0; i < n; i++ {
+		g.add(expected, expected, g.mulScalar(tmp, bases[i], scalars[i]))
+	}
+	result := g.newPoint()
+	if _, err := g.multiExp(result, bases, scalars); err != nil {
+		t.Fatal(err)
+	}
+	if !g.equal(expected, result) {
+		t.Fatal("bad batch affine multi exponentiation")
+	}
+}
+
+func BenchmarkParallelMillerLoop(t *testing.B) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	bls := v.bls().(blsTest).bls
+	A1, A2 := parallelPairs(v, 128)
+	for _, workers := range []int{1, 2, 4, 8} {
+		t.Run(fmt.Sprintf("%d_pairs_%d_workers", len(A1), workers), func(t *testing.B) {
+			bls.setWorkers(workers)
+			defer bls.setWorkers(1)
+			for i := 0; i < t.N; i++ {
+				bls.multiPair(A1, A2)
+			}
+		})
+	}
+}
+
+func BenchmarkMultiExp(t *testing.B) {
+	bls := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	mnt6 := testBuilderFromVector(t, "mnt6_320", mnt6320Vector, newBuilderOptPairing("MNT6"))
+	groups := []struct {
+		name string
+		g    group
+		one  point
+	}{
+		{"bls12_381_g1", bls.g1TestInstance(), bls.G1()},
+		{"bls12_381_g2", bls.g22TestInstance(), bls.G22()},
+		{"mnt6_320_g2", mnt6.g23TestInstance(), mnt6.G23()},
+	}
+	for _, v := range groups {
+		g := v.g
+		// bases are consecutive multiples of generator to keep setup cheap
+		bases, scalars := []point{}, []*big.Int{}
+		for _, n := range []int{2, 1 << 4, 1 << 8, 1 << 12, 1 << 16, 1 << 20} {
+			for i := len(bases); i < n; i++ {
+				p := g.add(g.new(), g.zero(), v.one)
+				if i > 0 {
+					g.add(p, bases[i-1], v.one)
+				}
+				g.affine(p, p)
+				bases, scalars = append(bases, p), append(scalars, randScalar(g.Q()))
+			}
+			t.Run(fmt.Sprintf("%s_%d", v.name, n), func(t *testing.B) {
+				r := g.new()
+				for i := 0; i < t.N; i++ {
+					g.multiExp(r, bases[:n], scalars[:n])
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkFixedBase(t *testing.B) {
+	bls := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	for _, v := range []struct {
+		name string
+		g    group
+		one  point
+	}{
+		{"bls12_381_g1", bls.g1TestInstance(), bls.G1()},
+		{"bls12_381_g2", bls.g22TestInstance(), bls.G22()},
+	} {
+		g, r, s := v.g, v.g.new(), randScalar(v.g.Q())
+		for _, window := range []uint{4, 8} {
+			table, err := g.newFixedBaseTable(v.one, g.Q().BitLen(), window)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Run(fmt.Sprintf("%s_table_%d", v.name, window), func(t *testing.B) {
+				for i := 0; i < t.N; i++ {
+					g.mulFixedBase(r, table, s)
+				}
+			})
+		}
+		t.Run(v.name+"_mul", func(t *testing.B) {
+			for i := 0; i < t.N; i++ {
+				g.mulScalar(r, v.one, s)
+			}
+		})
+	}
+}
+
+// compares dedicated square kernels against squaring with multiplication
+func BenchmarkPairingSquare(t *testing.B) {
+	vectors := []*builder{
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS")),
+		testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN")),
+	}
+	for _, v := range vectors {
+		f := v.fq()
+		engine := v.bls()
+		if v.family == "BN" {
+			engine = v.bn()
+		}
+		G1, G2 := v.G1(), v.G22()
+		square := f._square
+		kernels := []struct {
+			name   string
+			square func(c, a, p fe, inp uint64)
+		}{
+			{"square", square},
+			{"mul", func(c, a, p fe, inp uint64) { f._mul(c, a, a, p, inp) }},
+		}
+		for _, kernel := range kernels {
+			t.Run(fmt.Sprintf("%s_%s", v.tag, kernel.name), func(t *testing.B) {
+				f._square = kernel.square
+				defer func() { f._square = square }()
+				for i := 0; i < t.N; i++ {
+					engine.pair(G1, G2)
+				}
+			})
+		}
+	}
+}
+
+func testCompressedEncoding(t *testing.T, g group, a point) {
+	negA := g.neg(g.new(), a)
+	for _, p := range []point{g.zero(), a, negA} {
+		buf := g.toBytesCompressed(p)
+		b, err := g.fromBytesCompressed(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g.equal(p, b) {
+			t.Fatalf("bad compressed serialization")
+		}
+	}
+	buf := g.toBytesCompressed(a)
+	if buf[0] == g.toBytesCompressed(negA)[0] {
+		t.Fatalf("a and -a has same sign flag")
+	}
+	if _, err := g.fromBytesCompressed(buf[1:]); err == nil {
+		t.Fatalf("short input is accepted")
+	}
+	bad := append([]byte{}, buf...)
+	bad[0] = 0x04
+	if _, err := g.fromBytesCompressed(bad); err == nil {
+		t.Fatalf("unknown flag is accepted")
+	}
+	bad[0] = 0x00
+	if _, err := g.fromBytesCompressed(bad); err == nil {
+		t.Fatalf("infinity with non zero x is accepted")
+	}
+	bad[0] = buf[0]
+	for i := 1; i < len(bad); i++ {
+		bad[i] = 0xff
+	}
+	if _, err := g.fromBytesCompressed(bad); err == nil {
+		t.Fatalf("x larger than modulus is accepted")
+	}
+	// about half of x values are not on curve
+	copy(bad, buf)
+	rejected := false
+	for i := 0; i < 256 && !rejected; i++ {
+		bad[len(bad)-1] = byte(i)
+		_, err := g.fromBytesCompressed(bad)
+		rejected = err != nil
+	}
+	if !rejected {
+		t.Fatalf("x that is not on curve is accepted")
+	}
+}
+
+func testMulScalarCT(t *testing.T, g group, a point) {
+	zero, t0, t1 := g.zero(), g.new(), g.new()
+	for _, s := range []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Set(g.Q()),
+		new(big.Int).Sub(g.Q(), big.NewInt(1)),
+		new(big.Int).Add(g.Q(), big.NewInt(2)),
+		new(big.Int).Lsh(g.Q(), 8),
+	} {
+		g.mulScalarCT(t0, a, s)
+		g.mulScalar(t1, a, s)
+		if !g.equal(t0, t1) {
+			t.Fatalf("constant time multiplication by %s", s)
+		}
+	}
+	for i := 0; i < fuz; i++ {
+		s := randScalar(g.Q())
+		g.mulScalarCT(t0, zero, s)
+		if !g.equal(t0, zero) {
+			t.Fatalf(" 0 ^ s == 0")
+		}
+		g.mulScalarCT(t0, a, s)
+		g.mulScalar(t1, a, s)
+		if !g.equal(t0, t1) || !g.isOnCurve(t0) {
+			t.Fatalf("constant time multiplication")
+		}
+	}
+}
+
+func testFixedBase(t *testing.T, g group, a point) {
+	zero, t0, t1 := g.zero(), g.new(), g.new()
+	bits := g.Q().BitLen()
+	for _, window := range []uint{1, 4, 5} {
+		table, err := g.newFixedBaseTable(a, bits, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+		scalars := []*big.Int{
+			big.NewInt(0),
+			big.NewInt(1),
+			new(big.Int).Set(g.Q()),
+			new(big.Int).Sub(g.Q(), big.NewInt(1)),
+			max,
+			// falls back to multiplication without the table
+			new(big.Int).Lsh(max, 2*fixedBaseMaxWindow),
+		}
+		for i := 0; i < fuz; i++ {
+			scalars = append(scalars, randScalar(g.Q()))
+		}
+		for _, s := range scalars {
+			g.mulFixedBase(t0, table, s)
+			g.wnafMul(t1, a, s)
+			if !g.equal(t0, t1) {
+				t.Fatalf("fixed base multiplication by %s with window %d", s, window)
+			}
+			g.mulFixedBaseCT(t0, table, s)
+			if !g.equal(t0, t1) {
+				t.Fatalf("constant time fixed base multiplication by %s with window %d", s, window)
+			}
+		}
+		buf := table.Bytes()
+		if !bytes.Equal(buf, g.fixedBaseTableToBytes(table)) {
+			t.Fatalf("bad fixed base table encoding")
+		}
+		decoded, err := table.FromBytes(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := randScalar(g.Q())
+		if !g.equal(g.mulFixedBase(t0, decoded, s), g.mulFixedBase(t1, table, s)) {
+			t.Fatalf("bad fixed base table serialization")
+		}
+		if !bytes.Equal(decoded.Bytes(), buf) {
+			t.Fatalf("decoded fixed base table is expected to encode the same")
+		}
+	}
+	table, err := g.newFixedBaseTable(zero, bits, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := g.fixedBaseTableFromBytes(g.fixedBaseTableToBytes(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.equal(g.mulFixedBase(t0, decoded, randScalar(g.Q())), zero) {
+		t.Fatalf(" 0 ^ s == 0")
+	}
+	if _, err := g.newFixedBaseTable(a, bits, 0); err == nil {
+		t.Fatalf("zero window is accepted")
+	}
+	if _, err := g.newFixedBaseTable(a, bits, fixedBaseMaxWindow+1); err == nil {
+		t.Fatalf("large window is accepted")
+	}
+	table, err = g.newFixedBaseTable(a, 8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// falls back to constant time multiplication without the table
+	s := randScalar(g.Q())
+	if !g.equal(g.mulFixedBaseCT(t0, table, s), g.wnafMul(t1, a, s)) {
+		t.Fatalf("constant time fixed base multiplication with short table")
+	}
+	buf := g.fixedBaseTableToBytes(table)
+	if _, err := g.fixedBaseTableFromBytes(buf[:len(buf)-1]); err == nil {
+		t.Fatalf("short table is accepted")
+	}
+	bad := append([]byte{}, buf...)
+	bad[0] = 0
+	if _, err := g.fixedBaseTableFromBytes(bad); err == nil {
+		t.Fatalf("zero window is accepted")
+	}
+	// last point is moved off the curve
+	copy(bad, buf)
+	bad[len(bad)-1] ^= 1
+	if _, err := g.fixedBaseTableFromBytes(bad); err == nil {
+		t.Fatalf("point that is not on curve is accepted")
+	}
+}
+
+// dudect style leakage test, timings of multiplications by a fixed scalar
+// and by random scalars are compared with welch's t-test. wall clock timings
+// are noisy so it only runs with -timing flag and reports t values, |t|
+// above 10 is taken as a definite leakage in dudect
+// timingThreshold is the usual bound on welch t statistic of dudect, larger
+// values are taken as timing depending on the scalar
+const timingThreshold = 4.5
+
+func TestMulScalarCTTiming(t *testing.T) {
+	if !timing {
+		t.Skip("timing test runs with -timing flag")
+	}
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	g, one := v.g1TestInstance(), v.G1()
+	table, err := g.newFixedBaseTable(one, g.Q().BitLen(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mulFixedBaseCT := func(c, _ point, e *big.Int) point {
+		return g.mulFixedBaseCT(c, table, e)
+	}
+	// variable time multiplication is only a reference for sensitivity
+	t.Logf("variable time multiplication, t = %.2f", timingLeakage(g, g.mulScalar, one))
+	for _, m := range []struct {
+		name string
+		mul  func(c, a point, e *big.Int) point
+	}{
+		{"mulScalarCT", g.mulScalarCT},
+		{"mulFixedBaseCT", mulFixedBaseCT},
+	} {
+		tt := timingLeakage(g, m.mul, one)
+		t.Logf("%s, t = %.2f", m.name, tt)
+		if math.Abs(tt) > timingThreshold {
+			t.Fatalf("%s timing depends on the scalar, t = %.2f", m.name, tt)
+		}
+	}
+}
+
+func timingLeakage(g group, mul func(c, a point, e *big.Int) point, a point) float64 {
+	n := 2000
+	fixed := big.NewInt(1)
+	c := g.new()
+	var x [2][]float64
+	for i := 0; i < n; i++ {
+		class := randBig(big.NewInt(2)).Int64()
+		s := fixed
+		if class == 1 {
+			s = randScalar(g.Q())
+		}
+		start := time.Now()
+		mul(c, a, s)
+		x[class] = append(x[class], float64(time.Since(start)))
+	}
+	// crop outliers that are mostly interrupts and gc, each class is
+	// cropped at its own percentile
+	for i := range x {
+		sort.Float64s(x[i])
+		x[i] = x[i][:len(x[i])*9/10]
+	}
+	return welchT(x[0], x[1])
+}
+
+func welchT(a, b []float64) float64 {
+	moments := func(x []float64) (float64, float64) {
+		var mean, variance float64
+		for _, v := range x {
+			mean += v
+		}
+		mean /= float64(len(x))
+		for _, v := range x {
+			variance += (v - mean) * (v - mean)
+		}
+		return mean, variance / float64(len(x)-1)
+	}
+	ma, va := moments(a)
+	mb, vb := moments(b)
+	return (ma - mb) / math.Sqrt(va/float64(len(a))+vb/float64(len(b)))
+}
+
 func testNonDegeneracy(t *testing.T, e pairingEngine, g1, g2 group, G1, G2 point) {
 	gt := e.gt()
 	// e(g1^a, g2^b) != 1
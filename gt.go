@@ -0,0 +1,273 @@
+package eip
+
+import (
+	"errors"
+	"math/big"
+)
+
+// GTGroup is the target group of a pairing, the subgroup of order q of the
+// multiplicative group of Fp12 for BLS12 and BN, Fp4 for MNT4 and Fp6 for
+// MNT6 curves. A GTGroup keeps scratch space and is not safe for concurrent
+// use.
+type GTGroup struct {
+	f gtField
+	q *big.Int
+}
+
+// GT is an element of a target group. Elements of different groups can not
+// be mixed, Mul fails and Equal reports false for them.
+type GT struct {
+	g *GTGroup
+	e interface{}
+}
+
+// gtField is the arithmetic of the extension field that target group lives in
+type gtField interface {
+	one() interface{}
+	fromBytes(in []byte) (interface{}, error)
+	toBytes(a interface{}) []byte
+	mul(a, b interface{}) interface{}
+	exp(a interface{}, e *big.Int) interface{}
+	cyclotomicExp(a interface{}, e *big.Int) interface{}
+	conjugate(a interface{}) interface{}
+	equal(a, b interface{}) bool
+	isOne(a interface{}) bool
+}
+
+// NewGTGroup returns the target group of a pairing operation. opType is one
+// of the pairing operations and in is its input, so the group of a result of
+// OPERATION_*PAIR_GT is constructed from the same call data. Only the curve
+// header, the call data up to the number of pairs, is read and anything
+// after it is ignored.
+func (api *API) NewGTGroup(opType int, in []byte) (*GTGroup, error) {
+	decoder := newDecoder(in)
+	switch opType {
+	case OPERATION_BLS12PAIR, OPERATION_BLS12PAIR_GT:
+		e, err := decoder.blsEngine()
+		if err != nil {
+			return nil, err
+		}
+		return &GTGroup{&fq12GT{e.fq12}, e.g1.q}, nil
+	case OPERATION_BNPAIR, OPERATION_BNPAIR_GT:
+		e, err := decoder.bnEngine()
+		if err != nil {
+			return nil, err
+		}
+		return &GTGroup{&fq12GT{e.fq12}, e.g1.q}, nil
+	case OPERATION_MNT4PAIR, OPERATION_MNT4PAIR_GT:
+		e, err := decoder.mnt4Engine()
+		if err != nil {
+			return nil, err
+		}
+		return &GTGroup{&fq4GT{e.fq4}, e.g1.q}, nil
+	case OPERATION_MNT6PAIR, OPERATION_MNT6PAIR_GT:
+		e, err := decoder.mnt6Engine()
+		if err != nil {
+			return nil, err
+		}
+		return &GTGroup{&fq6QGT{e.fq6}, e.g1.q}, nil
+	default:
+		return nil, errors.New(ERR_UNKNOWN_OPERATION)
+	}
+}
+
+// Order returns the order of the group.
+func (g *GTGroup) Order() *big.Int {
+	return new(big.Int).Set(g.q)
+}
+
+// One returns the identity element.
+func (g *GTGroup) One() *GT {
+	return &GT{g, g.f.one()}
+}
+
+// FromBytes decodes an element in the encoding of OPERATION_*PAIR_GT
+// results. Coefficients must be canonical, group membership is not checked,
+// see IsInSubgroup.
+func (g *GTGroup) FromBytes(in []byte) (*GT, error) {
+	e, err := g.f.fromBytes(in)
+	if err != nil {
+		return nil, err
+	}
+	return &GT{g, e}, nil
+}
+
+// Bytes returns the canonical encoding of a.
+func (a *GT) Bytes() []byte {
+	return a.g.f.toBytes(a.e)
+}
+
+// Mul returns a * b. It fails if a and b are elements of different groups.
+func (a *GT) Mul(b *GT) (*GT, error) {
+	if a.g != b.g {
+		return nil, errors.New(ERR_GT_GROUP_MISMATCH)
+	}
+	return &GT{a.g, a.g.f.mul(a.e, b.e)}, nil
+}
+
+// Exp returns a^e. Exponent is reduced modulo the group order and
+// exponentiation takes the cyclotomic squaring path where it is available,
+// so a is expected to be in the group.
+func (a *GT) Exp(e *big.Int) *GT {
+	s := new(big.Int).Mod(e, a.g.q)
+	return &GT{a.g, a.g.f.cyclotomicExp(a.e, s)}
+}
+
+// Inverse returns the inverse of a, which is the conjugate of a for the
+// elements of the group.
+func (a *GT) Inverse() *GT {
+	return &GT{a.g, a.g.f.conjugate(a.e)}
+}
+
+// Equal reports whether a and b are the same element of the same group.
+func (a *GT) Equal(b *GT) bool {
+	return a.g == b.g && a.g.f.equal(a.e, b.e)
+}
+
+// IsOne reports whether a is the identity element.
+func (a *GT) IsOne() bool {
+	return a.g.f.isOne(a.e)
+}
+
+// IsInSubgroup reports whether a is in the group, that is a^q = 1.
+func (a *GT) IsInSubgroup() bool {
+	return a.g.f.isOne(a.g.f.exp(a.e, a.g.q))
+}
+
+type fq12GT struct {
+	f *fq12
+}
+
+func (t *fq12GT) one() interface{} {
+	return t.f.one()
+}
+
+func (t *fq12GT) fromBytes(in []byte) (interface{}, error) {
+	return t.f.fromBytes(in)
+}
+
+func (t *fq12GT) toBytes(a interface{}) []byte {
+	return t.f.toBytes(a.(*fe12))
+}
+
+func (t *fq12GT) mul(a, b interface{}) interface{} {
+	c := t.f.new()
+	t.f.mul(c, a.(*fe12), b.(*fe12))
+	return c
+}
+
+func (t *fq12GT) exp(a interface{}, e *big.Int) interface{} {
+	c := t.f.new()
+	t.f.exp(c, a.(*fe12), e)
+	return c
+}
+
+func (t *fq12GT) cyclotomicExp(a interface{}, e *big.Int) interface{} {
+	c := t.f.new()
+	t.f.cyclotomicExp(c, a.(*fe12), e)
+	return c
+}
+
+func (t *fq12GT) conjugate(a interface{}) interface{} {
+	return t.f.conjugate(t.f.new(), a.(*fe12))
+}
+
+func (t *fq12GT) equal(a, b interface{}) bool {
+	return t.f.equal(a.(*fe12), b.(*fe12))
+}
+
+func (t *fq12GT) isOne(a interface{}) bool {
+	return t.f.isOne(a.(*fe12))
+}
+
+type fq4GT struct {
+	f *fq4
+}
+
+func (t *fq4GT) one() interface{} {
+	return t.f.one()
+}
+
+func (t *fq4GT) fromBytes(in []byte) (interface{}, error) {
+	return t.f.fromBytes(in)
+}
+
+func (t *fq4GT) toBytes(a interface{}) []byte {
+	return t.f.toBytes(a.(*fe4))
+}
+
+func (t *fq4GT) mul(a, b interface{}) interface{} {
+	c := t.f.new()
+	t.f.mul(c, a.(*fe4), b.(*fe4))
+	return c
+}
+
+func (t *fq4GT) exp(a interface{}, e *big.Int) interface{} {
+	c := t.f.new()
+	t.f.exp(c, a.(*fe4), e)
+	return c
+}
+
+// cyclotomicExp falls back to square and multiply, there is no compressed
+// squaring for fq4
+func (t *fq4GT) cyclotomicExp(a interface{}, e *big.Int) interface{} {
+	return t.exp(a, e)
+}
+
+func (t *fq4GT) conjugate(a interface{}) interface{} {
+	return t.f.conjugate(t.f.new(), a.(*fe4))
+}
+
+func (t *fq4GT) equal(a, b interface{}) bool {
+	return t.f.equal(a.(*fe4), b.(*fe4))
+}
+
+func (t *fq4GT) isOne(a interface{}) bool {
+	return t.f.isOne(a.(*fe4))
+}
+
+type fq6QGT struct {
+	f *fq6Q
+}
+
+func (t *fq6QGT) one() interface{} {
+	return t.f.one()
+}
+
+func (t *fq6QGT) fromBytes(in []byte) (interface{}, error) {
+	return t.f.fromBytes(in)
+}
+
+func (t *fq6QGT) toBytes(a interface{}) []byte {
+	return t.f.toBytes(a.(*fe6Q))
+}
+
+func (t *fq6QGT) mul(a, b interface{}) interface{} {
+	c := t.f.new()
+	t.f.mul(c, a.(*fe6Q), b.(*fe6Q))
+	return c
+}
+
+func (t *fq6QGT) exp(a interface{}, e *big.Int) interface{} {
+	c := t.f.new()
+	t.f.exp(c, a.(*fe6Q), e)
+	return c
+}
+
+// cyclotomicExp falls back to square and multiply, there is no compressed
+// squaring for fq6
+func (t *fq6QGT) cyclotomicExp(a interface{}, e *big.Int) interface{} {
+	return t.exp(a, e)
+}
+
+func (t *fq6QGT) conjugate(a interface{}) interface{} {
+	return t.f.conjugate(t.f.new(), a.(*fe6Q))
+}
+
+func (t *fq6QGT) equal(a, b interface{}) bool {
+	return t.f.equal(a.(*fe6Q), b.(*fe6Q))
+}
+
+func (t *fq6QGT) isOne(a interface{}) bool {
+	return t.f.isOne(a.(*fe6Q))
+}
@@ -0,0 +1,93 @@
+package eip
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestGTGroup(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		family := v.family
+		g, err := api.NewGTGroup(v.operation, v.input)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		out1, err := api.Run(v.operation, v.first)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		out2, err := api.Run(v.operation, v.last)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		a, err := g.FromBytes(out1)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		b, err := g.FromBytes(out2)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		if !bytes.Equal(a.Bytes(), out1) {
+			t.Fatal("bad encoding", family)
+		}
+		if !a.IsInSubgroup() || !b.IsInSubgroup() || a.IsOne() {
+			t.Fatal("pairing result is expected to be a non trivial element", family)
+		}
+		mul := func(a, b *GT) *GT {
+			c, err := a.Mul(b)
+			if err != nil {
+				t.Fatal(err, family)
+			}
+			return c
+		}
+		if !mul(a, b).IsOne() || !a.Inverse().Equal(b) {
+			t.Fatal("bad inverse", family)
+		}
+		if !a.Exp(big.NewInt(-1)).Equal(b) {
+			t.Fatal("bad exponentiation by negative exponent", family)
+		}
+		if !a.Exp(big.NewInt(3)).Equal(mul(mul(a, a), a)) {
+			t.Fatal("bad exponentiation", family)
+		}
+		if !a.Exp(g.Order()).IsOne() || !mul(g.One(), a).Equal(a) {
+			t.Fatal("bad identity", family)
+		}
+		// group is constructed from the curve header only
+		h, err := api.NewGTGroup(v.operation, v.header)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		d, err := h.FromBytes(out1)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		if !bytes.Equal(d.Bytes(), out1) {
+			t.Fatal("bad encoding", family)
+		}
+		if _, err := a.Mul(d); err == nil || err.Error() != ERR_GT_GROUP_MISMATCH {
+			t.Fatal("elements of different groups are expected to fail", family)
+		}
+		if a.Equal(d) {
+			t.Fatal("elements of different groups are expected to be unequal", family)
+		}
+		// 1 + w for the last basis element w is not in the group
+		in := g.One().Bytes()
+		in[len(in)-1] = 1
+		c, err := g.FromBytes(in)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		if c.IsInSubgroup() {
+			t.Fatal("element is not expected to be in the group", family)
+		}
+		if _, err := g.FromBytes(out1[1:]); err == nil {
+			t.Fatal("short input is expected to fail", family)
+		}
+	}
+	if _, err := api.NewGTGroup(OPERATION_G1_ADD, []byte{}); err == nil {
+		t.Fatal("non pairing operation is expected to fail")
+	}
+}
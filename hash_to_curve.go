@@ -0,0 +1,439 @@
+package eip
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+// hash to curve of rfc 9380 with simplified swu, suites for bls12-381 are
+// BLS12381G1_XMD:SHA-256_SSWU_RO_, BLS12381G2_XMD:SHA-256_SSWU_RO_ and their
+// encode to curve variants with _NU_
+
+const (
+	bls12381Modulus = "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab"
+	bls12381Order   = "0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001"
+)
+
+// effective cofactors that clears cofactor with a scalar multiplication
+const (
+	bls12381G1HEff = "0xd201000000010001"
+	bls12381G2HEff = "0xbc69f08f2ee75b3584c6a0ea91b352888e2a8e9145ad7689986ff031508ffe1329c2f178731db956d82bf015d1212b02ec0ec69d7477c1ae954cbc06689f6a359894c0adebbf6b4e8020005aaa95551"
+	// bls12381Z is the negative curve parameter, g2 cofactor is cleared with
+	// psi when it is available that gives the same result as hEff
+	bls12381Z = "-0xd201000000010000"
+)
+
+// security parameter of hash to field in bits
+const hashToFieldSecurity = 128
+
+// expandMessageXMD is expand_message_xmd of rfc 9380 section 5.3.1
+func expandMessageXMD(newHash func() hash.Hash, msg, dst []byte, outLen int) ([]byte, error) {
+	h := newHash()
+	b, s := h.Size(), h.BlockSize()
+	ell := (outLen + b - 1) / b
+	if ell > 255 || outLen > 65535 {
+		return nil, fmt.Errorf("requested output length %d is too large", outLen)
+	}
+	if len(dst) > 255 {
+		return nil, errors.New("domain separation tag is too large")
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	// b0 = H(Z_pad || msg || l_i_b_str || 0 || DST_prime)
+	h.Write(make([]byte, s))
+	h.Write(msg)
+	h.Write([]byte{byte(outLen >> 8), byte(outLen), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+	// b1 = H(b0 || 1 || DST_prime)
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+	out := make([]byte, 0, ell*b)
+	out = append(out, bi...)
+	// bi = H(b0 ^ b(i-1) || i || DST_prime)
+	for i := 2; i <= ell; i++ {
+		t := make([]byte, b)
+		for j := range t {
+			t[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(t)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:outLen], nil
+}
+
+// hashToFieldLength is L = ceil((ceil(log2(p)) + k) / 8)
+func hashToFieldLength(f *fq) int {
+	return (f.pbig.BitLen() + hashToFieldSecurity + 7) / 8
+}
+
+// reduceBytes maps a big endian string to a field element modulo p
+func reduceBytes(f *fq, in []byte) (fe, error) {
+	e := new(big.Int).SetBytes(in)
+	e.Mod(e, f.pbig)
+	return f.fromBytes(padBytes(e.Bytes(), f.byteSize()))
+}
+
+func hashToFieldFq(f *fq, msg, dst []byte, count int) ([]fe, error) {
+	l := hashToFieldLength(f)
+	uniform, err := expandMessageXMD(sha256.New, msg, dst, count*l)
+	if err != nil {
+		return nil, err
+	}
+	u := make([]fe, count)
+	for i := 0; i < count; i++ {
+		if u[i], err = reduceBytes(f, uniform[i*l:(i+1)*l]); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+func hashToFieldFq2(f *fq2, msg, dst []byte, count int) ([]*fe2, error) {
+	fq := f.fq()
+	l := hashToFieldLength(fq)
+	uniform, err := expandMessageXMD(sha256.New, msg, dst, 2*count*l)
+	if err != nil {
+		return nil, err
+	}
+	u := make([]*fe2, count)
+	for i := 0; i < count; i++ {
+		u[i] = f.new()
+		for j := 0; j < 2; j++ {
+			off := (2*i + j) * l
+			if u[i][j], err = reduceBytes(fq, uniform[off:off+l]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return u, nil
+}
+
+func isBLS12381(f *fq) bool {
+	p, _ := new(big.Int).SetString(bls12381Modulus[2:], 16)
+	return f.pbig.Cmp(p) == 0
+}
+
+func isBLS12381Order(q *big.Int) bool {
+	r, _ := new(big.Int).SetString(bls12381Order[2:], 16)
+	return q.Cmp(r) == 0
+}
+
+// isBLS12381G1 checks for y^2 = x^3 + 4 over bls12-381 base field
+func isBLS12381G1(g *g1) bool {
+	if !isBLS12381(g.f) || !isBLS12381Order(g.q) {
+		return false
+	}
+	four := g.f.new()
+	g.f.double(four, g.f.one)
+	g.f.double(four, four)
+	return g.f.isZero(g.a) && g.f.equal(g.b, four)
+}
+
+// isBLS12381G2 checks for y^2 = x^3 + 4(u + 1) over fq2 with u^2 = -1
+func isBLS12381G2(g *g22) bool {
+	fq := g.f.fq()
+	if !isBLS12381(fq) || !isBLS12381Order(g.q) {
+		return false
+	}
+	minusOne, four := fq.new(), fq.new()
+	fq.neg(minusOne, fq.one)
+	fq.double(four, fq.one)
+	fq.double(four, four)
+	return fq.equal(g.f.nonResidue, minusOne) && g.f.isZero(g.a) &&
+		fq.equal(g.b[0], four) && fq.equal(g.b[1], four)
+}
+
+// g1Mapper maps field elements to the curve of g1 with one of simplified swu,
+// svdw or elligator 2 maps and clears cofactor with hEff
+type g1Mapper struct {
+	g    *g1
+	m    func(u fe) (fe, fe, bool)
+	hEff *big.Int
+}
+
+type g22Mapper struct {
+	g    *g22
+	m    func(u *fe2) (*fe2, *fe2, bool)
+	hEff *big.Int
+	// z is set for bls12 twists to clear cofactor with psi
+	z *big.Int
+}
+
+// newG1SWUMapper derives simplified swu parameters for the curve of g. If a * b = 0
+// for the curve an isogenous curve with the isogeny map is required
+func newG1SWUMapper(g *g1, iso *g1IsogenyParams, hEff *big.Int) (*g1Mapper, error) {
+	a, b := g.a, g.b
+	if iso != nil {
+		a, b = iso.a, iso.b
+	} else if g.f.isZero(a) || g.f.isZero(b) {
+		return nil, errors.New("isogenous curve is required for a * b = 0")
+	}
+	swu, err := newSWUParamsForG1(g.f, a, b)
+	if err != nil {
+		return nil, err
+	}
+	m := newG1SWUMapperWithParams(g, swu, iso, hEff)
+	if iso != nil {
+		if p, err := m.mapToCurve(g.f.one); err != nil || !g.isOnCurve(p) {
+			return nil, errors.New("isogeny does not map to the curve")
+		}
+	}
+	return m, nil
+}
+
+func newG22SWUMapper(g *g22, iso *g2IsogenyParams, hEff *big.Int) (*g22Mapper, error) {
+	a, b := g.a, g.b
+	if iso != nil {
+		a, b = iso.a, iso.b
+	} else if g.f.isZero(a) || g.f.isZero(b) {
+		return nil, errors.New("isogenous curve is required for a * b = 0")
+	}
+	swu, err := newSWUParamsForG2(g.f, a, b)
+	if err != nil {
+		return nil, err
+	}
+	m := newG22SWUMapperWithParams(g, swu, iso, hEff)
+	if iso != nil {
+		if p, err := m.mapToCurve(g.f.one()); err != nil || !g.isOnCurve(p) {
+			return nil, errors.New("isogeny does not map to the curve")
+		}
+	}
+	return m, nil
+}
+
+func newG1SWUMapperWithParams(g *g1, swu *g1SWUParams, iso *g1IsogenyParams, hEff *big.Int) *g1Mapper {
+	return &g1Mapper{g, func(u fe) (fe, fe, bool) {
+		x, y, ok := swuMapForG1(u, g.f, swu)
+		if ok && iso != nil {
+			x, y = applyIsogenyMapForG1(g.f, x, y, iso)
+		}
+		return x, y, ok
+	}, new(big.Int).Set(hEff)}
+}
+
+func newG22SWUMapperWithParams(g *g22, swu *g2SWUParams, iso *g2IsogenyParams, hEff *big.Int) *g22Mapper {
+	return &g22Mapper{g, func(u *fe2) (*fe2, *fe2, bool) {
+		x, y, ok := swuMapForG2(u, g.f, swu)
+		if ok && iso != nil {
+			x, y = applyIsogenyMapForG2(g.f, x, y, iso)
+		}
+		return x, y, ok
+	}, new(big.Int).Set(hEff), nil}
+}
+
+// newG1SvdWMapper applies to any curve of g
+func newG1SvdWMapper(g *g1, hEff *big.Int) (*g1Mapper, error) {
+	params, err := newSvdWParamsForG1(g.f, g.a, g.b)
+	if err != nil {
+		return nil, err
+	}
+	return &g1Mapper{g, func(u fe) (fe, fe, bool) {
+		return svdwMapForG1(u, g.f, params)
+	}, new(big.Int).Set(hEff)}, nil
+}
+
+func newG22SvdWMapper(g *g22, hEff *big.Int) (*g22Mapper, error) {
+	params, err := newSvdWParamsForG2(g.f, g.a, g.b)
+	if err != nil {
+		return nil, err
+	}
+	return &g22Mapper{g, func(u *fe2) (*fe2, *fe2, bool) {
+		return svdwMapForG2(u, g.f, params)
+	}, new(big.Int).Set(hEff), nil}, nil
+}
+
+// newG1Ell2Mapper expects the curve of g to be the short weierstrass form
+// of montgomery curve k * t^2 = s^3 + j * s^2 + s
+func newG1Ell2Mapper(g *g1, j, k fe, hEff *big.Int) (*g1Mapper, error) {
+	params, err := newEll2ParamsForG1(g.f, j, k)
+	if err != nil {
+		return nil, err
+	}
+	if a, b := montgomeryToWeierstrassFq(g.f, j, k); !g.f.equal(a, g.a) || !g.f.equal(b, g.b) {
+		return nil, errors.New("montgomery curve is not equivalent to the curve")
+	}
+	return &g1Mapper{g, func(u fe) (fe, fe, bool) {
+		return ell2MapForG1(u, g.f, params)
+	}, new(big.Int).Set(hEff)}, nil
+}
+
+func newG22Ell2Mapper(g *g22, j, k *fe2, hEff *big.Int) (*g22Mapper, error) {
+	params, err := newEll2ParamsForG2(g.f, j, k)
+	if err != nil {
+		return nil, err
+	}
+	if a, b := montgomeryToWeierstrassFq2(g.f, j, k); !g.f.equal(a, g.a) || !g.f.equal(b, g.b) {
+		return nil, errors.New("montgomery curve is not equivalent to the curve")
+	}
+	return &g22Mapper{g, func(u *fe2) (*fe2, *fe2, bool) {
+		return ell2MapForG2(u, g.f, params)
+	}, new(big.Int).Set(hEff), nil}, nil
+}
+
+// bls12381G1Mapper uses precomputed parameters of BLS12381G1_XMD:SHA-256_SSWU_
+func bls12381G1Mapper(g *g1) *g1Mapper {
+	hEff, _ := new(big.Int).SetString(bls12381G1HEff[2:], 16)
+	return newG1SWUMapperWithParams(g, computeSWUParamsForG1(g.f), prepareIsogenyParamsForG1(g.f), hEff)
+}
+
+func bls12381G2Mapper(g *g22) *g22Mapper {
+	hEff, _ := new(big.Int).SetString(bls12381G2HEff[2:], 16)
+	m := newG22SWUMapperWithParams(g, computeSWUParamsForG2(g.f), prepareIsogenyParamsForG2(g.f), hEff)
+	m.z, _ = new(big.Int).SetString(bls12381Z, 0)
+	return m
+}
+
+// mapToCurve is map_to_curve, result is not cleared of cofactor
+func (m *g1Mapper) mapToCurve(u fe) (*pointG1, error) {
+	g := m.g
+	x, y, ok := m.m(u)
+	if !ok {
+		return nil, errors.New("element has no square root")
+	}
+	p := g.newPoint()
+	g.f.copy(p[0], x)
+	g.f.copy(p[1], y)
+	g.f.copy(p[2], g.f.one)
+	return p, nil
+}
+
+// mapToGroup maps a field element to a point in prime order subgroup
+func (m *g1Mapper) mapToGroup(u fe) (*pointG1, error) {
+	p, err := m.mapToCurve(u)
+	if err != nil {
+		return nil, err
+	}
+	return m.g.mulScalar(p, p, m.hEff), nil
+}
+
+func (m *g1Mapper) hashToCurve(msg, dst []byte) (*pointG1, error) {
+	u, err := hashToFieldFq(m.g.f, msg, dst, 2)
+	if err != nil {
+		return nil, err
+	}
+	q0, err := m.mapToCurve(u[0])
+	if err != nil {
+		return nil, err
+	}
+	q1, err := m.mapToCurve(u[1])
+	if err != nil {
+		return nil, err
+	}
+	m.g.add(q0, q0, q1)
+	return m.g.mulScalar(q0, q0, m.hEff), nil
+}
+
+func (m *g1Mapper) encodeToCurve(msg, dst []byte) (*pointG1, error) {
+	u, err := hashToFieldFq(m.g.f, msg, dst, 1)
+	if err != nil {
+		return nil, err
+	}
+	return m.mapToGroup(u[0])
+}
+
+func (m *g22Mapper) mapToCurve(u *fe2) (*pointG22, error) {
+	g := m.g
+	x, y, ok := m.m(u)
+	if !ok {
+		return nil, errors.New("element has no square root")
+	}
+	p := g.newPoint()
+	g.f.copy(p[0], x)
+	g.f.copy(p[1], y)
+	g.f.copy(p[2], g.f.one())
+	return p, nil
+}
+
+func (m *g22Mapper) mapToGroup(u *fe2) (*pointG22, error) {
+	p, err := m.mapToCurve(u)
+	if err != nil {
+		return nil, err
+	}
+	return m.clearCofactor(p), nil
+}
+
+// clearCofactor multiplies by hEff
+func (m *g22Mapper) clearCofactor(p *pointG22) *pointG22 {
+	if m.z != nil {
+		if params := m.g.endomorphism(); params != nil {
+			return m.g.clearCofactorBLS12(p, p, m.z, params)
+		}
+	}
+	return m.g.mulScalar(p, p, m.hEff)
+}
+
+func (m *g22Mapper) hashToCurve(msg, dst []byte) (*pointG22, error) {
+	u, err := hashToFieldFq2(m.g.f, msg, dst, 2)
+	if err != nil {
+		return nil, err
+	}
+	q0, err := m.mapToCurve(u[0])
+	if err != nil {
+		return nil, err
+	}
+	q1, err := m.mapToCurve(u[1])
+	if err != nil {
+		return nil, err
+	}
+	m.g.add(q0, q0, q1)
+	return m.clearCofactor(q0), nil
+}
+
+func (m *g22Mapper) encodeToCurve(msg, dst []byte) (*pointG22, error) {
+	u, err := hashToFieldFq2(m.g.f, msg, dst, 1)
+	if err != nil {
+		return nil, err
+	}
+	return m.mapToGroup(u[0])
+}
+
+// mapToG1 maps a field element to bls12-381 g1
+func mapToG1(g *g1, u fe) (*pointG1, error) {
+	return bls12381G1Mapper(g).mapToGroup(u)
+}
+
+func mapToG2(g *g22, u *fe2) (*pointG22, error) {
+	return bls12381G2Mapper(g).mapToGroup(u)
+}
+
+// hashToCurveG1 is hash_to_curve of BLS12381G1_XMD:SHA-256_SSWU_RO_
+func hashToCurveG1(g *g1, msg, dst []byte) (*pointG1, error) {
+	if !isBLS12381G1(g) {
+		return nil, errors.New("hash to curve is only defined for bls12-381")
+	}
+	return bls12381G1Mapper(g).hashToCurve(msg, dst)
+}
+
+// encodeToCurveG1 is encode_to_curve of BLS12381G1_XMD:SHA-256_SSWU_NU_
+func encodeToCurveG1(g *g1, msg, dst []byte) (*pointG1, error) {
+	if !isBLS12381G1(g) {
+		return nil, errors.New("hash to curve is only defined for bls12-381")
+	}
+	return bls12381G1Mapper(g).encodeToCurve(msg, dst)
+}
+
+// hashToCurveG2 is hash_to_curve of BLS12381G2_XMD:SHA-256_SSWU_RO_
+func hashToCurveG2(g *g22, msg, dst []byte) (*pointG22, error) {
+	if !isBLS12381G2(g) {
+		return nil, errors.New("hash to curve is only defined for bls12-381")
+	}
+	return bls12381G2Mapper(g).hashToCurve(msg, dst)
+}
+
+// encodeToCurveG2 is encode_to_curve of BLS12381G2_XMD:SHA-256_SSWU_NU_
+func encodeToCurveG2(g *g22, msg, dst []byte) (*pointG22, error) {
+	if !isBLS12381G2(g) {
+		return nil, errors.New("hash to curve is only defined for bls12-381")
+	}
+	return bls12381G2Mapper(g).encodeToCurve(msg, dst)
+}
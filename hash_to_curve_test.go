@@ -0,0 +1,420 @@
+package eip
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+	"testing"
+)
+
+func bls12381G1ForHashing(t *testing.T) *g1 {
+	f, _ := newField(fromHex(48, bls12381Modulus))
+	a, _ := f.fromString("0x00")
+	b, _ := f.fromString("0x04")
+	q := new(big.Int).SetBytes(fromHex(48, "0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001"))
+	g, err := newG1(f, a, b, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func bls12381G2ForHashing(t *testing.T) *g22 {
+	f, _ := newField(fromHex(48, bls12381Modulus))
+	nonResidue := f.new()
+	f.neg(nonResidue, f.one)
+	fq2, err := newFq2(f, f.toBytes(nonResidue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fq2.calculateFrobeniusCoeffs()
+	b0, _ := f.fromString("0x04")
+	a, b := fq2.zero(), fq2.new()
+	f.copy(b[0], b0)
+	f.copy(b[1], b0)
+	q := new(big.Int).SetBytes(fromHex(48, "0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001"))
+	g, err := newG22(fq2, a, b, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	for i, v := range []struct {
+		msg      string
+		outLen   int
+		expected string
+	}{
+		{"", 0x20, "0x68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", 0x20, "0xd8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+		{"abcdef0123456789", 0x20, "0xeff31487c770a893cfb36f912fbfcbff40d5661771ca4b2cb4eafe524333f5c1"},
+		{"", 0x80, "0xaf84c27ccfd45d41914fdff5df25293e221afc53d8ad2ac06d5e3e29485dadbee0d121587713a3e0dd4d5e69e93eb7cd4f5df4cd103e188cf60cb02edc3edf18eda8576c412b18ffb658e3dd6ec849469b979d444cf7b26911a08e63cf31f9dcc541708d3491184472c2c29bb749d4286b004ceb5ee6b9a7fa5b646c993f0ced"},
+	} {
+		out, err := expandMessageXMD(sha256.New, []byte(v.msg), dst, v.outLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, fromHex(v.outLen, v.expected)) {
+			t.Fatalf("bad output at vector %d", i)
+		}
+	}
+	if _, err := expandMessageXMD(sha256.New, nil, dst, 256*32); err == nil {
+		t.Fatal("too large output length is expected to fail")
+	}
+	if _, err := expandMessageXMD(sha256.New, nil, make([]byte, 256), 32); err == nil {
+		t.Fatal("too large dst is expected to fail")
+	}
+}
+
+func TestHashToFieldFq(t *testing.T) {
+	g := bls12381G1ForHashing(t)
+	u, err := hashToFieldFq(g.f, []byte{}, []byte("QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u0, _ := g.f.fromString("0x0ba14bd907ad64a016293ee7c2d276b8eae71f25a4b941eece7b0d89f17f75cb3ae5438a614fb61d6835ad59f29c564f")
+	u1, _ := g.f.fromString("0x019b9bd7979f12657976de2884c7cce192b82c177c80e0ec604436a7f538d231552f0d96d9f7babe5fa3b19b3ff25ac9")
+	if !g.f.equal(u[0], u0) || !g.f.equal(u[1], u1) {
+		t.Fatal("bad field elements")
+	}
+}
+
+func TestHashToCurveG1(t *testing.T) {
+	g := bls12381G1ForHashing(t)
+	for _, v := range []struct {
+		dst  string
+		msg  string
+		x, y string
+		hash bool
+	}{
+		{
+			"QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_", "",
+			"0x052926add2207b76ca4fa57a8734416c8dc95e24501772c814278700eed6d1e4e8cf62d9c09db0fac349612b759e79a1",
+			"0x08ba738453bfed09cb546dbb0783dbb3a5f1f566ed67bb6be0e8c67e2e81a4cc68ee29813bb7994998f3eae0c9c6a265",
+			true,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_", "abc",
+			"0x03567bc5ef9c690c2ab2ecdf6a96ef1c139cc0b2f284dca0a9a7943388a49a3aee664ba5379a7655d3c68900be2f6903",
+			"0x0b9c15f3fe6e5cf4211f346271d7b01c8f3b28be689c8429c85b67af215533311f0b8dfaaa154fa6b88176c229f2885d",
+			true,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_", "abcdef0123456789",
+			"0x11e0b079dea29a68f0383ee94fed1b940995272407e3bb916bbf268c263ddd57a6a27200a784cbc248e84f357ce82d98",
+			"0x03a87ae2caf14e8ee52e51fa2ed8eefe80f02457004ba4d486d6aa1f517c0889501dc7413753f9599b099ebcbbd2d709",
+			true,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_NU_", "",
+			"0x184bb665c37ff561a89ec2122dd343f20e0f4cbcaec84e3c3052ea81d1834e192c426074b02ed3dca4e7676ce4ce48ba",
+			"0x04407b8d35af4dacc809927071fc0405218f1401a6d15af775810e4e460064bcc9468beeba82fdc751be70476c888bf3",
+			false,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_NU_", "abc",
+			"0x009769f3ab59bfd551d53a5f846b9984c59b97d6842b20a2c565baa167945e3d026a3755b6345df8ec7e6acb6868ae6d",
+			"0x1532c00cf61aa3d0ce3e5aa20c3b531a2abd2c770a790a2613818303c6b830ffc0ecf6c357af3317b9575c567f11cd2c",
+			false,
+		},
+	} {
+		var p *pointG1
+		var err error
+		if v.hash {
+			p, err = hashToCurveG1(g, []byte(v.msg), []byte(v.dst))
+		} else {
+			p, err = encodeToCurveG1(g, []byte(v.msg), []byte(v.dst))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := g.newPoint()
+		expected[0], _ = g.f.fromString(v.x)
+		expected[1], _ = g.f.fromString(v.y)
+		g.f.copy(expected[2], g.f.one)
+		if !g.equal(expected, p) {
+			t.Fatalf("bad point for %q with %s", v.msg, v.dst)
+		}
+		if !g.checkCorrectSubgroup(p) {
+			t.Fatal("point is not in correct subgroup")
+		}
+	}
+}
+
+func TestHashToCurveG2(t *testing.T) {
+	// cofactor is cleared with psi if it is available
+	withPsi := bls12381G2ForHashing(t)
+	xi := withPsi.f.one()
+	withPsi.f.fq().copy(xi[1], withPsi.f.fq().one)
+	withPsi.gls = newGLS(xi, TWIST_M)
+	if withPsi.endomorphism() == nil {
+		t.Fatal("psi is expected to be available")
+	}
+	groups := []*g22{bls12381G2ForHashing(t), withPsi}
+	f := withPsi.f.fq()
+	for _, v := range []struct {
+		dst            string
+		msg            string
+		x0, x1, y0, y1 string
+		hash           bool
+	}{
+		{
+			"QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_", "",
+			"0x0141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a",
+			"0x05cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d",
+			"0x0503921d7f6a12805e72940b963c0cf3471c7b2a524950ca195d11062ee75ec076daf2d4bc358c4b190c0c98064fdd92",
+			"0x12424ac32561493f3fe3c260708a12b7c620e7be00099a974e259ddc7d1f6395c3c811cdd19f1e8dbf3e9ecfdcbab8d6",
+			true,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_", "abc",
+			"0x02c2d18e033b960562aae3cab37a27ce00d80ccd5ba4b7fe0e7a210245129dbec7780ccc7954725f4168aff2787776e6",
+			"0x139cddbccdc5e91b9623efd38c49f81a6f83f175e80b06fc374de9eb4b41dfe4ca3a230ed250fbe3a2acf73a41177fd8",
+			"0x1787327b68159716a37440985269cf584bcb1e621d3a7202be6ea05c4cfe244aeb197642555a0645fb87bf7466b2ba48",
+			"0x00aa65dae3c8d732d10ecd2c50f8a1baf3001578f71c694e03866e9f3d49ac1e1ce70dd94a733534f106d4cec0eddd16",
+			true,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_NU_", "",
+			"0x00e7f4568a82b4b7dc1f14c6aaa055edf51502319c723c4dc2688c7fe5944c213f510328082396515734b6612c4e7bb7",
+			"0x126b855e9e69b1f691f816e48ac6977664d24d99f8724868a184186469ddfd4617367e94527d4b74fc86413483afb35b",
+			"0x0caead0fd7b6176c01436833c79d305c78be307da5f6af6c133c47311def6ff1e0babf57a0fb5539fce7ee12407b0a42",
+			"0x1498aadcf7ae2b345243e281ae076df6de84455d766ab6fcdaad71fab60abb2e8b980a440043cd305db09d283c895e3d",
+			false,
+		},
+		{
+			"QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_NU_", "abc",
+			"0x108ed59fd9fae381abfd1d6bce2fd2fa220990f0f837fa30e0f27914ed6e1454db0d1ee957b219f61da6ff8be0d6441f",
+			"0x0296238ea82c6d4adb3c838ee3cb2346049c90b96d602d7bb1b469b905c9228be25c627bffee872def773d5b2a2eb57d",
+			"0x033f90f6057aadacae7963b0a0b379dd46750c1c94a6357c99b65f63b79e321ff50fe3053330911c56b6ceea08fee656",
+			"0x153606c417e59fb331b7ae6bce4fbf7c5190c33ce9402b5ebe2b70e44fca614f3f1382a3625ed5493843d0b0a652fc3f",
+			false,
+		},
+	} {
+		for _, g := range groups {
+			var p *pointG22
+			var err error
+			if v.hash {
+				p, err = hashToCurveG2(g, []byte(v.msg), []byte(v.dst))
+			} else {
+				p, err = encodeToCurveG2(g, []byte(v.msg), []byte(v.dst))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := g.newPoint()
+			expected[0][0], _ = f.fromString(v.x0)
+			expected[0][1], _ = f.fromString(v.x1)
+			expected[1][0], _ = f.fromString(v.y0)
+			expected[1][1], _ = f.fromString(v.y1)
+			g.f.copy(expected[2], g.f.one())
+			if !g.equal(expected, p) {
+				t.Fatalf("bad point for %q with %s", v.msg, v.dst)
+			}
+			if !g.checkCorrectSubgroup(p) {
+				t.Fatal("point is not in correct subgroup")
+			}
+		}
+	}
+}
+
+func TestHashToCurveNonBLS12381(t *testing.T) {
+	f, _ := newField(fromHex(32, "0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47"))
+	a, _ := f.fromString("0x00")
+	b, _ := f.fromString("0x03")
+	q := new(big.Int).SetBytes(fromHex(32, "0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001"))
+	g, err := newG1(f, a, b, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hashToCurveG1(g, []byte("abc"), []byte("dst")); err == nil {
+		t.Fatal("hash to curve is expected to fail for non bls12-381 modulus")
+	}
+}
+
+func TestMapToCurveZero(t *testing.T) {
+	// exceptional case of simplified swu should give x = b / (z * a)
+	g1 := bls12381G1ForHashing(t)
+	f := g1.f
+	swu1 := computeSWUParamsForG1(f)
+	x, _, ok := swuMapForG1(f.zero, f, swu1)
+	if !ok {
+		t.Fatal("element has no square root")
+	}
+	f.mul(x, x, swu1.z)
+	f.mul(x, x, swu1.a)
+	if !f.equal(x, swu1.b) {
+		t.Fatal("bad x coordinate")
+	}
+	g2 := bls12381G2ForHashing(t)
+	f2 := g2.f
+	swu2 := computeSWUParamsForG2(f2)
+	x2, _, ok := swuMapForG2(f2.zero(), f2, swu2)
+	if !ok {
+		t.Fatal("element has no square root")
+	}
+	f2.mul(x2, x2, swu2.z)
+	f2.mul(x2, x2, swu2.a)
+	if !f2.equal(x2, swu2.b) {
+		t.Fatal("bad x coordinate")
+	}
+}
+
+func TestSWUMapperG1(t *testing.T) {
+	// bls12-381 with isogeny supplied by caller
+	g := bls12381G1ForHashing(t)
+	hEff, _ := new(big.Int).SetString(bls12381G1HEff[2:], 16)
+	m, err := newG1SWUMapper(g, prepareIsogenyParamsForG1(g.f), hEff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := m.hashToCurve([]byte("abc"), []byte("QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := g.newPoint()
+	expected[0], _ = g.f.fromString("0x03567bc5ef9c690c2ab2ecdf6a96ef1c139cc0b2f284dca0a9a7943388a49a3aee664ba5379a7655d3c68900be2f6903")
+	expected[1], _ = g.f.fromString("0x0b9c15f3fe6e5cf4211f346271d7b01c8f3b28be689c8429c85b67af215533311f0b8dfaaa154fa6b88176c229f2885d")
+	g.f.copy(expected[2], g.f.one)
+	if !g.equal(expected, p) {
+		t.Fatal("bad point for bls12-381")
+	}
+	if _, err := newG1SWUMapper(g, nil, hEff); err == nil {
+		t.Fatal("isogeny is expected to be required for a = 0")
+	}
+	// isogeny that does not map to the curve
+	iso := prepareIsogenyParamsForG1(g.f)
+	iso.k1 = iso.k1[1:]
+	if _, err := newG1SWUMapper(g, iso, hEff); err == nil {
+		t.Fatal("bad isogeny is expected to fail")
+	}
+	// p-256 is mapped directly, P256_XMD:SHA-256_SSWU_RO_
+	f, _ := newField(fromHex(40, "0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"))
+	a, _ := f.fromString("0xffffffff00000001000000000000000000000000fffffffffffffffffffffffc")
+	b, _ := f.fromString("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b")
+	q, _ := new(big.Int).SetString("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 16)
+	g, err = newG1(f, a, b, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = newG1SWUMapper(g, nil, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = m.hashToCurve([]byte{}, []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = g.newPoint()
+	expected[0], _ = f.fromString("0x2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4")
+	expected[1], _ = f.fromString("0x8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415")
+	f.copy(expected[2], f.one)
+	if !g.equal(expected, p) {
+		t.Fatal("bad point for p-256")
+	}
+}
+
+func TestSWUMapperG2(t *testing.T) {
+	g := bls12381G2ForHashing(t)
+	hEff, _ := new(big.Int).SetString(bls12381G2HEff[2:], 16)
+	m, err := newG22SWUMapper(g, prepareIsogenyParamsForG2(g.f), hEff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := m.hashToCurve([]byte("abc"), []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := g.f.fq()
+	expected := g.newPoint()
+	expected[0][0], _ = f.fromString("0x02c2d18e033b960562aae3cab37a27ce00d80ccd5ba4b7fe0e7a210245129dbec7780ccc7954725f4168aff2787776e6")
+	expected[0][1], _ = f.fromString("0x139cddbccdc5e91b9623efd38c49f81a6f83f175e80b06fc374de9eb4b41dfe4ca3a230ed250fbe3a2acf73a41177fd8")
+	expected[1][0], _ = f.fromString("0x1787327b68159716a37440985269cf584bcb1e621d3a7202be6ea05c4cfe244aeb197642555a0645fb87bf7466b2ba48")
+	expected[1][1], _ = f.fromString("0x00aa65dae3c8d732d10ecd2c50f8a1baf3001578f71c694e03866e9f3d49ac1e1ce70dd94a733534f106d4cec0eddd16")
+	g.f.copy(expected[2], g.f.one())
+	if !g.equal(expected, p) {
+		t.Fatal("bad point for bls12-381")
+	}
+	if _, err := newG22SWUMapper(g, nil, hEff); err == nil {
+		t.Fatal("isogeny is expected to be required for a = 0")
+	}
+}
+
+func TestSvdWMapperG1(t *testing.T) {
+	// BN254G1_XMD:SHA-256_SVDW_RO_
+	f, _ := newField(fromHex(32, "0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47"))
+	a, _ := f.fromString("0x00")
+	b, _ := f.fromString("0x03")
+	q := new(big.Int).SetBytes(fromHex(32, "0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001"))
+	g, err := newG1(f, a, b, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := newG1SvdWMapper(g, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := m.hashToCurve([]byte{}, []byte("QUUX-V01-CS02-with-BN254G1_XMD:SHA-256_SVDW_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := g.newPoint()
+	expected[0], _ = f.fromString("0x0a976ab906170db1f9638d376514dbf8c42aef256a54bbd48521f20749e59e86")
+	expected[1], _ = f.fromString("0x02925ead66b9e68bfc309b014398640ab55f6619ab59bc1fab2210ad4c4d53d5")
+	f.copy(expected[2], f.one)
+	if !g.equal(expected, p) {
+		t.Fatal("bad point for bn254")
+	}
+}
+
+func TestEll2MapperG1(t *testing.T) {
+	// curve25519_XMD:SHA-512_ELL2_NU_ in short weierstrass form
+	f, _ := newField(fromHex(32, "0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed"))
+	j, _ := f.fromString("0x076d06")
+	k := f.new()
+	f.copy(k, f.one)
+	a, b := montgomeryToWeierstrassFq(f, j, k)
+	q, _ := new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+	g, err := newG1(f, a, b, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := newG1Ell2Mapper(g, j, k, big.NewInt(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := []byte("QUUX-V01-CS02-with-curve25519_XMD:SHA-512_ELL2_NU_")
+	uniform, err := expandMessageXMD(sha512.New, []byte{}, dst, hashToFieldLength(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := reduceBytes(f, uniform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := m.mapToGroup(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// s = x - j / 3 for k = 1
+	s, _ := f.fromString("0x1bb913f0c9daefa0b3375378ffa534bda5526c97391952a7789eb976edfe4d08")
+	tt, _ := f.fromString("0x4548368f4f983243e747b62a600840ae7c1dab5c723991f85d3a9768479f3ec4")
+	three := f.new()
+	f.double(three, f.one)
+	f.add(three, three, f.one)
+	f.inverse(three, three)
+	f.mul(three, three, j)
+	expected := g.newPoint()
+	f.add(expected[0], s, three)
+	f.copy(expected[1], tt)
+	f.copy(expected[2], f.one)
+	if !g.equal(expected, p) {
+		t.Fatal("bad point for curve25519")
+	}
+	if _, err := newG1Ell2Mapper(g, j, three, big.NewInt(8)); err == nil {
+		t.Fatal("non equivalent montgomery curve is expected to fail")
+	}
+}
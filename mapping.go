@@ -1,5 +1,10 @@
 package eip
 
+import (
+	"errors"
+	"math/big"
+)
+
 func swuMapForG1(u fe, fq *fq, params *g1SWUParams) (fe, fe, bool) {
 	var tv [4]fe
 	for i := 0; i < 4; i++ {
@@ -27,9 +32,7 @@ func swuMapForG1(u fe, fq *fq, params *g1SWUParams) (fe, fe, bool) {
 	fq.add(x1, x1, fq.one)
 
 	// 7.   x1 = CMOV(x1, c2, e1)    # If (tv1 + tv2) == 0, set x1 = -1 / Z
-	if e1 {
-		fq.copy(x1, params.zInv)
-	}
+	fq.cmov(x1, params.minusZInv, e1)
 
 	// 8.   x1 = x1 * c1      # x1 = (-B / A) * (1 + (1 / (Z^2 * u^4 + Z * u^2)))
 	fq.mul(x1, x1, params.minusBOverA)
@@ -63,19 +66,13 @@ func swuMapForG1(u fe, fq *fq, params *g1SWUParams) (fe, fe, bool) {
 	e2 := _e2 == 0 || _e2 == 1
 	// 17.   x = CMOV(x2, x1, e2)    # If is_square(gx1), x = x1, else x = x2
 	x := fq.new()
-	if e2 {
-		fq.copy(x, x1)
-	} else {
-		fq.copy(x, x2)
-	}
+	fq.copy(x, x2)
+	fq.cmov(x, x1, e2)
 
 	// 18.  y2 = CMOV(gx2, gx1, e2)  # If is_square(gx1), y2 = gx1, else y2 = gx2
 	y2 := fq.new()
-	if e2 {
-		fq.copy(y2, gx1)
-	} else {
-		fq.copy(y2, gx2)
-	}
+	fq.copy(y2, gx2)
+	fq.cmov(y2, gx1, e2)
 
 	// 19.   y = sqrt(y2)
 	y := fq.new()
@@ -83,12 +80,10 @@ func swuMapForG1(u fe, fq *fq, params *g1SWUParams) (fe, fe, bool) {
 		return nil, nil, false
 	}
 	// 20.  e3 = sgn0(u) == sgn0(y)  # Fix sign of y
-	uSign := fq.sign(u)
-	ySign := fq.sign(y)
-
-	if ((uSign == 1 && ySign == -1) || (uSign == -1 && ySign == 1)) || ((uSign == 0 && ySign == -1) || (uSign == -1 && ySign == 0)) {
-		fq.neg(y, y)
-	}
+	e3 := fq.sgn0(u) != fq.sgn0(y)
+	negY := fq.new()
+	fq.neg(negY, y)
+	fq.cmov(y, negY, e3)
 	return x, y, true
 }
 
@@ -119,9 +114,7 @@ func swuMapForG2(u *fe2, fq2 *fq2, params *g2SWUParams) (*fe2, *fe2, bool) {
 	fq2.add(x1, x1, fq2.one())
 
 	// 7.   x1 = CMOV(x1, c2, e1)    # If (tv1 + tv2) == 0, set x1 = -1 / Z
-	if e1 {
-		fq2.copy(x1, params.zInv)
-	}
+	fq2.cmov(x1, params.minusZInv, e1)
 
 	// 8.   x1 = x1 * c1      # x1 = (-B / A) * (1 + (1 / (Z^2 * u^4 + Z * u^2)))
 	fq2.mul(x1, x1, params.minusBOverA)
@@ -154,19 +147,13 @@ func swuMapForG2(u *fe2, fq2 *fq2, params *g2SWUParams) (*fe2, *fe2, bool) {
 	e2 := _e2 == 0 || _e2 == 1
 	// 17.   x = CMOV(x2, x1, e2)    # If is_square(gx1), x = x1, else x = x2
 	x := fq2.new()
-	if e2 {
-		fq2.copy(x, x1)
-	} else {
-		fq2.copy(x, x2)
-	}
+	fq2.copy(x, x2)
+	fq2.cmov(x, x1, e2)
 
 	// 18.  y2 = CMOV(gx2, gx1, e2)  # If is_square(gx1), y2 = gx1, else y2 = gx2
 	y2 := fq2.new()
-	if e2 {
-		fq2.copy(y2, gx1)
-	} else {
-		fq2.copy(y2, gx2)
-	}
+	fq2.copy(y2, gx2)
+	fq2.cmov(y2, gx1, e2)
 
 	// 19.   y = sqrt(y2)
 	y := fq2.new()
@@ -174,12 +161,10 @@ func swuMapForG2(u *fe2, fq2 *fq2, params *g2SWUParams) (*fe2, *fe2, bool) {
 		return nil, nil, false
 	}
 	// 20.  e3 = sgn0(u) == sgn0(y)  # Fix sign of y
-	uSign := fq2.sign(u)
-	ySign := fq2.sign(y)
-
-	if ((uSign == 1 && ySign == -1) || (uSign == -1 && ySign == 1)) || ((uSign == 0 && ySign == -1) || (uSign == -1 && ySign == 0)) {
-		fq2.neg(y, y)
-	}
+	e3 := fq2.sgn0(u) != fq2.sgn0(y)
+	negY := fq2.new()
+	fq2.neg(negY, y)
+	fq2.cmov(y, negY, e3)
 	return x, y, true
 }
 
@@ -193,34 +178,24 @@ func legendreSymbolFq(fq *fq, elem fe) int {
 	}
 }
 
+// legendreSymbolFq2 is legendre symbol of the norm, a0^2 - nonResidue * a1^2,
+// in base field which agrees with the one in extension field
 func legendreSymbolFq2(fq2 *fq2, elem *fe2) int {
 	if fq2.isZero(elem) {
 		return 0
-	} else if fq2.isNonResidue(elem, 2) {
-		return -1
-	} else {
-		return 1
 	}
+	fq := fq2.fq()
+	norm, t := fq.new(), fq.new()
+	fq.square(norm, elem[0])
+	fq.square(t, elem[1])
+	fq.mul(t, t, fq2.nonResidue)
+	fq.sub(norm, norm, t)
+	return legendreSymbolFq(fq, norm)
 }
 
 func applyIsogenyMapForG1(fq *fq, x, y fe, params *g1IsogenyParams) (fe, fe) {
-	degree := 15
-	xNum := params.k1[degree]
-	xDen := params.k2[degree]
-	yNum := params.k3[degree]
-	yDen := params.k4[degree]
-
-	for i := degree - 1; i >= 0; i-- {
-		fq.mul(xNum, xNum, x)
-		fq.mul(xDen, xDen, x)
-		fq.mul(yNum, yNum, x)
-		fq.mul(yDen, yDen, x)
-
-		fq.add(xNum, xNum, params.k1[i])
-		fq.add(xDen, xDen, params.k2[i])
-		fq.add(yNum, yNum, params.k3[i])
-		fq.add(yDen, yDen, params.k4[i])
-	}
+	xNum, xDen := evalPolyFq(fq, params.k1, x), evalPolyFq(fq, params.k2, x)
+	yNum, yDen := evalPolyFq(fq, params.k3, x), evalPolyFq(fq, params.k4, x)
 
 	fq.inverse(xDen, xDen)
 	fq.inverse(yDen, yDen)
@@ -234,23 +209,8 @@ func applyIsogenyMapForG1(fq *fq, x, y fe, params *g1IsogenyParams) (fe, fe) {
 }
 
 func applyIsogenyMapForG2(fq2 *fq2, x, y *fe2, params *g2IsogenyParams) (*fe2, *fe2) {
-	degree := 3
-	xNum := params.k1[degree]
-	xDen := params.k2[degree]
-	yNum := params.k3[degree]
-	yDen := params.k4[degree]
-
-	for i := degree - 1; i >= 0; i-- {
-		fq2.mul(xNum, xNum, x)
-		fq2.mul(xDen, xDen, x)
-		fq2.mul(yNum, yNum, x)
-		fq2.mul(yDen, yDen, x)
-
-		fq2.add(xNum, xNum, params.k1[i])
-		fq2.add(xDen, xDen, params.k2[i])
-		fq2.add(yNum, yNum, params.k3[i])
-		fq2.add(yDen, yDen, params.k4[i])
-	}
+	xNum, xDen := evalPolyFq2(fq2, params.k1, x), evalPolyFq2(fq2, params.k2, x)
+	yNum, yDen := evalPolyFq2(fq2, params.k3, x), evalPolyFq2(fq2, params.k4, x)
 
 	fq2.inverse(xDen, xDen)
 	fq2.inverse(yDen, yDen)
@@ -263,9 +223,30 @@ func applyIsogenyMapForG2(fq2 *fq2, x, y *fe2, params *g2IsogenyParams) (*fe2, *
 	return xNum, yNum
 }
 
+// evalPolyFq evaluates polynomial with coefficients in ascending order at x
+func evalPolyFq(fq *fq, k []fe, x fe) fe {
+	r := fq.new()
+	fq.copy(r, k[len(k)-1])
+	for i := len(k) - 2; i >= 0; i-- {
+		fq.mul(r, r, x)
+		fq.add(r, r, k[i])
+	}
+	return r
+}
+
+func evalPolyFq2(fq2 *fq2, k []*fe2, x *fe2) *fe2 {
+	r := fq2.new()
+	fq2.copy(r, k[len(k)-1])
+	for i := len(k) - 2; i >= 0; i-- {
+		fq2.mul(r, r, x)
+		fq2.add(r, r, k[i])
+	}
+	return r
+}
+
 type g1SWUParams struct {
 	z           fe
-	zInv        fe
+	minusZInv   fe
 	a           fe
 	b           fe
 	minusBOverA fe
@@ -273,7 +254,7 @@ type g1SWUParams struct {
 
 type g2SWUParams struct {
 	z           *fe2
-	zInv        *fe2
+	minusZInv   *fe2
 	a           *fe2
 	b           *fe2
 	minusBOverA *fe2
@@ -283,14 +264,14 @@ func computeSWUParamsForG1(fq *fq) *g1SWUParams {
 	z, _ := fq.fromString("0x0b")
 	a, _ := fq.fromString("0x00144698a3b8e9433d693a02c96d4982b0ea985383ee66a8d8e8981aefd881ac98936f8da0e0f97f5cf428082d584c1d")
 	b, _ := fq.fromString("0x12e2908d11688030018b12e8753eee3b2016c1f0f24f4070a0b9c14fcef35ef55a23215a316ceaa5d1cc48e98e172be0")
-	// z^-1
-	zInv, _ := fq.fromString("0x025d302c90dd14f6c102839c34a9c9e509221e235bf4d328ac4a41b18aca44ec02c9d1743eaa8ba2e25cfffffffff83e")
+	// -z^-1
+	minusZInv, _ := fq.fromString("0x025d302c90dd14f6c102839c34a9c9e509221e235bf4d328ac4a41b18aca44ec02c9d1743eaa8ba2e25cfffffffff83e")
 	// -b/a
 	minusBOverA, _ := fq.fromString("0x0793154fd85631d966ef2470460c78f6a928ad9f5bdbfac21df39753aa278ba751bdfcf95a84188e29d670675e4c9c7c")
 
 	return &g1SWUParams{
 		z,
-		zInv,
+		minusZInv,
 		a,
 		b,
 		minusBOverA,
@@ -299,7 +280,7 @@ func computeSWUParamsForG1(fq *fq) *g1SWUParams {
 }
 
 func computeSWUParamsForG2(fq2 *fq2) *g2SWUParams {
-	z, a, b, zInv, minusBOverA := fq2.new(), fq2.new(), fq2.new(), fq2.new(), fq2.new()
+	z, a, b, minusZInv, minusBOverA := fq2.new(), fq2.new(), fq2.new(), fq2.new(), fq2.new()
 
 	z[0], _ = fq2.f.fromString("0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaa9")
 	z[1], _ = fq2.f.fromString("0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaaa")
@@ -307,34 +288,293 @@ func computeSWUParamsForG2(fq2 *fq2) *g2SWUParams {
 	a[1], _ = fq2.f.fromString("0xf0")
 	b[0], _ = fq2.f.fromString("0x03f4")
 	b[1], _ = fq2.f.fromString("0x03f4")
-	// z^-1
-	zInv[0], _ = fq2.f.fromString("0x053369fba51994854238bb2473dbef5e474b0f1a971a9d597b09c3b9caf0313a6c88cccc89dd99998b99666666665555")
-	zInv[1], _ = fq2.f.fromString("0x0a66d3f74a33290a84717648e7b7debc8e961e352e353ab2f613877395e06274d911999913bb33331732ccccccccaaab")
+	// -z^-1
+	minusZInv[0], _ = fq2.f.fromString("0x053369fba51994854238bb2473dbef5e474b0f1a971a9d597b09c3b9caf0313a6c88cccc89dd99998b99666666665555")
+	minusZInv[1], _ = fq2.f.fromString("0x0a66d3f74a33290a84717648e7b7debc8e961e352e353ab2f613877395e06274d911999913bb33331732ccccccccaaab")
+	fq2.neg(minusZInv, minusZInv)
 	// -b/a
 	minusBOverA[0], _ = fq2.f.fromString("0x083c12791abdd5d2fe2f284f0cc6e5aa9b8c2d3f6f3f792302cf75e62bfc4df1d6834443da498888725d8cccccccb1c3")
 	minusBOverA[1], _ = fq2.f.fromString("0x11c4ff711ec210c74cec7f673684c72cc8eb1e458445999c64615cbacab4a8324828bbbad70a777747a173333332f8e8")
 
 	return &g2SWUParams{
 		z,
-		zInv,
+		minusZInv,
 		a,
 		b,
 		minusBOverA,
 	}
 }
 
+// limit on counter of find_z_sswu
+const maxSWUZCandidates = 1 << 10
+
+// newSWUParamsForG1 derives simplified swu parameters for y^2 = x^3 + a * x + b
+// with a * b != 0, z is the first candidate of find_z_sswu in rfc 9380 appendix H.2
+func newSWUParamsForG1(fq *fq, a, b fe) (*g1SWUParams, error) {
+	if fq.isZero(a) || fq.isZero(b) {
+		return nil, errors.New("simplified swu requires non zero a and b")
+	}
+	ctr := fq.new()
+	fq.copy(ctr, fq.one)
+	for i := 0; i < maxSWUZCandidates; i++ {
+		for _, neg := range []bool{false, true} {
+			z := fq.new()
+			fq.copy(z, ctr)
+			if neg {
+				fq.neg(z, ctr)
+			}
+			if !isSWUZForG1(fq, a, b, z) {
+				continue
+			}
+			minusZInv, minusBOverA := fq.new(), fq.new()
+			fq.inverse(minusZInv, z)
+			fq.neg(minusZInv, minusZInv)
+			fq.inverse(minusBOverA, a)
+			fq.mul(minusBOverA, minusBOverA, b)
+			fq.neg(minusBOverA, minusBOverA)
+			return &g1SWUParams{z, minusZInv, a, b, minusBOverA}, nil
+		}
+		fq.add(ctr, ctr, fq.one)
+	}
+	return nil, errors.New("could not find z for simplified swu")
+}
+
+func newSWUParamsForG2(fq2 *fq2, a, b *fe2) (*g2SWUParams, error) {
+	if fq2.isZero(a) || fq2.isZero(b) {
+		return nil, errors.New("simplified swu requires non zero a and b")
+	}
+	// generator of fq2 is u
+	ctr := fq2.zero()
+	fq2.f.copy(ctr[1], fq2.f.one)
+	for i := 0; i < maxSWUZCandidates; i++ {
+		for _, neg := range []bool{false, true} {
+			z := fq2.new()
+			fq2.copy(z, ctr)
+			if neg {
+				fq2.neg(z, ctr)
+			}
+			if !isSWUZForG2(fq2, a, b, z) {
+				continue
+			}
+			minusZInv, minusBOverA := fq2.new(), fq2.new()
+			fq2.inverse(minusZInv, z)
+			fq2.neg(minusZInv, minusZInv)
+			fq2.inverse(minusBOverA, a)
+			fq2.mul(minusBOverA, minusBOverA, b)
+			fq2.neg(minusBOverA, minusBOverA)
+			return &g2SWUParams{z, minusZInv, a, b, minusBOverA}, nil
+		}
+		fq2.add(ctr, ctr, fq2.one())
+	}
+	return nil, errors.New("could not find z for simplified swu")
+}
+
+// isSWUZForG1 checks that z is non square, z != -1, g(x) - z is irreducible
+// and g(b / (z * a)) is square where g(x) = x^3 + a * x + b
+func isSWUZForG1(fq *fq, a, b, z fe) bool {
+	minusOne := fq.new()
+	fq.neg(minusOne, fq.one)
+	if legendreSymbolFq(fq, z) != -1 || fq.equal(z, minusOne) {
+		return false
+	}
+	c0 := fq.new()
+	fq.sub(c0, b, z)
+	if cubicHasRootFq(fq, a, c0, fq.pbig) {
+		return false
+	}
+	x, gx := fq.new(), fq.new()
+	fq.mul(x, z, a)
+	fq.inverse(x, x)
+	fq.mul(x, x, b)
+	fq.square(gx, x)
+	fq.add(gx, gx, a)
+	fq.mul(gx, gx, x)
+	fq.add(gx, gx, b)
+	return legendreSymbolFq(fq, gx) != -1
+}
+
+func isSWUZForG2(fq2 *fq2, a, b, z *fe2) bool {
+	minusOne := fq2.new()
+	fq2.neg(minusOne, fq2.one())
+	if legendreSymbolFq2(fq2, z) != -1 || fq2.equal(z, minusOne) {
+		return false
+	}
+	c0 := fq2.new()
+	fq2.sub(c0, b, z)
+	order := new(big.Int).Mul(fq2.f.pbig, fq2.f.pbig)
+	if cubicHasRootFq2(fq2, a, c0, order) {
+		return false
+	}
+	x, gx := fq2.new(), fq2.new()
+	fq2.mul(x, z, a)
+	fq2.inverse(x, x)
+	fq2.mul(x, x, b)
+	fq2.square(gx, x)
+	fq2.add(gx, gx, a)
+	fq2.mul(gx, gx, x)
+	fq2.add(gx, gx, b)
+	return legendreSymbolFq2(fq2, gx) != -1
+}
+
+// cubicHasRootFq checks if h(x) = x^3 + c1 * x + c0 has a root in a field
+// of given order, that is if gcd(x^order - x, h) is not trivial
+func cubicHasRootFq(fq *fq, c1, c0 fe, order *big.Int) bool {
+	// r = x^order mod h
+	r := [3]fe{fq.new(), fq.new(), fq.new()}
+	fq.copy(r[0], fq.one)
+	for i := order.BitLen() - 1; i >= 0; i-- {
+		r = mulModCubicFq(fq, c1, c0, r, r)
+		if order.Bit(i) == 1 {
+			// r = r * x
+			t := fq.new()
+			fq.mul(t, c1, r[2])
+			fq.sub(t, r[0], t)
+			fq.mul(r[0], c0, r[2])
+			fq.neg(r[0], r[0])
+			r[2] = r[1]
+			r[1] = t
+		}
+	}
+	fq.sub(r[1], r[1], fq.one)
+	if !fq.isZero(r[2]) {
+		// h mod r = s1 * x + s0 where r = x^2 + m1 * x + m0 made monic
+		m1, m0, s1, s0 := fq.new(), fq.new(), fq.new(), fq.new()
+		fq.inverse(m0, r[2])
+		fq.mul(m1, r[1], m0)
+		fq.mul(m0, r[0], m0)
+		fq.square(s1, m1)
+		fq.sub(s1, s1, m0)
+		fq.add(s1, s1, c1)
+		fq.mul(s0, m1, m0)
+		fq.add(s0, s0, c0)
+		if fq.isZero(s1) {
+			return fq.isZero(s0)
+		}
+		// common root is -s0 / s1 if r vanishes there
+		x := fq.new()
+		fq.inverse(x, s1)
+		fq.mul(x, x, s0)
+		fq.neg(x, x)
+		return fq.isZero(evalPolyFq(fq, []fe{m0, m1, fq.one}, x))
+	}
+	if !fq.isZero(r[1]) {
+		x := fq.new()
+		fq.inverse(x, r[1])
+		fq.mul(x, x, r[0])
+		fq.neg(x, x)
+		return fq.isZero(evalPolyFq(fq, []fe{c0, c1, fq.zero, fq.one}, x))
+	}
+	// h divides x^order - x
+	return fq.isZero(r[0])
+}
+
+func cubicHasRootFq2(fq2 *fq2, c1, c0 *fe2, order *big.Int) bool {
+	r := [3]*fe2{fq2.new(), fq2.new(), fq2.new()}
+	fq2.copy(r[0], fq2.one())
+	for i := order.BitLen() - 1; i >= 0; i-- {
+		r = mulModCubicFq2(fq2, c1, c0, r, r)
+		if order.Bit(i) == 1 {
+			t := fq2.new()
+			fq2.mul(t, c1, r[2])
+			fq2.sub(t, r[0], t)
+			fq2.mul(r[0], c0, r[2])
+			fq2.neg(r[0], r[0])
+			r[2] = r[1]
+			r[1] = t
+		}
+	}
+	fq2.sub(r[1], r[1], fq2.one())
+	if !fq2.isZero(r[2]) {
+		m1, m0, s1, s0 := fq2.new(), fq2.new(), fq2.new(), fq2.new()
+		fq2.inverse(m0, r[2])
+		fq2.mul(m1, r[1], m0)
+		fq2.mul(m0, r[0], m0)
+		fq2.square(s1, m1)
+		fq2.sub(s1, s1, m0)
+		fq2.add(s1, s1, c1)
+		fq2.mul(s0, m1, m0)
+		fq2.add(s0, s0, c0)
+		if fq2.isZero(s1) {
+			return fq2.isZero(s0)
+		}
+		x := fq2.new()
+		fq2.inverse(x, s1)
+		fq2.mul(x, x, s0)
+		fq2.neg(x, x)
+		return fq2.isZero(evalPolyFq2(fq2, []*fe2{m0, m1, fq2.one()}, x))
+	}
+	if !fq2.isZero(r[1]) {
+		x := fq2.new()
+		fq2.inverse(x, r[1])
+		fq2.mul(x, x, r[0])
+		fq2.neg(x, x)
+		return fq2.isZero(evalPolyFq2(fq2, []*fe2{c0, c1, fq2.zero(), fq2.one()}, x))
+	}
+	return fq2.isZero(r[0])
+}
+
+// mulModCubicFq multiplies polynomials of degree two modulo x^3 + c1 * x + c0
+func mulModCubicFq(fq *fq, c1, c0 fe, a, b [3]fe) [3]fe {
+	var p [5]fe
+	t := fq.new()
+	for i := 0; i < 5; i++ {
+		p[i] = fq.new()
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			fq.mul(t, a[i], b[j])
+			fq.add(p[i+j], p[i+j], t)
+		}
+	}
+	// x^4 = -c1 * x^2 - c0 * x and x^3 = -c1 * x - c0
+	for i := 4; i >= 3; i-- {
+		fq.mul(t, c1, p[i])
+		fq.sub(p[i-2], p[i-2], t)
+		fq.mul(t, c0, p[i])
+		fq.sub(p[i-3], p[i-3], t)
+	}
+	return [3]fe{p[0], p[1], p[2]}
+}
+
+func mulModCubicFq2(fq2 *fq2, c1, c0 *fe2, a, b [3]*fe2) [3]*fe2 {
+	var p [5]*fe2
+	t := fq2.new()
+	for i := 0; i < 5; i++ {
+		p[i] = fq2.new()
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			fq2.mul(t, a[i], b[j])
+			fq2.add(p[i+j], p[i+j], t)
+		}
+	}
+	for i := 4; i >= 3; i-- {
+		fq2.mul(t, c1, p[i])
+		fq2.sub(p[i-2], p[i-2], t)
+		fq2.mul(t, c0, p[i])
+		fq2.sub(p[i-3], p[i-3], t)
+	}
+	return [3]*fe2{p[0], p[1], p[2]}
+}
+
+// isogeny params keep coefficients of isogenous curve that simplified swu
+// maps to and x, y numerator and denominator polynomials in ascending order
 type g1IsogenyParams struct {
-	k1 [16]fe
-	k2 [16]fe
-	k3 [16]fe
-	k4 [16]fe
+	a  fe
+	b  fe
+	k1 []fe
+	k2 []fe
+	k3 []fe
+	k4 []fe
 }
 
 type g2IsogenyParams struct {
-	k1 [4]*fe2
-	k2 [4]*fe2
-	k3 [4]*fe2
-	k4 [4]*fe2
+	a  *fe2
+	b  *fe2
+	k1 []*fe2
+	k2 []*fe2
+	k3 []*fe2
+	k4 []*fe2
 }
 
 func prepareIsogenyParamsForG1(fq *fq) *g1IsogenyParams {
@@ -425,11 +665,14 @@ func prepareIsogenyParamsForG1(fq *fq) *g1IsogenyParams {
 		k4[i], _ = fq.fromString(k4Constants[i])
 	}
 
+	swu := computeSWUParamsForG1(fq)
 	return &g1IsogenyParams{
-		k1,
-		k2,
-		k3,
-		k4,
+		swu.a,
+		swu.b,
+		k1[:],
+		k2[:],
+		k3[:],
+		k4[:],
 	}
 }
 
@@ -473,11 +716,470 @@ func prepareIsogenyParamsForG2(fq2 *fq2) *g2IsogenyParams {
 	k4[3][0], _ = fq2.f.fromString("0x01")
 	k4[3][1], _ = fq2.f.fromString("0x00")
 
+	swu := computeSWUParamsForG2(fq2)
 	return &g2IsogenyParams{
-		k1,
-		k2,
-		k3,
-		k4,
+		swu.a,
+		swu.b,
+		k1[:],
+		k2[:],
+		k3[:],
+		k4[:],
 	}
 
 }
+
+// shallue-van de woestijne map of rfc 9380 section 6.6.1 for any curve
+// y^2 = x^3 + a * x + b, c3 is chosen with sgn0(c3) = 0
+type g1SvdWParams struct {
+	z  fe
+	c1 fe
+	c2 fe
+	c3 fe
+	c4 fe
+	a  fe
+	b  fe
+}
+
+type g2SvdWParams struct {
+	z  *fe2
+	c1 *fe2
+	c2 *fe2
+	c3 *fe2
+	c4 *fe2
+	a  *fe2
+	b  *fe2
+}
+
+// newSvdWParamsForG1 derives constants with z as the first candidate of find_z_svdw
+// in rfc 9380 appendix H.1
+func newSvdWParamsForG1(fq *fq, a, b fe) (*g1SvdWParams, error) {
+	ctr, t := fq.new(), fq.new()
+	fq.copy(ctr, fq.one)
+	for i := 0; i < maxSWUZCandidates; i++ {
+		for _, neg := range []bool{false, true} {
+			z := fq.new()
+			fq.copy(z, ctr)
+			if neg {
+				fq.neg(z, ctr)
+			}
+			// g(z) != 0
+			gz := curveEquationFq(fq, z, a, b)
+			if fq.isZero(gz) {
+				continue
+			}
+			// h = -(3 * z^2 + 4 * a) / (4 * g(z)) is non zero square
+			n, d := fq.new(), fq.new()
+			fq.square(n, z)
+			fq.double(t, n)
+			fq.add(n, n, t)
+			fq.double(t, a)
+			fq.double(t, t)
+			fq.add(n, n, t)
+			fq.double(d, gz)
+			fq.double(d, d)
+			h := fq.new()
+			fq.inverse(h, d)
+			fq.mul(h, h, n)
+			fq.neg(h, h)
+			if fq.isZero(h) || legendreSymbolFq(fq, h) != 1 {
+				continue
+			}
+			// one of g(z) and g(-z / 2) is square
+			fq.double(t, fq.one)
+			fq.inverse(t, t)
+			fq.mul(t, t, z)
+			fq.neg(t, t)
+			if legendreSymbolFq(fq, gz) == -1 && legendreSymbolFq(fq, curveEquationFq(fq, t, a, b)) == -1 {
+				continue
+			}
+			c1, c2, c3, c4 := gz, fq.new(), fq.new(), fq.new()
+			fq.copy(c2, t)
+			// c3 = sqrt(-g(z) * (3 * z^2 + 4 * a))
+			fq.mul(c3, gz, n)
+			fq.neg(c3, c3)
+			if !fq.sqrt(c3, c3) {
+				return nil, errors.New("no square root for svdw constant")
+			}
+			if fq.sgn0(c3) {
+				fq.neg(c3, c3)
+			}
+			// c4 = -4 * g(z) / (3 * z^2 + 4 * a)
+			fq.inverse(c4, n)
+			fq.mul(c4, c4, d)
+			fq.neg(c4, c4)
+			return &g1SvdWParams{z, c1, c2, c3, c4, a, b}, nil
+		}
+		fq.add(ctr, ctr, fq.one)
+	}
+	return nil, errors.New("could not find z for svdw")
+}
+
+func newSvdWParamsForG2(fq2 *fq2, a, b *fe2) (*g2SvdWParams, error) {
+	ctr, t := fq2.new(), fq2.new()
+	fq2.copy(ctr, fq2.one())
+	for i := 0; i < maxSWUZCandidates; i++ {
+		for _, neg := range []bool{false, true} {
+			z := fq2.new()
+			fq2.copy(z, ctr)
+			if neg {
+				fq2.neg(z, ctr)
+			}
+			gz := curveEquationFq2(fq2, z, a, b)
+			if fq2.isZero(gz) {
+				continue
+			}
+			n, d := fq2.new(), fq2.new()
+			fq2.square(n, z)
+			fq2.double(t, n)
+			fq2.add(n, n, t)
+			fq2.double(t, a)
+			fq2.double(t, t)
+			fq2.add(n, n, t)
+			fq2.double(d, gz)
+			fq2.double(d, d)
+			h := fq2.new()
+			fq2.inverse(h, d)
+			fq2.mul(h, h, n)
+			fq2.neg(h, h)
+			if fq2.isZero(h) || legendreSymbolFq2(fq2, h) != 1 {
+				continue
+			}
+			fq2.double(t, fq2.one())
+			fq2.inverse(t, t)
+			fq2.mul(t, t, z)
+			fq2.neg(t, t)
+			if legendreSymbolFq2(fq2, gz) == -1 && legendreSymbolFq2(fq2, curveEquationFq2(fq2, t, a, b)) == -1 {
+				continue
+			}
+			c1, c2, c3, c4 := gz, fq2.new(), fq2.new(), fq2.new()
+			fq2.copy(c2, t)
+			fq2.mul(c3, gz, n)
+			fq2.neg(c3, c3)
+			if !fq2.sqrt(c3, c3) {
+				return nil, errors.New("no square root for svdw constant")
+			}
+			if fq2.sgn0(c3) {
+				fq2.neg(c3, c3)
+			}
+			fq2.inverse(c4, n)
+			fq2.mul(c4, c4, d)
+			fq2.neg(c4, c4)
+			return &g2SvdWParams{z, c1, c2, c3, c4, a, b}, nil
+		}
+		fq2.add(ctr, ctr, fq2.one())
+	}
+	return nil, errors.New("could not find z for svdw")
+}
+
+// svdwMapForG1 follows straight line implementation of rfc 9380 appendix F.1
+func svdwMapForG1(u fe, fq *fq, params *g1SvdWParams) (fe, fe, bool) {
+	tv1, tv2, tv3, tv4 := fq.new(), fq.new(), fq.new(), fq.new()
+	// tv1 = c1 * u^2, tv2 = 1 + tv1, tv1 = 1 - tv1
+	fq.square(tv1, u)
+	fq.mul(tv1, tv1, params.c1)
+	fq.add(tv2, fq.one, tv1)
+	fq.sub(tv1, fq.one, tv1)
+	// tv3 = inv0(tv1 * tv2)
+	fq.mul(tv3, tv1, tv2)
+	fq.inverse(tv3, tv3)
+	// tv4 = u * tv1 * tv3 * c3
+	fq.mul(tv4, u, tv1)
+	fq.mul(tv4, tv4, tv3)
+	fq.mul(tv4, tv4, params.c3)
+	// x1 = c2 - tv4, x2 = c2 + tv4
+	x1, x2, x3 := fq.new(), fq.new(), fq.new()
+	fq.sub(x1, params.c2, tv4)
+	fq.add(x2, params.c2, tv4)
+	e1 := legendreSymbolFq(fq, curveEquationFq(fq, x1, params.a, params.b)) != -1
+	e2 := legendreSymbolFq(fq, curveEquationFq(fq, x2, params.a, params.b)) != -1 && !e1
+	// x3 = (tv2^2 * tv3)^2 * c4 + z
+	fq.square(x3, tv2)
+	fq.mul(x3, x3, tv3)
+	fq.square(x3, x3)
+	fq.mul(x3, x3, params.c4)
+	fq.add(x3, x3, params.z)
+	x := fq.new()
+	fq.copy(x, x3)
+	fq.cmov(x, x1, e1)
+	fq.cmov(x, x2, e2)
+	y := fq.new()
+	if !fq.sqrt(y, curveEquationFq(fq, x, params.a, params.b)) {
+		return nil, nil, false
+	}
+	negY := fq.new()
+	fq.neg(negY, y)
+	fq.cmov(y, negY, fq.sgn0(u) != fq.sgn0(y))
+	return x, y, true
+}
+
+func svdwMapForG2(u *fe2, fq2 *fq2, params *g2SvdWParams) (*fe2, *fe2, bool) {
+	tv1, tv2, tv3, tv4 := fq2.new(), fq2.new(), fq2.new(), fq2.new()
+	fq2.square(tv1, u)
+	fq2.mul(tv1, tv1, params.c1)
+	fq2.add(tv2, fq2.one(), tv1)
+	fq2.sub(tv1, fq2.one(), tv1)
+	fq2.mul(tv3, tv1, tv2)
+	fq2.inverse(tv3, tv3)
+	fq2.mul(tv4, u, tv1)
+	fq2.mul(tv4, tv4, tv3)
+	fq2.mul(tv4, tv4, params.c3)
+	x1, x2, x3 := fq2.new(), fq2.new(), fq2.new()
+	fq2.sub(x1, params.c2, tv4)
+	fq2.add(x2, params.c2, tv4)
+	e1 := legendreSymbolFq2(fq2, curveEquationFq2(fq2, x1, params.a, params.b)) != -1
+	e2 := legendreSymbolFq2(fq2, curveEquationFq2(fq2, x2, params.a, params.b)) != -1 && !e1
+	fq2.square(x3, tv2)
+	fq2.mul(x3, x3, tv3)
+	fq2.square(x3, x3)
+	fq2.mul(x3, x3, params.c4)
+	fq2.add(x3, x3, params.z)
+	x := fq2.new()
+	fq2.copy(x, x3)
+	fq2.cmov(x, x1, e1)
+	fq2.cmov(x, x2, e2)
+	y := fq2.new()
+	if !fq2.sqrt(y, curveEquationFq2(fq2, x, params.a, params.b)) {
+		return nil, nil, false
+	}
+	negY := fq2.new()
+	fq2.neg(negY, y)
+	fq2.cmov(y, negY, fq2.sgn0(u) != fq2.sgn0(y))
+	return x, y, true
+}
+
+// curveEquationFq is x^3 + a * x + b
+func curveEquationFq(fq *fq, x, a, b fe) fe {
+	gx := fq.new()
+	fq.square(gx, x)
+	fq.add(gx, gx, a)
+	fq.mul(gx, gx, x)
+	fq.add(gx, gx, b)
+	return gx
+}
+
+func curveEquationFq2(fq2 *fq2, x, a, b *fe2) *fe2 {
+	gx := fq2.new()
+	fq2.square(gx, x)
+	fq2.add(gx, gx, a)
+	fq2.mul(gx, gx, x)
+	fq2.add(gx, gx, b)
+	return gx
+}
+
+// elligator 2 of rfc 9380 section 6.7.1 for montgomery curve k * t^2 = s^3 + j * s^2 + s
+// given as its short weierstrass form with x = (3 * s + j) / (3 * k), y = t / k
+type g1Ell2Params struct {
+	z  fe
+	c1 fe
+	c2 fe
+	c3 fe
+}
+
+type g2Ell2Params struct {
+	z  *fe2
+	c1 *fe2
+	c2 *fe2
+	c3 *fe2
+}
+
+// newEll2ParamsForG1 derives c1 = j / k, c2 = 1 / k^2, c3 = j / (3 * k) and z as
+// the first candidate of find_z_ell2 in rfc 9380 appendix H.3
+func newEll2ParamsForG1(fq *fq, j, k fe) (*g1Ell2Params, error) {
+	four, t := fq.new(), fq.new()
+	fq.double(four, fq.one)
+	fq.double(four, four)
+	fq.square(t, j)
+	if fq.isZero(k) || fq.equal(t, four) {
+		return nil, errors.New("not a montgomery curve")
+	}
+	c1, c2, c3 := fq.new(), fq.new(), fq.new()
+	fq.inverse(c2, k)
+	fq.mul(c1, j, c2)
+	fq.square(c2, c2)
+	fq.double(c3, fq.one)
+	fq.add(c3, c3, fq.one)
+	fq.inverse(c3, c3)
+	fq.mul(c3, c3, c1)
+	ctr := fq.new()
+	fq.copy(ctr, fq.one)
+	for i := 0; i < maxSWUZCandidates; i++ {
+		for _, neg := range []bool{false, true} {
+			z := fq.new()
+			fq.copy(z, ctr)
+			if neg {
+				fq.neg(z, ctr)
+			}
+			if legendreSymbolFq(fq, z) == -1 {
+				return &g1Ell2Params{z, c1, c2, c3}, nil
+			}
+		}
+		fq.add(ctr, ctr, fq.one)
+	}
+	return nil, errors.New("could not find z for elligator 2")
+}
+
+func newEll2ParamsForG2(fq2 *fq2, j, k *fe2) (*g2Ell2Params, error) {
+	four, t := fq2.new(), fq2.new()
+	fq2.double(four, fq2.one())
+	fq2.double(four, four)
+	fq2.square(t, j)
+	if fq2.isZero(k) || fq2.equal(t, four) {
+		return nil, errors.New("not a montgomery curve")
+	}
+	c1, c2, c3 := fq2.new(), fq2.new(), fq2.new()
+	fq2.inverse(c2, k)
+	fq2.mul(c1, j, c2)
+	fq2.square(c2, c2)
+	fq2.double(c3, fq2.one())
+	fq2.add(c3, c3, fq2.one())
+	fq2.inverse(c3, c3)
+	fq2.mul(c3, c3, c1)
+	// every element of the base field is a square in fq2, start from u
+	ctr := fq2.zero()
+	fq2.f.copy(ctr[1], fq2.f.one)
+	for i := 0; i < maxSWUZCandidates; i++ {
+		for _, neg := range []bool{false, true} {
+			z := fq2.new()
+			fq2.copy(z, ctr)
+			if neg {
+				fq2.neg(z, ctr)
+			}
+			if legendreSymbolFq2(fq2, z) == -1 {
+				return &g2Ell2Params{z, c1, c2, c3}, nil
+			}
+		}
+		fq2.add(ctr, ctr, fq2.one())
+	}
+	return nil, errors.New("could not find z for elligator 2")
+}
+
+// montgomeryToWeierstrassFq returns a = (3 - j^2) / (3 * k^2) and
+// b = (2 * j^3 - 9 * j) / (27 * k^3)
+func montgomeryToWeierstrassFq(fq *fq, j, k fe) (fe, fe) {
+	three, t, d := fq.new(), fq.new(), fq.new()
+	fq.double(three, fq.one)
+	fq.add(three, three, fq.one)
+	a, b := fq.new(), fq.new()
+	fq.square(t, j)
+	fq.sub(a, three, t)
+	fq.square(d, k)
+	fq.mul(d, d, three)
+	fq.inverse(d, d)
+	fq.mul(a, a, d)
+	// 2 * j^3 - 9 * j = j * (2 * j^2 - 9)
+	fq.double(b, t)
+	fq.mul(t, three, three)
+	fq.sub(b, b, t)
+	fq.mul(b, b, j)
+	fq.mul(t, t, three)
+	fq.square(d, k)
+	fq.mul(d, d, k)
+	fq.mul(d, d, t)
+	fq.inverse(d, d)
+	fq.mul(b, b, d)
+	return a, b
+}
+
+func montgomeryToWeierstrassFq2(fq2 *fq2, j, k *fe2) (*fe2, *fe2) {
+	three, t, d := fq2.new(), fq2.new(), fq2.new()
+	fq2.double(three, fq2.one())
+	fq2.add(three, three, fq2.one())
+	a, b := fq2.new(), fq2.new()
+	fq2.square(t, j)
+	fq2.sub(a, three, t)
+	fq2.square(d, k)
+	fq2.mul(d, d, three)
+	fq2.inverse(d, d)
+	fq2.mul(a, a, d)
+	fq2.double(b, t)
+	fq2.mul(t, three, three)
+	fq2.sub(b, b, t)
+	fq2.mul(b, b, j)
+	fq2.mul(t, t, three)
+	fq2.square(d, k)
+	fq2.mul(d, d, k)
+	fq2.mul(d, d, t)
+	fq2.inverse(d, d)
+	fq2.mul(b, b, d)
+	return a, b
+}
+
+// ell2MapForG1 follows straight line implementation of rfc 9380 appendix F.3
+func ell2MapForG1(u fe, fq *fq, params *g1Ell2Params) (fe, fe, bool) {
+	// tv1 = z * u^2, tv1 = 0 if tv1 = -1
+	tv1, minusOne := fq.new(), fq.new()
+	fq.square(tv1, u)
+	fq.mul(tv1, tv1, params.z)
+	fq.neg(minusOne, fq.one)
+	fq.cmov(tv1, fq.zero, fq.equal(tv1, minusOne))
+	// x1 = -c1 / (tv1 + 1)
+	x1 := fq.new()
+	fq.add(x1, tv1, fq.one)
+	fq.inverse(x1, x1)
+	fq.mul(x1, x1, params.c1)
+	fq.neg(x1, x1)
+	// gx1 = x1^3 + c1 * x1^2 + c2 * x1
+	gx1 := fq.new()
+	fq.add(gx1, x1, params.c1)
+	fq.mul(gx1, gx1, x1)
+	fq.add(gx1, gx1, params.c2)
+	fq.mul(gx1, gx1, x1)
+	// x2 = -x1 - c1, gx2 = tv1 * gx1
+	x2, gx2 := fq.new(), fq.new()
+	fq.add(x2, x1, params.c1)
+	fq.neg(x2, x2)
+	fq.mul(gx2, tv1, gx1)
+	e2 := legendreSymbolFq(fq, gx1) != -1
+	x, y2 := fq.new(), fq.new()
+	fq.copy(x, x2)
+	fq.cmov(x, x1, e2)
+	fq.copy(y2, gx2)
+	fq.cmov(y2, gx1, e2)
+	y := fq.new()
+	if !fq.sqrt(y, y2) {
+		return nil, nil, false
+	}
+	negY := fq.new()
+	fq.neg(negY, y)
+	fq.cmov(y, negY, e2 != fq.sgn0(y))
+	// to weierstrass form
+	fq.add(x, x, params.c3)
+	return x, y, true
+}
+
+func ell2MapForG2(u *fe2, fq2 *fq2, params *g2Ell2Params) (*fe2, *fe2, bool) {
+	tv1, minusOne := fq2.new(), fq2.new()
+	fq2.square(tv1, u)
+	fq2.mul(tv1, tv1, params.z)
+	fq2.neg(minusOne, fq2.one())
+	fq2.cmov(tv1, fq2.zero(), fq2.equal(tv1, minusOne))
+	x1 := fq2.new()
+	fq2.add(x1, tv1, fq2.one())
+	fq2.inverse(x1, x1)
+	fq2.mul(x1, x1, params.c1)
+	fq2.neg(x1, x1)
+	gx1 := fq2.new()
+	fq2.add(gx1, x1, params.c1)
+	fq2.mul(gx1, gx1, x1)
+	fq2.add(gx1, gx1, params.c2)
+	fq2.mul(gx1, gx1, x1)
+	x2, gx2 := fq2.new(), fq2.new()
+	fq2.add(x2, x1, params.c1)
+	fq2.neg(x2, x2)
+	fq2.mul(gx2, tv1, gx1)
+	e2 := legendreSymbolFq2(fq2, gx1) != -1
+	x, y2 := fq2.new(), fq2.new()
+	fq2.copy(x, x2)
+	fq2.cmov(x, x1, e2)
+	fq2.copy(y2, gx2)
+	fq2.cmov(y2, gx1, e2)
+	y := fq2.new()
+	if !fq2.sqrt(y, y2) {
+		return nil, nil, false
+	}
+	negY := fq2.new()
+	fq2.neg(negY, y)
+	fq2.cmov(y, negY, e2 != fq2.sgn0(y))
+	fq2.add(x, x, params.c3)
+	return x, y, true
+}
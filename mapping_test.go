@@ -1,6 +1,7 @@
 package eip
 
 import (
+	"crypto/rand"
 	"math/big"
 	"testing"
 )
@@ -29,7 +30,7 @@ func TestMapToG1(t *testing.T) {
 
 	expected := g1.newPoint()
 	ex, _ := f.fromString("0x14ef8eb66c02365fdca133fa506d522dad21919ea3d863d834ba2ddab4f4114273a166eba85d5abf8a17b59cfbec8a66")
-	ey, _ := f.fromString("0x11b8e4a01bb5db119a48b9a747f5bd6a9da0be7576d6335f3f9eab0ce258211577c2637ce1707f93c2a4c4cfd0655f90")
+	ey, _ := f.fromString("0x08482d4a1dca0b88b0d2ee0efb55ef6cc6d68d0f7caedf60279227941458d50ea6e99c81cfe3806bf75a3b302f9a4b1b")
 
 	f.copy(expected[0], ex)
 	f.copy(expected[1], ey)
@@ -113,3 +114,103 @@ func TestMapToG2(t *testing.T) {
 		t.Fatal("p is not on the curve")
 	}
 }
+
+func TestSWUParamsDerivation(t *testing.T) {
+	// z values of rfc 9380 suites
+	modulus := fromHex(48, "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+	f, _ := newField(modulus)
+	expected := computeSWUParamsForG1(f)
+	params, err := newSWUParamsForG1(f, expected.a, expected.b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.equal(params.z, expected.z) || !f.equal(params.minusZInv, expected.minusZInv) || !f.equal(params.minusBOverA, expected.minusBOverA) {
+		t.Fatal("bad swu params for bls12-381 g1")
+	}
+	nonResidue := f.new()
+	f.neg(nonResidue, f.one)
+	fq2, err := newFq2(f, f.toBytes(nonResidue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected2 := computeSWUParamsForG2(fq2)
+	params2, err := newSWUParamsForG2(fq2, expected2.a, expected2.b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fq2.equal(params2.z, expected2.z) || !fq2.equal(params2.minusZInv, expected2.minusZInv) || !fq2.equal(params2.minusBOverA, expected2.minusBOverA) {
+		t.Fatal("bad swu params for bls12-381 g2")
+	}
+	// p-256 has z = -10
+	f, _ = newField(fromHex(40, "0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"))
+	a, _ := f.fromString("0xffffffff00000001000000000000000000000000fffffffffffffffffffffffc")
+	b, _ := f.fromString("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b")
+	params, err = newSWUParamsForG1(f, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, _ := f.fromString("0x0a")
+	f.neg(z, z)
+	if !f.equal(params.z, z) {
+		t.Fatal("bad z for p-256")
+	}
+	if _, err := newSWUParamsForG1(f, f.zero, b); err == nil {
+		t.Fatal("a = 0 is expected to fail")
+	}
+}
+
+func TestSvdWAndEll2Maps(t *testing.T) {
+	modulus := fromHex(48, "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+	f, _ := newField(modulus)
+	nonResidue := f.new()
+	f.neg(nonResidue, f.one)
+	fq2, err := newFq2(f, f.toBytes(nonResidue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// svdw for y^2 = x^3 + 4(u + 1) and elligator 2 for t^2 = s^3 + (3 + u) * s^2 + s
+	a, b := fq2.zero(), fq2.new()
+	b[0], _ = f.fromString("0x04")
+	b[1], _ = f.fromString("0x04")
+	svdw, err := newSvdWParamsForG2(fq2, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, k := fq2.new(), fq2.one()
+	j[0], _ = f.fromString("0x03")
+	j[1], _ = f.fromString("0x01")
+	ell2, err := newEll2ParamsForG2(fq2, j, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ea, eb := montgomeryToWeierstrassFq2(fq2, j, k)
+	for i := 0; i < 20; i++ {
+		u := fq2.rand(rand.Reader)
+		if i == 0 {
+			u = fq2.zero()
+		}
+		x, y, ok := svdwMapForG2(u, fq2, svdw)
+		if !ok {
+			t.Fatal("element has no square root")
+		}
+		y2 := fq2.new()
+		fq2.square(y2, y)
+		if !fq2.equal(y2, curveEquationFq2(fq2, x, a, b)) {
+			t.Fatal("svdw point is not on the curve")
+		}
+		if fq2.sgn0(u) != fq2.sgn0(y) {
+			t.Fatal("bad sign of y")
+		}
+		x, y, ok = ell2MapForG2(u, fq2, ell2)
+		if !ok {
+			t.Fatal("element has no square root")
+		}
+		fq2.square(y2, y)
+		if !fq2.equal(y2, curveEquationFq2(fq2, x, ea, eb)) {
+			t.Fatal("elligator 2 point is not on the curve")
+		}
+	}
+	if _, err := newEll2ParamsForG2(fq2, a, fq2.zero()); err == nil {
+		t.Fatal("k = 0 is expected to fail")
+	}
+}
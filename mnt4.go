@@ -252,21 +252,15 @@ func (mnt4 *mnt4Instance) precomputeG2(precomputedG2 *precomputedG2, g2Point *po
 	return true
 }
 
-func (mnt4 *mnt4Instance) atePairingLoop(f *fe4, g1Point *pointG1, g2Point *pointG22) bool {
+// prepareG2 returns the line coefficients of ate pairing loop for a g2
+// point, they can be used in any number of pairings with the same engine
+// parameters
+func (mnt4 *mnt4Instance) prepareG2(g2Point *pointG22) (*precomputedG2, bool) {
 	fq4 := mnt4.fq4
 	twistInv := mnt4.fq4.f.new()
 	if ok := fq4.f.inverse(twistInv, mnt4.twist); !ok {
-		return false
-	}
-
-	p := &precomputedG1{
-		fq4.f.f.new(),
-		fq4.f.f.new(),
-		fq4.f.new(),
-		fq4.f.new(),
+		return nil, false
 	}
-	mnt4.precomputeG1(p, g1Point)
-
 	doubleCount, addCount := mnt4.calculateCoeffSize()
 	q := &precomputedG2{
 		x:              fq4.f.new(),
@@ -292,8 +286,28 @@ func (mnt4 *mnt4Instance) atePairingLoop(f *fe4, g1Point *pointG1, g2Point *poin
 	}
 
 	if ok := mnt4.precomputeG2(q, g2Point, twistInv); !ok {
+		return nil, false
+	}
+	return q, true
+}
+
+func (mnt4 *mnt4Instance) atePairingLoop(f *fe4, g1Point *pointG1, g2Point *pointG22) bool {
+	q, ok := mnt4.prepareG2(g2Point)
+	if !ok {
 		return false
 	}
+	return mnt4.atePairingLoopPrepared(f, g1Point, q)
+}
+
+func (mnt4 *mnt4Instance) atePairingLoopPrepared(f *fe4, g1Point *pointG1, q *precomputedG2) bool {
+	fq4 := mnt4.fq4
+	p := &precomputedG1{
+		fq4.f.f.new(),
+		fq4.f.f.new(),
+		fq4.f.new(),
+		fq4.f.new(),
+	}
+	mnt4.precomputeG1(p, g1Point)
 
 	l1Coeff := fq4.f.zero()
 	fq4.f.f.copy(l1Coeff[0], p.x)
@@ -448,3 +462,29 @@ func (mnt4 *mnt4Instance) multiPair(g1Points []*pointG1, g2Points []*pointG22) (
 	}
 	return f, true
 }
+
+// multiPairPrepared is multiPair with prepared g2 points, nil coefficients
+// stand for the point at infinity
+func (mnt4 *mnt4Instance) multiPairPrepared(g1Points []*pointG1, g2Points []*precomputedG2) (*fe4, bool) {
+	if len(g1Points) != len(g2Points) {
+		return nil, false
+	}
+	f := mnt4.fq4.one()
+	nonZero := false
+	for i := 0; i < len(g1Points); i++ {
+		if mnt4.g1.isZero(g1Points[i]) || g2Points[i] == nil {
+			continue
+		}
+		if ok := mnt4.atePairingLoopPrepared(f, g1Points[i], g2Points[i]); !ok {
+			return nil, false
+		}
+		nonZero = true
+	}
+	if !nonZero {
+		return f, true
+	}
+	if ok := mnt4.finalexp(f); !ok {
+		return nil, false
+	}
+	return f, true
+}
@@ -252,22 +252,15 @@ func (mnt6 *mnt6Instance) precomputeG2(precomputedG2 *precomputedG2_6, g2Point *
 	return true
 }
 
-func (mnt6 *mnt6Instance) atePairingLoop(f *fe6Q, g1Point *pointG1, g2Point *pointG23) bool {
-	// TODO: check that points are in affine form
+// prepareG2 returns the line coefficients of ate pairing loop for a g2
+// point, they can be used in any number of pairings with the same engine
+// parameters
+func (mnt6 *mnt6Instance) prepareG2(g2Point *pointG23) (*precomputedG2_6, bool) {
 	fq6 := mnt6.fq6
 	twistInv := mnt6.fq6.f.new()
 	if ok := fq6.f.inverse(twistInv, mnt6.twist); !ok {
-		return false
-	}
-
-	p := &precomputedG1_6{
-		fq6.f.f.new(),
-		fq6.f.f.new(),
-		fq6.f.new(),
-		fq6.f.new(),
+		return nil, false
 	}
-	mnt6.precomputeG1(p, g1Point)
-
 	doubleCount, addCount := mnt6.calculateCoeffSize()
 	q := &precomputedG2_6{
 		x:              fq6.f.new(),
@@ -293,8 +286,29 @@ func (mnt6 *mnt6Instance) atePairingLoop(f *fe6Q, g1Point *pointG1, g2Point *poi
 	}
 
 	if ok := mnt6.precomputeG2(q, g2Point, twistInv); !ok {
+		return nil, false
+	}
+	return q, true
+}
+
+func (mnt6 *mnt6Instance) atePairingLoop(f *fe6Q, g1Point *pointG1, g2Point *pointG23) bool {
+	// TODO: check that points are in affine form
+	q, ok := mnt6.prepareG2(g2Point)
+	if !ok {
 		return false
 	}
+	return mnt6.atePairingLoopPrepared(f, g1Point, q)
+}
+
+func (mnt6 *mnt6Instance) atePairingLoopPrepared(f *fe6Q, g1Point *pointG1, q *precomputedG2_6) bool {
+	fq6 := mnt6.fq6
+	p := &precomputedG1_6{
+		fq6.f.f.new(),
+		fq6.f.f.new(),
+		fq6.f.new(),
+		fq6.f.new(),
+	}
+	mnt6.precomputeG1(p, g1Point)
 
 	l1Coeff := fq6.f.zero()
 	fq6.f.f.copy(l1Coeff[0], p.x)
@@ -449,3 +463,29 @@ func (mnt6 *mnt6Instance) multiPair(g1Points []*pointG1, g2Points []*pointG23) (
 	}
 	return f, true
 }
+
+// multiPairPrepared is multiPair with prepared g2 points, nil coefficients
+// stand for the point at infinity
+func (mnt6 *mnt6Instance) multiPairPrepared(g1Points []*pointG1, g2Points []*precomputedG2_6) (*fe6Q, bool) {
+	if len(g1Points) != len(g2Points) {
+		return nil, false
+	}
+	f := mnt6.fq6.one()
+	nonZero := false
+	for i := 0; i < len(g1Points); i++ {
+		if mnt6.g1.isZero(g1Points[i]) || g2Points[i] == nil {
+			continue
+		}
+		if ok := mnt6.atePairingLoopPrepared(f, g1Points[i], g2Points[i]); !ok {
+			return nil, false
+		}
+		nonZero = true
+	}
+	if !nonZero {
+		return f, true
+	}
+	if ok := mnt6.finalexp(f); !ok {
+		return nil, false
+	}
+	return f, true
+}
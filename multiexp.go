@@ -0,0 +1,115 @@
+package eip
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// multiExpWindowSizes is the window size of the bucket method by number of
+// points, measured with BLS12-381 G1 and 255 bit scalars
+var multiExpWindowSizes = []struct {
+	n int
+	c uint
+}{
+	{8, 1},
+	{16, 3},
+	{32, 4},
+	{128, 5},
+	{512, 7},
+	{1 << 10, 8},
+	{1 << 11, 9},
+	{1 << 13, 10},
+	{1 << 15, 11},
+	{1 << 17, 12},
+	{1 << 19, 13},
+}
+
+func multiExpWindowSize(n int) uint {
+	for _, w := range multiExpWindowSizes {
+		if n <= w.n {
+			return w.c
+		}
+	}
+	return 14
+}
+
+// multiExpWorker accumulates the buckets of a window with its own scratch
+// space
+type multiExpWorker interface {
+	clearBuckets()
+	// addToBucket adds the point at index i to the bucket
+	addToBucket(bucket, i int)
+	// reduceBuckets stores the sum of (i + 1) * bucket[i] as the result of
+	// the window
+	reduceBuckets(window int)
+}
+
+// multiExpGroup is the group specific part of the bucket method
+type multiExpGroup interface {
+	newWorker(numBuckets int) multiExpWorker
+}
+
+// multiExpWindows returns scalars in limbs, window size and number of windows
+// of the bucket method. Scalars are expected to be non negative and are not
+// modified.
+func multiExpWindows(scalars []*big.Int) ([]scalar, uint, int) {
+	reprs := make([]scalar, len(scalars))
+	maxBits := 0
+	for i, s := range scalars {
+		reprs[i] = newRepr(s)
+		if s.BitLen() > maxBits {
+			maxBits = s.BitLen()
+		}
+	}
+	c := multiExpWindowSize(len(scalars))
+	return reprs, c, (maxBits + int(c) - 1) / int(c)
+}
+
+// bucketMultiExp fills buckets of each window and reduces them into window
+// results. Windows are independent and split across goroutines.
+func bucketMultiExp(m multiExpGroup, reprs []scalar, c uint, numWindows int) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > numWindows {
+		workers = numWindows
+	}
+	run := func(first int) {
+		w := m.newWorker(1<<c - 1)
+		for window := first; window < numWindows; window += workers {
+			w.clearBuckets()
+			offset := uint(window) * c
+			for i, s := range reprs {
+				if d := s.window(offset, c); d != 0 {
+					w.addToBucket(int(d-1), i)
+				}
+			}
+			w.reduceBuckets(window)
+		}
+	}
+	if workers <= 1 {
+		run(0)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			run(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// window returns c bits of the scalar starting from offset
+func (repr scalar) window(offset, c uint) uint64 {
+	limb, shift := offset/64, offset%64
+	if limb >= uint(len(repr)) {
+		return 0
+	}
+	v := repr[limb] >> shift
+	if shift+c > 64 && limb+1 < uint(len(repr)) {
+		v |= repr[limb+1] << (64 - shift)
+	}
+	return v & (1<<c - 1)
+}
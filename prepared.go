@@ -0,0 +1,330 @@
+package eip
+
+import (
+	"errors"
+	"sync"
+)
+
+// PreparedG2 holds the line coefficients of the Miller loop for a list of G2
+// points, so that pairings against fixed G2 points, such as a verifying key,
+// skip that step. A PreparedG2 is immutable after PrepareG2 returns and is
+// safe for concurrent use by multiple goroutines.
+type PreparedG2 struct {
+	opType int
+	header []byte
+	n      int
+	// [][]fe6C for BLS12 and BN, []*precomputedG2 for MNT4 and
+	// []*precomputedG2_6 for MNT6, nil entries are points at infinity
+	coeffs interface{}
+	// engines keep scratch space so each call takes its own
+	engines sync.Pool
+}
+
+// PrepareG2 decodes the curve parameters of the pairing operation opType
+// followed by a list of G2 points and precomputes their line coefficients.
+// Input is the encoding of OPERATION_*PAIR call data up to the number of
+// pairs, then the number of G2 points as a single byte and for each point a
+// subgroup check flag and the dense encoded point.
+func (api *API) PrepareG2(opType int, in []byte) (*PreparedG2, error) {
+	decoder := newDecoder(in)
+	var p *PreparedG2
+	var err error
+	switch opType {
+	case OPERATION_BLS12PAIR, OPERATION_BLS12PAIR_GT:
+		p, err = decoder.prepareBLSG2()
+	case OPERATION_BNPAIR, OPERATION_BNPAIR_GT:
+		p, err = decoder.prepareBNG2()
+	case OPERATION_MNT4PAIR, OPERATION_MNT4PAIR_GT:
+		p, err = decoder.prepareMNT4G2()
+	case OPERATION_MNT6PAIR, OPERATION_MNT6PAIR_GT:
+		p, err = decoder.prepareMNT6G2()
+	default:
+		err = errors.New(ERR_UNKNOWN_OPERATION)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if decoder.remainingDataLen() != 0 {
+		return nil, errors.New(ERR_GARBAGE_INPUT)
+	}
+	p.opType = opType
+	return p, nil
+}
+
+// Len returns the number of prepared G2 points.
+func (p *PreparedG2) Len() int {
+	return p.n
+}
+
+// MultiPairPrepared computes the product of pairings of G1 points given in
+// the input with the prepared G2 points. Input is a subgroup check flag and
+// a dense encoded point for each of the p.Len() G1 points. Result is encoded
+// as the result of the operation that p was prepared for.
+func (api *API) MultiPairPrepared(p *PreparedG2, in []byte) ([]byte, error) {
+	gtOutput := false
+	switch p.opType {
+	case OPERATION_BLS12PAIR_GT, OPERATION_BNPAIR_GT, OPERATION_MNT4PAIR_GT, OPERATION_MNT6PAIR_GT:
+		gtOutput = true
+	}
+	engine := p.engines.Get()
+	defer p.engines.Put(engine)
+	switch e := engine.(type) {
+	case *blsInstance:
+		g1Points, err := readPreparedG1Points(e.g1, p.n, in)
+		if err != nil {
+			return nil, err
+		}
+		result, ok := e.multiPairPrepared(g1Points, p.coeffs.([][]fe6C))
+		if !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+		return preparedResult(e.fq12.toBytes(result), e.fq12.isOne(result), gtOutput), nil
+	case *bnInstance:
+		g1Points, err := readPreparedG1Points(e.g1, p.n, in)
+		if err != nil {
+			return nil, err
+		}
+		result, ok := e.multiPairPrepared(g1Points, p.coeffs.([][]fe6C))
+		if !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+		return preparedResult(e.fq12.toBytes(result), e.fq12.isOne(result), gtOutput), nil
+	case *mnt4Instance:
+		g1Points, err := readPreparedG1Points(e.g1, p.n, in)
+		if err != nil {
+			return nil, err
+		}
+		result, ok := e.multiPairPrepared(g1Points, p.coeffs.([]*precomputedG2))
+		if !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+		return preparedResult(e.fq4.toBytes(result), e.fq4.isOne(result), gtOutput), nil
+	case *mnt6Instance:
+		g1Points, err := readPreparedG1Points(e.g1, p.n, in)
+		if err != nil {
+			return nil, err
+		}
+		result, ok := e.multiPairPrepared(g1Points, p.coeffs.([]*precomputedG2_6))
+		if !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+		return preparedResult(e.fq6.toBytes(result), e.fq6.isOne(result), gtOutput), nil
+	default:
+		return nil, errors.New(ERR_UNKNOWN_OPERATION)
+	}
+}
+
+func preparedResult(gt []byte, isOne bool, gtOutput bool) []byte {
+	if gtOutput {
+		return gt
+	}
+	if !isOne {
+		return pairingError
+	}
+	return pairingSuccess
+}
+
+// newPreparedG2 keeps the encoded curve parameters, engines in the pool are
+// decoded from them on demand
+func newPreparedG2(header []byte, n int, coeffs interface{}, e interface{}) *PreparedG2 {
+	// input of the caller may be reused
+	p := &PreparedG2{header: append([]byte{}, header...), n: n, coeffs: coeffs}
+	p.engines.New = func() interface{} {
+		return newPairingEngine(p.opType, p.header)
+	}
+	p.engines.Put(e)
+	return p
+}
+
+// newPairingEngine decodes a pairing engine from the curve parameters that
+// are already validated by PrepareG2
+func newPairingEngine(opType int, header []byte) interface{} {
+	decoder := newDecoder(header)
+	switch opType {
+	case OPERATION_BLS12PAIR, OPERATION_BLS12PAIR_GT:
+		e, _ := decoder.blsEngine()
+		return e
+	case OPERATION_BNPAIR, OPERATION_BNPAIR_GT:
+		e, _ := decoder.bnEngine()
+		return e
+	case OPERATION_MNT4PAIR, OPERATION_MNT4PAIR_GT:
+		e, _ := decoder.mnt4Engine()
+		return e
+	case OPERATION_MNT6PAIR, OPERATION_MNT6PAIR_GT:
+		e, _ := decoder.mnt6Engine()
+		return e
+	}
+	return nil
+}
+
+func (decoder *decoder) prepareBLSG2() (*PreparedG2, error) {
+	e, err := decoder.blsEngine()
+	if err != nil {
+		return nil, err
+	}
+	header := decoder.tape.data[:decoder.tape.offset]
+	g2Points, err := decoder.readPreparedG22Points(e.g2)
+	if err != nil {
+		return nil, err
+	}
+	coeffs := make([][]fe6C, len(g2Points))
+	for i, point := range g2Points {
+		if e.g2.isZero(point) {
+			continue
+		}
+		var ok bool
+		if coeffs[i], ok = e.prepareG2(point); !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+	}
+	return newPreparedG2(header, len(g2Points), coeffs, e), nil
+}
+
+func (decoder *decoder) prepareBNG2() (*PreparedG2, error) {
+	e, err := decoder.bnEngine()
+	if err != nil {
+		return nil, err
+	}
+	header := decoder.tape.data[:decoder.tape.offset]
+	g2Points, err := decoder.readPreparedG22Points(e.g2)
+	if err != nil {
+		return nil, err
+	}
+	coeffs := make([][]fe6C, len(g2Points))
+	for i, point := range g2Points {
+		if e.g2.isZero(point) {
+			continue
+		}
+		var ok bool
+		if coeffs[i], ok = e.prepareG2(point); !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+	}
+	return newPreparedG2(header, len(g2Points), coeffs, e), nil
+}
+
+func (decoder *decoder) prepareMNT4G2() (*PreparedG2, error) {
+	e, err := decoder.mnt4Engine()
+	if err != nil {
+		return nil, err
+	}
+	header := decoder.tape.data[:decoder.tape.offset]
+	g2Points, err := decoder.readPreparedG22Points(e.g2)
+	if err != nil {
+		return nil, err
+	}
+	coeffs := make([]*precomputedG2, len(g2Points))
+	for i, point := range g2Points {
+		if e.g2.isZero(point) {
+			continue
+		}
+		var ok bool
+		if coeffs[i], ok = e.prepareG2(point); !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+	}
+	return newPreparedG2(header, len(g2Points), coeffs, e), nil
+}
+
+func (decoder *decoder) prepareMNT6G2() (*PreparedG2, error) {
+	e, err := decoder.mnt6Engine()
+	if err != nil {
+		return nil, err
+	}
+	header := decoder.tape.data[:decoder.tape.offset]
+	g2Points, err := decoder.readPreparedG23Points(e.g2)
+	if err != nil {
+		return nil, err
+	}
+	coeffs := make([]*precomputedG2_6, len(g2Points))
+	for i, point := range g2Points {
+		if e.g2.isZero(point) {
+			continue
+		}
+		var ok bool
+		if coeffs[i], ok = e.prepareG2(point); !ok {
+			return nil, errors.New(ERR_PAIRING_NO_RETURN_VALUE)
+		}
+	}
+	return newPreparedG2(header, len(g2Points), coeffs, e), nil
+}
+
+func (decoder *decoder) readPreparedG22Points(g2 *g22) ([]*pointG22, error) {
+	n, err := decoder.readLength()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errors.New(ERR_PAIRING_NUM_PAIRS_ZERO)
+	}
+	points := make([]*pointG22, n)
+	for i := 0; i < n; i++ {
+		checkSubgroup, err := decoder.readBool()
+		if err != nil {
+			return nil, err
+		}
+		if points[i], err = decoder.readG22Point(g2); err != nil {
+			return nil, err
+		}
+		if !g2.isOnCurve(points[i]) {
+			return nil, errors.New(ERR_PAIRING_POINTG2_NOT_ON_CURVE)
+		}
+		if checkSubgroup && !g2.checkCorrectSubgroup(points[i]) {
+			return nil, errors.New(ERR_PAIRING_POINTG2_NOT_IN_SUBGROUP)
+		}
+	}
+	return points, nil
+}
+
+func (decoder *decoder) readPreparedG23Points(g2 *g23) ([]*pointG23, error) {
+	n, err := decoder.readLength()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errors.New(ERR_PAIRING_NUM_PAIRS_ZERO)
+	}
+	points := make([]*pointG23, n)
+	for i := 0; i < n; i++ {
+		checkSubgroup, err := decoder.readBool()
+		if err != nil {
+			return nil, err
+		}
+		if points[i], err = decoder.readG23Point(g2); err != nil {
+			return nil, err
+		}
+		if !g2.isOnCurve(points[i]) {
+			return nil, errors.New(ERR_PAIRING_POINTG2_NOT_ON_CURVE)
+		}
+		if checkSubgroup && !g2.checkCorrectSubgroup(points[i]) {
+			return nil, errors.New(ERR_PAIRING_POINTG2_NOT_IN_SUBGROUP)
+		}
+	}
+	return points, nil
+}
+
+// readPreparedG1Points decodes n g1 points, field of the curve is already known
+func readPreparedG1Points(g1 *g1, n int, in []byte) ([]*pointG1, error) {
+	decoder := newDecoder(in)
+	decoder.cache.fq = g1.f
+	points := make([]*pointG1, n)
+	for i := 0; i < n; i++ {
+		checkSubgroup, err := decoder.readBool()
+		if err != nil {
+			return nil, err
+		}
+		if points[i], err = decoder.readG1Point(g1); err != nil {
+			return nil, err
+		}
+		if !g1.isOnCurve(points[i]) {
+			return nil, errors.New(ERR_PAIRING_POINTG1_NOT_ON_CURVE)
+		}
+		if checkSubgroup && !g1.checkCorrectSubgroup(points[i]) {
+			return nil, errors.New(ERR_PAIRING_POINTG1_NOT_IN_SUBGROUP)
+		}
+	}
+	if decoder.remainingDataLen() != 0 {
+		return nil, errors.New(ERR_GARBAGE_INPUT)
+	}
+	return points, nil
+}
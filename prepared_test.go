@@ -0,0 +1,129 @@
+package eip
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// splitPairs returns prepared g2 input and g1 input of the pairs of v
+func splitPairs(v *pairingGTInput, pairs []byte) ([]byte, []byte) {
+	pairLen := 2 + v.g1Len + v.g2Len
+	n := len(pairs) / pairLen
+	g2In := append(append([]byte{}, v.header...), byte(n))
+	g1In := []byte{}
+	for i := 0; i < n; i++ {
+		pair := pairs[i*pairLen : (i+1)*pairLen]
+		g1In = append(g1In, pair[:1+v.g1Len]...)
+		g2In = append(g2In, pair[1+v.g1Len:]...)
+	}
+	return g2In, g1In
+}
+
+func TestMultiPairPrepared(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		family := v.family
+		boolOp := v.operation - OPERATION_BLS12PAIR_GT + OPERATION_BLS12PAIR
+		for _, in := range [][]byte{v.input, v.first, v.last} {
+			headerLen := len(v.header)
+			g2In, g1In := splitPairs(v, in[headerLen+1:])
+			for _, op := range []int{v.operation, boolOp} {
+				expected, err := api.Run(op, in)
+				if err != nil {
+					t.Fatal(err, family)
+				}
+				p, err := api.PrepareG2(op, g2In)
+				if err != nil {
+					t.Fatal(err, family)
+				}
+				if p.Len() != int(in[headerLen]) {
+					t.Fatal("bad number of prepared points", family)
+				}
+				// prepared points are reused
+				for i := 0; i < 2; i++ {
+					actual, err := api.MultiPairPrepared(p, g1In)
+					if err != nil {
+						t.Fatal(err, family)
+					}
+					if !bytes.Equal(expected, actual) {
+						t.Fatal("prepared pairing does not match", family)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMultiPairPreparedConcurrent(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		family := v.family
+		g2In, g1In := splitPairs(v, v.first[len(v.header)+1:])
+		expected, err := api.Run(v.operation, v.first)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		p, err := api.PrepareG2(v.operation, g2In)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		var wg sync.WaitGroup
+		errs := make(chan string, 8)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				actual, err := api.MultiPairPrepared(p, g1In)
+				if err != nil {
+					errs <- err.Error()
+					return
+				}
+				if !bytes.Equal(expected, actual) {
+					errs <- "prepared pairing does not match"
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatal(err, family)
+		}
+	}
+}
+
+func TestMultiPairPreparedErrors(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		family := v.family
+		g2In, g1In := splitPairs(v, v.last[len(v.header)+1:])
+		if _, err := api.PrepareG2(OPERATION_G1_ADD, g2In); err == nil || err.Error() != ERR_UNKNOWN_OPERATION {
+			t.Fatal("unknown operation is expected", family)
+		}
+		zero := append(append([]byte{}, v.header...), 0)
+		if _, err := api.PrepareG2(v.operation, zero); err == nil || err.Error() != ERR_PAIRING_NUM_PAIRS_ZERO {
+			t.Fatal("zero points is expected to fail", family)
+		}
+		if _, err := api.PrepareG2(v.operation, append(g2In, 0)); err == nil || err.Error() != ERR_GARBAGE_INPUT {
+			t.Fatal("garbage input is expected to fail", family)
+		}
+		if _, err := api.PrepareG2(v.operation, g2In[:len(g2In)-1]); err == nil {
+			t.Fatal("short input is expected to fail", family)
+		}
+		p, err := api.PrepareG2(v.operation, g2In)
+		if err != nil {
+			t.Fatal(err, family)
+		}
+		if _, err := api.MultiPairPrepared(p, append(g1In, g1In...)); err == nil || err.Error() != ERR_GARBAGE_INPUT {
+			t.Fatal("garbage input is expected to fail", family)
+		}
+		if _, err := api.MultiPairPrepared(p, g1In[:len(g1In)-1]); err == nil {
+			t.Fatal("short input is expected to fail", family)
+		}
+		bad := append([]byte{}, g1In...)
+		bad[len(bad)-1] ^= 1
+		if _, err := api.MultiPairPrepared(p, bad); err == nil || err.Error() != ERR_PAIRING_POINTG1_NOT_ON_CURVE {
+			t.Fatal("point not on curve is expected to fail", family)
+		}
+	}
+}
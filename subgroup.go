@@ -0,0 +1,91 @@
+package eip
+
+import (
+	"math/big"
+	"sync"
+)
+
+// subgroupTest checks membership to the subgroup of order q on curves with
+// a = 0 using the endomorphism phi(x, y) = (beta * x, y) where beta is a cube
+// root of unity. For a vector (a, b) with a^2 - ab + b^2 = q kernels of
+// a + b * phi and a + b * phi^2 have q points, so a point that is in either
+// kernel is of order q. Points of the subgroup are in one of them, as phi
+// acts on a cyclic subgroup as a cube root of unity modulo q. With
+// (z^2, 1) it is the test of Scott for BLS12 G1,
+// https://eprint.iacr.org/2021/1130
+type subgroupTest struct {
+	a, b *big.Int
+	// result is the same as multiplication by q if q is prime and full q
+	// torsion is not defined over the field of the group, that is checked
+	// on first use
+	once  sync.Once
+	valid bool
+	beta  fe
+}
+
+// newSubgroupTest returns nil if the vector does not match the order
+func newSubgroupTest(a, b, q *big.Int) *subgroupTest {
+	norm := new(big.Int).Mul(a, a)
+	norm.Sub(norm, new(big.Int).Mul(a, b))
+	norm.Add(norm, new(big.Int).Mul(b, b))
+	if norm.Cmp(q) != 0 {
+		return nil
+	}
+	return &subgroupTest{a: new(big.Int).Set(a), b: new(big.Int).Set(b)}
+}
+
+// blsSubgroupTest uses q = z^4 - z^2 + 1 of BLS12 family
+func blsSubgroupTest(z, q *big.Int) *subgroupTest {
+	return newSubgroupTest(new(big.Int).Mul(z, z), big.NewInt(1), q)
+}
+
+// bnSubgroupTest uses q = 36u^4 + 36u^3 + 18u^2 + 6u + 1 of BN family, that
+// is the norm of 6u^2 + 4u + 1 and 2u + 1
+func bnSubgroupTest(u *big.Int, uIsNegative bool, q *big.Int) *subgroupTest {
+	u = new(big.Int).Set(u)
+	if uIsNegative {
+		u.Neg(u)
+	}
+	a := new(big.Int).Mul(u, u)
+	a.Mul(a, big.NewInt(6))
+	a.Add(a, new(big.Int).Lsh(u, 2))
+	a.Add(a, big.NewInt(1))
+	b := new(big.Int).Lsh(u, 1)
+	b.Add(b, big.NewInt(1))
+	return newSubgroupTest(a, b, q)
+}
+
+// init reports whether the test can be used for a group over the extension
+// of degree k of f
+func (s *subgroupTest) init(f *fq, q *big.Int, k int) bool {
+	s.once.Do(func() {
+		p, one, three := f.modulus(), big.NewInt(1), big.NewInt(3)
+		pMinus1 := new(big.Int).Sub(p, one)
+		if new(big.Int).Mod(pMinus1, three).Sign() != 0 {
+			return
+		}
+		if q.Cmp(three) <= 0 || q.Cmp(p) == 0 || !q.ProbablyPrime(0) {
+			return
+		}
+		pk := new(big.Int).Set(p)
+		for i := 1; i <= k; i++ {
+			if new(big.Int).Mod(new(big.Int).Sub(pk, one), q).Sign() == 0 {
+				return
+			}
+			pk.Mul(pk, p)
+		}
+		power := new(big.Int).Div(pMinus1, three)
+		s.beta = f.new()
+		for c := int64(2); ; c++ {
+			base, err := f.fromBig(big.NewInt(c))
+			if err != nil {
+				return
+			}
+			if f.exp(s.beta, base, power); !f.isOne(s.beta) {
+				break
+			}
+		}
+		s.valid = true
+	})
+	return s.valid
+}
@@ -0,0 +1,177 @@
+package eip
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// randCurvePointG1 returns a point on the curve which is not necessarily in
+// the subgroup
+func randCurvePointG1(g *g1) *pointG1 {
+	p := g.newPoint()
+	for {
+		x := g.f.rand(rand.Reader)
+		rhs := g.f.new()
+		g.f.square(rhs, x)
+		g.f.add(rhs, rhs, g.a)
+		g.f.mul(rhs, rhs, x)
+		g.f.add(rhs, rhs, g.b)
+		if g.f.sqrt(p[1], rhs) {
+			g.f.copy(p[0], x)
+			g.f.copy(p[2], g.f.one)
+			return p
+		}
+	}
+}
+
+func randCurvePointG22(g *g22) *pointG22 {
+	p := g.newPoint()
+	for {
+		x := g.f.rand(rand.Reader)
+		rhs := g.f.new()
+		g.f.square(rhs, x)
+		g.f.add(rhs, rhs, g.a)
+		g.f.mul(rhs, rhs, x)
+		g.f.add(rhs, rhs, g.b)
+		if g.f.sqrt(p[1], rhs) {
+			g.f.copy(p[0], x)
+			g.f.copy(p[2], g.f.one())
+			return p
+		}
+	}
+}
+
+func TestSubgroupTest(t *testing.T) {
+	vectors := []*builder{
+		testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS")),
+		testBuilderFromVector(t, "bn254", bn254Vector, newBuilderOptPairing("BN")),
+	}
+	for _, v := range vectors {
+		t.Run(v.tag, func(t *testing.T) {
+			var g1 *g1
+			var g2 *g22
+			if v.family == "BN" {
+				e := v.bn().(bnTest).bn
+				g1, g2 = e.g1, e.g2
+			} else {
+				e := v.bls().(blsTest).bls
+				g1, g2 = e.g1, e.g2
+			}
+			if g1.subgroup == nil || !g1.subgroup.init(g1.f, g1.q, 1) {
+				t.Fatal("g1 subgroup test is expected to be available")
+			}
+			if g2.subgroup == nil || !g2.subgroup.init(g2.f.fq(), g2.q, 2) {
+				t.Fatal("g2 subgroup test is expected to be available")
+			}
+			G1, G2 := v.G1().(*pointG1), v.G22().(*pointG22)
+			c1, c2 := g1.newPoint(), g2.newPoint()
+			for i := 0; i < 8; i++ {
+				g1.mulScalar(c1, G1, randScalar(g1.q))
+				g2.mulScalar(c2, G2, randScalar(g2.q))
+				if !g1.checkCorrectSubgroup(c1) || !g2.checkCorrectSubgroup(c2) {
+					t.Fatal("point of the subgroup is rejected")
+				}
+				p1, p2 := randCurvePointG1(g1), randCurvePointG22(g2)
+				expected1 := g1.isZero(g1.wnafMul(c1, p1, g1.q))
+				expected2 := g2.isZero(g2.wnafMul(c2, p2, g2.q))
+				if g1.checkCorrectSubgroup(p1) != expected1 || g2.checkCorrectSubgroup(p2) != expected2 {
+					t.Fatal("subgroup test does not match multiplication by order")
+				}
+				// points of the cofactor part are rejected
+				if v.family == "BLS" && expected1 {
+					t.Fatal("random point is expected to be out of g1")
+				}
+				if expected2 {
+					t.Fatal("random point is expected to be out of g2")
+				}
+			}
+			if !g1.checkCorrectSubgroup(g1.zero()) || !g2.checkCorrectSubgroup(g2.zero()) {
+				t.Fatal("point at infinity is rejected")
+			}
+		})
+	}
+	// order of the test curve is of the family but not prime
+	g := testBuilderFromFile(t, "bls12/384.json", newBuilderOptPairing("BLS")).bls().(blsTest).bls.g1
+	if g.subgroup == nil || g.subgroup.init(g.f, g.q, 1) {
+		t.Fatal("subgroup test is expected to fall back for composite order")
+	}
+	q := big.NewInt(7)
+	if newSubgroupTest(big.NewInt(3), big.NewInt(1), q) == nil {
+		t.Fatal("vector of norm q is rejected")
+	}
+	if newSubgroupTest(big.NewInt(2), big.NewInt(1), q) != nil {
+		t.Fatal("vector that does not match q is accepted")
+	}
+}
+
+func TestPairingSubgroupCheckFlags(t *testing.T) {
+	api := NewAPI()
+	for _, v := range pairingGTInputs(t) {
+		family := v.family
+		if family != "BLS" && family != "BN" {
+			continue
+		}
+		e := newPairingEngine(v.operation, v.header)
+		var g1 *g1
+		var g2 *g22
+		if family == "BN" {
+			g1, g2 = e.(*bnInstance).g1, e.(*bnInstance).g2
+		} else {
+			g1, g2 = e.(*blsInstance).g1, e.(*blsInstance).g2
+		}
+		in := append([]byte{}, v.last...)
+		pair := in[len(v.header)+1:]
+		p2 := g2.toBytesDense(randCurvePointG22(g2))
+		if len(p2) != v.g2Len {
+			t.Fatal("bad g2 encoding", family)
+		}
+		copy(pair[2+v.g1Len:], p2)
+		pair[0], pair[1+v.g1Len] = 0, 1
+		if _, err := api.Run(v.operation, in); err == nil || err.Error() != ERR_PAIRING_POINTG2_NOT_IN_SUBGROUP {
+			t.Fatal("g2 point out of the subgroup is expected to fail", family)
+		}
+		pair[1+v.g1Len] = 0
+		if _, err := api.Run(v.operation, in); err != nil {
+			t.Fatal("subgroup is not checked without the flag", family)
+		}
+		// cofactor of bn g1 is one
+		if family == "BLS" {
+			in = append([]byte{}, v.last...)
+			pair = in[len(v.header)+1:]
+			copy(pair[1:], g1.toBytesDense(randCurvePointG1(g1)))
+			pair[0] = 1
+			if _, err := api.Run(v.operation, in); err == nil || err.Error() != ERR_PAIRING_POINTG1_NOT_IN_SUBGROUP {
+				t.Fatal("g1 point out of the subgroup is expected to fail", family)
+			}
+		}
+	}
+}
+
+func BenchmarkSubgroupCheck(t *testing.B) {
+	v := testBuilderFromVector(t, "bls12_381", bls12381Vector, newBuilderOptPairing("BLS"))
+	e := v.bls().(blsTest).bls
+	g1, g2 := e.g1, e.g2
+	p1, p2 := v.G1().(*pointG1), v.G22().(*pointG22)
+	t.Run("g1_endomorphism", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g1.checkCorrectSubgroup(p1)
+		}
+	})
+	t.Run("g2_endomorphism", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g2.checkCorrectSubgroup(p2)
+		}
+	})
+	g1.subgroup, g2.subgroup, g2.gls = nil, nil, nil
+	t.Run("g1_order", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g1.checkCorrectSubgroup(p1)
+		}
+	})
+	t.Run("g2_order", func(t *testing.B) {
+		for i := 0; i < t.N; i++ {
+			g2.checkCorrectSubgroup(p2)
+		}
+	})
+}
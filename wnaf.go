@@ -118,6 +118,8 @@ func wnaf(s *big.Int, window uint) []int64 {
 	if e.isZero() {
 		return []int64{}
 	}
+	// room for the carry of a negative digit at the top limb
+	e = append(e, 0)
 	max := int64(1 << window)
 	midpoint := int64(1 << (window - 1))
 	modulusMask := uint64(1<<window) - 1